@@ -1,10 +1,14 @@
 package main
 
 import (
+	"magic-server-2026/src/controllers"
 	"magic-server-2026/src/db"
 	"magic-server-2026/src/gen"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/mailer"
 	"magic-server-2026/src/middlewares"
 	"magic-server-2026/src/routes"
+	"magic-server-2026/src/search"
 	"magic-server-2026/src/server"
 	"magic-server-2026/src/utils"
 
@@ -13,16 +17,31 @@ import (
 
 func main() {
 	utils.LoadEnv()
+	helpers.CheckSigningSecrets()
+	utils.CheckSignedURLSecret()
 	db.Init()
 	app := fiber.New(fiber.Config{
 		EnableIPValidation: true,
 		TrustProxy:         true,
 	})
 
+	// talent search videos and tus chunks; handlers enforce their own per-file limits
+	middlewares.SetupUploadBodyLimit(app)
 	middlewares.Setup(app)
 
 	gen.Init()
 
+	search.Init()
+
+	mailer.Init()
+
+	go controllers.InitMovieDates()
+	go controllers.InitShoutboxBookings()
+	go controllers.InitForms()
+	go controllers.InitNewsletter()
+	go controllers.InitMediaLibrary()
+	go controllers.InitVideoUploads()
+
 	routes.SetupRouter(app)
 
 	app.Get("/", func(c fiber.Ctx) error {
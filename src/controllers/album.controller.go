@@ -0,0 +1,744 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Album Controller (event photo galleries)
+   -----------------------------------
+   1. List published albums       GET    /albums?q=&news_id=&page=&limit=
+   2. Get an album                GET    /albums/:slug?page=&limit= (images are paginated)
+   3. Download as ZIP (public)    GET    /albums/:slug.zip (app root, linkable)
+   Staff only:
+   4. Unpublished albums          GET    /albums/drafts
+   5. Create / update / delete    POST   /albums, PUT /albums/:slug, DELETE /albums/:slug
+   6. Import a numbered set       POST   /albums/:slug/import {"prefix": "kostcon"}
+   -----------------------------------
+   Images are media store files in display order, with their own caption,
+   alt text and credit (the album photographer when empty). Width, height
+   and missing text are taken from the media library. Text fields are
+   stored as plain text without markup. Deleting an album keeps its
+   files; they show up as orphans in the media library.
+   The ZIP is streamed straight from storage with stored (uncompressed)
+   entries, since photos do not compress, plus a credits.txt.
+   -----------------------------------
+   PATH: /api/v1/albums
+*/
+
+const (
+	albumMaxImages         = 500
+	albumMaxTitleLength    = 150
+	albumMaxDescLength     = 5000
+	albumMaxTextLength     = 500
+	albumDefaultLimit      = 20
+	albumMaxLimit          = 100
+	albumImageDefaultLimit = 50
+	albumImageMaxLimit     = 200
+	albumZipTimeout        = 30 * time.Minute
+)
+
+// albumReservedSlugs collide with staff routes under /albums
+var albumReservedSlugs = map[string]bool{"drafts": true}
+
+var (
+	albumIndexesOnce sync.Once
+	// albumZipSlots bounds how many ZIP downloads stream at once
+	albumZipSlots = make(chan struct{}, 4)
+)
+
+func AlbumCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "albums")
+	albumIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "published", Value: 1}, {Key: "taken_at", Value: -1}}},
+			{Keys: bson.D{{Key: "news_id", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[ALBUMS] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+var errAlbumNotFound = errors.New("album not found")
+
+// findAlbum loads the album named by slug; unpublished albums only for staff
+func findAlbum(ctx context.Context, slug string, staff bool) (models.PhotoAlbum, error) {
+	var album models.PhotoAlbum
+	filter := bson.M{"slug": slug}
+	if !staff {
+		filter["published"] = true
+	}
+	err := AlbumCollectionInit().FindOne(ctx, filter).Decode(&album)
+	if err == mongo.ErrNoDocuments {
+		return album, errAlbumNotFound
+	}
+	return album, err
+}
+
+func albumError(c fiber.Ctx, err error) error {
+	if err == errAlbumNotFound {
+		return errorResponse(c, http.StatusNotFound, "Album not found")
+	}
+	log.Println("Find album error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch album")
+}
+
+// albumPage reads ?page= and ?limit=
+func albumPage(c fiber.Ctx, defaultLimit, maxLimit int) (int, int) {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return page, min(limit, maxLimit)
+}
+
+// albumSummary is an album without its images
+func albumSummary(album models.PhotoAlbum) fiber.Map {
+	return fiber.Map{
+		"id":           album.ID,
+		"slug":         album.Slug,
+		"title":        html.UnescapeString(album.Title),
+		"description":  html.UnescapeString(album.Description),
+		"venue":        html.UnescapeString(album.Venue),
+		"taken_at":     album.Taken_at,
+		"photographer": html.UnescapeString(album.Photographer),
+		"news_id":      album.News_id,
+		"cover":        album.Cover,
+		"cover_url":    helpers.PublicImageURL(album.Cover),
+		"image_count":  album.Image_count,
+		"published":    album.Published,
+		"url":          helpers.AlbumURL(album.Slug),
+		"download_url": helpers.AlbumDownloadURL(album.Slug),
+		"created_at":   album.Created_at,
+		"updated_at":   album.Updated_at,
+	}
+}
+
+// albumImageView is an image with its URLs and effective credit
+func albumImageView(album models.PhotoAlbum, position int, image models.PhotoAlbumImage) fiber.Map {
+	credit := image.Credit
+	if credit == "" {
+		credit = album.Photographer
+	}
+	return fiber.Map{
+		"position":  position,
+		"file_name": image.File_name,
+		"url":       helpers.PublicImageURL(image.File_name),
+		"srcset":    imageSrcset(image.File_name, storage.ContentType(image.File_name), image.Width),
+		"width":     image.Width,
+		"height":    image.Height,
+		"caption":   html.UnescapeString(image.Caption),
+		"alt":       html.UnescapeString(image.Alt),
+		"credit":    html.UnescapeString(credit),
+	}
+}
+
+// albumText strips markup from a plain-text field and checks its length. The
+// text is stored raw; albums saved before this were stored escaped, so the
+// views unescape
+func albumText(field, value string, maxLength int) (string, string) {
+	text := helpers.PlainText(value)
+	if len(text) > maxLength {
+		return "", fmt.Sprintf("%s cannot be longer than %d characters", field, maxLength)
+	}
+	return text, ""
+}
+
+// albumImages validates an ordered image list against the media store and
+// fills dimensions and missing text from the media library
+func albumImages(ctx context.Context, images []models.PhotoAlbumImage) ([]models.PhotoAlbumImage, string, error) {
+	if len(images) > albumMaxImages {
+		return nil, fmt.Sprintf("an album cannot have more than %d images", albumMaxImages), nil
+	}
+	clean := make([]models.PhotoAlbumImage, 0, len(images))
+	seen := map[string]bool{}
+	for _, image := range images {
+		image.File_name = strings.TrimSpace(image.File_name)
+		if _, err := storage.Key(storage.Images, image.File_name); err != nil {
+			return nil, "invalid image file name: " + image.File_name, nil
+		}
+		if seen[image.File_name] {
+			return nil, image.File_name + " is in the album twice", nil
+		}
+		seen[image.File_name] = true
+		var problem string
+		if image.Caption, problem = albumText("caption", image.Caption, albumMaxTextLength); problem != "" {
+			return nil, problem, nil
+		}
+		if image.Alt, problem = albumText("alt", image.Alt, albumMaxTextLength); problem != "" {
+			return nil, problem, nil
+		}
+		if image.Credit, problem = albumText("credit", image.Credit, albumMaxTextLength); problem != "" {
+			return nil, problem, nil
+		}
+		clean = append(clean, image)
+	}
+	if len(clean) == 0 {
+		return clean, "", nil
+	}
+
+	names := make([]string, 0, len(clean))
+	for _, image := range clean {
+		names = append(names, image.File_name)
+	}
+	cursor, err := MediaAssetCollectionInit().Find(ctx, bson.M{"file_name": bson.M{"$in": names}})
+	if err != nil {
+		return nil, "", err
+	}
+	var assets []models.MediaAsset
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, "", err
+	}
+	known := make(map[string]models.MediaAsset, len(assets))
+	for _, asset := range assets {
+		known[asset.File_name] = asset
+	}
+
+	for i := range clean {
+		image := &clean[i]
+		asset, ok := known[image.File_name]
+		if !ok || asset.Missing {
+			// not scanned yet: the file itself decides
+			key, _ := storage.Key(storage.Images, image.File_name)
+			if _, err := storage.Default().Stat(ctx, key); errors.Is(err, storage.ErrNotFound) {
+				return nil, image.File_name + " is not in the media store", nil
+			} else if err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+		image.Width, image.Height = asset.Width, asset.Height
+		if image.Alt == "" {
+			image.Alt = html.UnescapeString(asset.Alt)
+		}
+		if image.Caption == "" {
+			image.Caption = html.UnescapeString(asset.Caption)
+		}
+		if image.Credit == "" {
+			image.Credit = html.UnescapeString(asset.Credit)
+		}
+	}
+	return clean, "", nil
+}
+
+// applyAlbumInput validates staff changes onto album; a non-empty string is a problem for the client
+func applyAlbumInput(ctx context.Context, album *models.PhotoAlbum, input models.PhotoAlbumInput) (string, error) {
+	var problem string
+	if input.Title != nil {
+		if album.Title, problem = albumText("title", *input.Title, albumMaxTitleLength); problem != "" {
+			return problem, nil
+		}
+	}
+	if input.Description != nil {
+		if album.Description, problem = albumText("description", *input.Description, albumMaxDescLength); problem != "" {
+			return problem, nil
+		}
+	}
+	if input.Venue != nil {
+		if album.Venue, problem = albumText("venue", *input.Venue, albumMaxTextLength); problem != "" {
+			return problem, nil
+		}
+	}
+	if input.Photographer != nil {
+		if album.Photographer, problem = albumText("photographer", *input.Photographer, albumMaxTextLength); problem != "" {
+			return problem, nil
+		}
+	}
+	if album.Title == "" {
+		return "title is required", nil
+	}
+
+	switch {
+	case input.Slug != nil:
+		album.Slug = utils.Slugify(strings.ReplaceAll(strings.TrimSpace(*input.Slug), " ", "-"))
+	case album.Slug == "":
+		album.Slug = utils.Slugify(strings.ReplaceAll(album.Title, " ", "-"))
+	}
+	if album.Slug == "" || albumReservedSlugs[album.Slug] {
+		return "slug is invalid", nil
+	}
+
+	if input.Taken_at != nil {
+		album.Taken_at = 0
+		if day := strings.TrimSpace(*input.Taken_at); day != "" {
+			taken, err := time.ParseInLocation("2006-01-02", day, utils.LocationAsiaManila)
+			if err != nil {
+				return "taken_at must be a date (YYYY-MM-DD)", nil
+			}
+			album.Taken_at = primitive.NewDateTimeFromTime(taken)
+		}
+	}
+
+	if input.News_id != nil {
+		album.News_id = nil
+		if hex := strings.TrimSpace(*input.News_id); hex != "" {
+			newsID, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				return "news_id is invalid", nil
+			}
+			count, err := NewsCollectionInit().CountDocuments(ctx, bson.M{"_id": newsID})
+			if err != nil {
+				return "", err
+			}
+			if count == 0 {
+				return "news_id does not match any news", nil
+			}
+			album.News_id = &newsID
+		}
+	}
+
+	if input.Images != nil {
+		images, problem, err := albumImages(ctx, *input.Images)
+		if problem != "" || err != nil {
+			return problem, err
+		}
+		album.Images = images
+	}
+	if album.Images == nil {
+		album.Images = []models.PhotoAlbumImage{}
+	}
+	album.Image_count = len(album.Images)
+
+	if input.Cover != nil {
+		album.Cover = strings.TrimSpace(*input.Cover)
+	}
+	if !albumHasImage(*album, album.Cover) {
+		if input.Cover != nil && album.Cover != "" {
+			return "cover must be one of the album's images", nil
+		}
+		album.Cover = ""
+		if len(album.Images) > 0 {
+			album.Cover = album.Images[0].File_name
+		}
+	}
+
+	if input.Published != nil {
+		album.Published = *input.Published
+	}
+	if album.Published && len(album.Images) == 0 {
+		return "an album needs images before it can be published", nil
+	}
+	return "", nil
+}
+
+func albumHasImage(album models.PhotoAlbum, name string) bool {
+	for _, image := range album.Images {
+		if image.File_name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAlbums - Published albums, newest event first
+func GetAlbums(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"published": true}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"title": pattern}, bson.M{"venue": pattern}}
+	}
+	if hex := c.Query("news_id"); hex != "" {
+		newsID, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return errorResponse(c, http.StatusBadRequest, "Invalid news_id")
+		}
+		filter["news_id"] = newsID
+	}
+	return listAlbums(c, ctx, filter)
+}
+
+// GetAlbumDrafts - Unpublished albums
+func GetAlbumDrafts(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return listAlbums(c, ctx, bson.M{"published": false})
+}
+
+func listAlbums(c fiber.Ctx, ctx context.Context, filter bson.M) error {
+	page, limit := albumPage(c, albumDefaultLimit, albumMaxLimit)
+
+	collection := AlbumCollectionInit()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count albums error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch albums")
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"images": 0}).
+		SetSort(bson.D{{Key: "taken_at", Value: -1}, {Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		log.Println("Find albums error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch albums")
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.PhotoAlbum
+	if err := cursor.All(ctx, &found); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse albums")
+	}
+	albums := make([]fiber.Map, 0, len(found))
+	for _, album := range found {
+		albums = append(albums, albumSummary(album))
+	}
+
+	return jsonResponse(c, http.StatusOK, "Albums fetched successfully", fiber.Map{
+		"albums": albums,
+		"page":   page,
+		"limit":  limit,
+		"total":  total,
+	})
+}
+
+// GetAlbum - A published album with a page of its images
+func GetAlbum(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	album, err := findAlbum(ctx, c.Params("slug"), false)
+	if err != nil {
+		return albumError(c, err)
+	}
+	return sendAlbum(c, album, http.StatusOK, "Album fetched successfully", nil)
+}
+
+// sendAlbum responds with the album and the requested page of its images
+func sendAlbum(c fiber.Ctx, album models.PhotoAlbum, status int, message string, extra fiber.Map) error {
+	page, limit := albumPage(c, albumImageDefaultLimit, albumImageMaxLimit)
+	start := min((page-1)*limit, len(album.Images))
+	end := min(start+limit, len(album.Images))
+	images := make([]fiber.Map, 0, end-start)
+	for i := start; i < end; i++ {
+		images = append(images, albumImageView(album, i+1, album.Images[i]))
+	}
+
+	data := fiber.Map{
+		"album":  albumSummary(album),
+		"images": images,
+		"page":   page,
+		"limit":  limit,
+		"total":  len(album.Images),
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	return jsonResponse(c, status, message, data)
+}
+
+// CreateAlbum - New album; slug defaults to the title
+func CreateAlbum(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var input models.PhotoAlbumInput
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	var album models.PhotoAlbum
+	problem, err := applyAlbumInput(ctx, &album, input)
+	if err != nil {
+		log.Println("Album validation error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create album")
+	}
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	_, author := revisionAuthor(c)
+	album.ID, album.Created_by, album.Created_at, album.Updated_at = primitive.NewObjectID(), author, now, now
+	if _, err := AlbumCollectionInit().InsertOne(ctx, album); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "An album with this slug already exists")
+		}
+		log.Println("Insert album error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create album")
+	}
+	return sendAlbum(c, album, http.StatusCreated, "Album created successfully", nil)
+}
+
+// UpdateAlbum - Change any field; images replace the whole ordered list
+func UpdateAlbum(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	album, err := findAlbum(ctx, c.Params("slug"), true)
+	if err != nil {
+		return albumError(c, err)
+	}
+	var input models.PhotoAlbumInput
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	problem, err := applyAlbumInput(ctx, &album, input)
+	if err != nil {
+		log.Println("Album validation error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update album")
+	}
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+	return saveAlbum(c, ctx, album, "Album updated successfully", nil)
+}
+
+// saveAlbum stores an edited album and responds with it
+func saveAlbum(c fiber.Ctx, ctx context.Context, album models.PhotoAlbum, message string, extra fiber.Map) error {
+	album.Updated_at = primitive.NewDateTimeFromTime(time.Now())
+	if _, err := AlbumCollectionInit().ReplaceOne(ctx, bson.M{"_id": album.ID}, album); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "An album with this slug already exists")
+		}
+		log.Println("Update album error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update album")
+	}
+	return sendAlbum(c, album, http.StatusOK, message, extra)
+}
+
+// albumImportName matches "<prefix>.ext" and "<prefix><n>.ext" media store files
+func albumImportName(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^` + regexp.QuoteMeta(prefix) + `(\d*)\.(jpe?g|png|webp|avif)$`)
+}
+
+// ImportAlbumImages - Append a numbered upload set (kostcon1.webp, kostcon2.webp ...) in
+// numeric order. An unnumbered file (kostcon.webp) goes first and becomes the cover.
+func ImportAlbumImages(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	album, err := findAlbum(ctx, c.Params("slug"), true)
+	if err != nil {
+		return albumError(c, err)
+	}
+	var body struct {
+		Prefix string `json:"prefix"`
+	}
+	if err := c.Bind().Body(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	prefix := strings.TrimSpace(body.Prefix)
+	if _, err := storage.Key(storage.Images, prefix); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "prefix is required and cannot contain slashes")
+	}
+
+	listPrefix, _ := storage.Key(storage.Images, prefix)
+	objects, err := storage.Default().List(ctx, listPrefix)
+	if err != nil {
+		log.Println("List media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to list images")
+	}
+	pattern := albumImportName(prefix)
+	type numbered struct {
+		name   string
+		number int // -1 for the unnumbered file
+	}
+	var matches []numbered
+	for _, obj := range objects {
+		name := obj.Name()
+		groups := pattern.FindStringSubmatch(name)
+		if groups == nil || albumHasImage(album, name) {
+			continue
+		}
+		number := -1
+		if groups[1] != "" {
+			number, _ = strconv.Atoi(groups[1])
+		}
+		matches = append(matches, numbered{name, number})
+	}
+	if len(matches) == 0 {
+		return errorResponse(c, http.StatusNotFound, "No new images match "+prefix)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].number != matches[j].number {
+			return matches[i].number < matches[j].number
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	images := append([]models.PhotoAlbumImage{}, album.Images...)
+	cover := ""
+	for _, match := range matches {
+		images = append(images, models.PhotoAlbumImage{File_name: match.name})
+		if match.number < 0 && cover == "" {
+			cover = match.name
+		}
+	}
+	input := models.PhotoAlbumInput{Images: &images}
+	if cover != "" && len(album.Images) == 0 {
+		input.Cover = &cover
+	}
+	problem, err := applyAlbumInput(ctx, &album, input)
+	if err != nil {
+		log.Println("Album validation error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to import images")
+	}
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+	return saveAlbum(c, ctx, album, "Images imported successfully", fiber.Map{"added": len(matches)})
+}
+
+// DeleteAlbum - Remove the album; its image files stay in the media store
+func DeleteAlbum(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	album, err := findAlbum(ctx, c.Params("slug"), true)
+	if err != nil {
+		return albumError(c, err)
+	}
+	if _, err := AlbumCollectionInit().DeleteOne(ctx, bson.M{"_id": album.ID}); err != nil {
+		log.Println("Delete album error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to delete album")
+	}
+	_, author := revisionAuthor(c)
+	log.Printf("[ALBUMS] %s deleted %s (%d images)", author, album.Slug, album.Image_count)
+	return jsonResponse(c, http.StatusOK, "Album deleted successfully", fiber.Map{"slug": album.Slug})
+}
+
+// albumCredits is the credits.txt placed in album downloads
+func albumCredits(album models.PhotoAlbum, names []string) string {
+	photographer := html.UnescapeString(album.Photographer)
+	var b strings.Builder
+	b.WriteString(html.UnescapeString(album.Title) + "\n")
+	var about []string
+	if album.Venue != "" {
+		about = append(about, html.UnescapeString(album.Venue))
+	}
+	if album.Taken_at != 0 {
+		about = append(about, album.Taken_at.Time().In(utils.LocationAsiaManila).Format("January 2, 2006"))
+	}
+	if len(about) > 0 {
+		b.WriteString(strings.Join(about, ", ") + "\n")
+	}
+	if photographer != "" {
+		b.WriteString("Photos by " + photographer + "\n")
+	}
+	b.WriteString(helpers.AlbumURL(album.Slug) + "\n\n")
+	for i, image := range album.Images {
+		if names[i] == "" {
+			continue
+		}
+		line := names[i]
+		if image.Caption != "" {
+			line += " - " + html.UnescapeString(image.Caption)
+		}
+		if credit := html.UnescapeString(image.Credit); credit != "" && credit != photographer {
+			line += " (" + credit + ")"
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// writeAlbumZip writes the album images in order, then credits.txt; images
+// missing from storage are left out
+func writeAlbumZip(ctx context.Context, w io.Writer, album models.PhotoAlbum) error {
+	archive := zip.NewWriter(w)
+	names := make([]string, len(album.Images))
+	digits := len(strconv.Itoa(len(album.Images)))
+	for i, image := range album.Images {
+		key, err := storage.Key(storage.Images, image.File_name)
+		if err != nil {
+			continue
+		}
+		r, obj, err := storage.Default().Get(ctx, key)
+		if err != nil {
+			log.Printf("[ALBUMS] %s: skipping %s: %v", album.Slug, image.File_name, err)
+			continue
+		}
+		names[i] = fmt.Sprintf("%0*d-%s", digits, i+1, image.File_name)
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: names[i], Method: zip.Store, Modified: obj.ModTime})
+		if err == nil {
+			_, err = io.Copy(entry, r)
+		}
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	entry, err := archive.Create("credits.txt")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(entry, albumCredits(album, names)); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// DownloadAlbum - The whole album as a ZIP, streamed as it is read from storage
+func DownloadAlbum(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	album, err := findAlbum(ctx, c.Params("slug"), false)
+	if err != nil {
+		return albumError(c, err)
+	}
+	if len(album.Images) == 0 {
+		return errorResponse(c, http.StatusNotFound, "Album has no images")
+	}
+
+	select {
+	case albumZipSlots <- struct{}{}:
+	default:
+		c.Set(fiber.HeaderRetryAfter, "60")
+		return errorResponse(c, http.StatusServiceUnavailable, "Too many album downloads right now, try again in a minute")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment(album.Slug + ".zip")
+	// the writer runs after this handler returns, so it gets its own context
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer func() { <-albumZipSlots }()
+		ctx, cancel := context.WithTimeout(context.Background(), albumZipTimeout)
+		defer cancel()
+
+		if err := writeAlbumZip(ctx, w, album); err != nil {
+			// usually the client went away
+			log.Printf("[ALBUMS] %s: download stopped: %v", album.Slug, err)
+		}
+	})
+}
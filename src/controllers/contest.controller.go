@@ -0,0 +1,1093 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/mailer"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Contest Controller (giveaways, photo hunts, movie passes)
+   -----------------------------------
+   1. List published contests     GET  /contests?state=open|upcoming|closed
+   2. Get a contest               GET  /contests/:id
+   3. Enter                       POST /contests/:id/entries (JSON or multipart with "photo")
+   4. Draw audit record           GET  /contests/:id/draw
+   5. Winner claim status         GET  /contests/:id/claim?token=
+   6. Claim a prize               POST /contests/:id/claim
+   Staff only:
+   7. Create / update             POST /contests, PUT /contests/:id
+   8. Entries                     GET  /contests/:id/entries, PATCH /contests/:id/entries/:entryId
+   9. Draw winners                POST /contests/:id/draw
+   10. Winners / forfeit          GET  /contests/:id/winners, POST /contests/:id/winners/:winnerId/forfeit
+   -----------------------------------
+   Draws are reproducible from the committed seed (see contestDraw.helper.go).
+   The draw is stored before its winners; if saving them fails, drawing
+   again saves the missing ones from the stored draw. Winners and
+   promoted alternates are emailed their claim link (contest-winner).
+   Unclaimed prizes pass to the next drawn alternate when the deadline ends.
+   -----------------------------------
+   PATH: /api/v1/contests
+*/
+
+const (
+	contestPhotoMaxBytes  = 8 << 20
+	contestAnswerMaxChars = 500
+	contestDefaultClaim   = 7
+)
+
+var contestFieldTypes = map[string]bool{"text": true, "textarea": true, "select": true}
+
+var (
+	contestIndexesOnce       sync.Once
+	contestWinnerIndexesOnce sync.Once
+)
+
+func ContestCollectionInit() *mongo.Collection {
+	return db.GetCollection("magic899_db", "contests")
+}
+
+func ContestEntryCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "contest_entries")
+	contestIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// one entry per person: same email or same phone counts as the same person
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "contest_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "contest_id", Value: 1}, {Key: "phone", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "contest_id", Value: 1}, {Key: "entry_no", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[CONTESTS] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+func ContestWinnerCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "contest_winners")
+	contestWinnerIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// one winner per slot and draw position, so saving a draw twice cannot duplicate winners
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "contest_id", Value: 1}, {Key: "slot", Value: 1}, {Key: "draw_position", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Println("[CONTESTS] winner index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+var errContestNotFound = errors.New("contest not found")
+
+// findContest loads the contest named by :id; unpublished contests only for staff
+func findContest(ctx context.Context, c fiber.Ctx, staff bool) (models.Contest, error) {
+	var contest models.Contest
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return contest, errContestNotFound
+	}
+	filter := bson.M{"_id": objID}
+	if !staff {
+		filter["published"] = true
+	}
+	err = ContestCollectionInit().FindOne(ctx, filter).Decode(&contest)
+	if err == mongo.ErrNoDocuments {
+		return contest, errContestNotFound
+	}
+	return contest, err
+}
+
+func contestError(c fiber.Ctx, err error) error {
+	if err == errContestNotFound {
+		return errorResponse(c, http.StatusNotFound, "Contest not found")
+	}
+	log.Println("Find contest error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch contest")
+}
+
+// contestState is upcoming, open or closed relative to now
+func contestState(contest models.Contest, now time.Time) string {
+	switch {
+	case now.Before(contest.Opens_at.Time()):
+		return "upcoming"
+	case now.Before(contest.Closes_at.Time()):
+		return "open"
+	}
+	return "closed"
+}
+
+// normalizeContest validates and cleans a staff contest definition
+func normalizeContest(contest *models.Contest) string {
+	policy := bluemonday.StrictPolicy()
+	contest.Title = strings.TrimSpace(policy.Sanitize(contest.Title))
+	contest.Description = strings.TrimSpace(bluemonday.UGCPolicy().Sanitize(contest.Description))
+	contest.Prize = strings.TrimSpace(policy.Sanitize(contest.Prize))
+	contest.Kind = strings.ToLower(strings.TrimSpace(contest.Kind))
+
+	switch {
+	case contest.Title == "":
+		return "title is required"
+	case contest.Opens_at == 0 || contest.Closes_at == 0 || contest.Closes_at <= contest.Opens_at:
+		return "opens_at and closes_at are required and closes_at must be later"
+	case contest.Winners < 1:
+		return "winners must be at least 1"
+	case contest.Alternates < 0:
+		return "alternates cannot be negative"
+	case contest.Rules.Min_age < 0 || contest.Rules.Min_age > 100:
+		return "min_age must be between 0 and 100"
+	}
+	if contest.Claim_days <= 0 {
+		contest.Claim_days = contestDefaultClaim
+	}
+
+	switch contest.Rules.Photo {
+	case "":
+		contest.Rules.Photo = models.ContestPhotoNone
+	case models.ContestPhotoNone, models.ContestPhotoOptional, models.ContestPhotoRequired:
+	default:
+		return "rules.photo must be none, optional or required"
+	}
+
+	regions := []string{}
+	for _, region := range contest.Rules.Regions {
+		if region = strings.TrimSpace(policy.Sanitize(region)); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	contest.Rules.Regions = regions
+
+	keys := map[string]bool{}
+	for i := range contest.Fields {
+		field := &contest.Fields[i]
+		field.Key = utils.Slugify(field.Key)
+		field.Label = strings.TrimSpace(policy.Sanitize(field.Label))
+		if field.Type == "" {
+			field.Type = "text"
+		}
+		switch {
+		case field.Key == "" || keys[field.Key]:
+			return "every field needs a unique key"
+		case !contestFieldTypes[field.Type]:
+			return "field type must be text, textarea or select"
+		case field.Type == "select" && len(field.Options) == 0:
+			return "select fields need options"
+		}
+		if field.Max_length <= 0 || field.Max_length > contestAnswerMaxChars {
+			field.Max_length = contestAnswerMaxChars
+		}
+		keys[field.Key] = true
+	}
+	if contest.Fields == nil {
+		contest.Fields = []models.ContestField{}
+	}
+	return ""
+}
+
+// ageOn returns the age in whole years of someone born on birth at the date of now
+func ageOn(birth, now time.Time) int {
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// checkContestEntry applies the contest's eligibility rules and form fields to an entry
+func checkContestEntry(contest models.Contest, input models.ContestEntryInput, now time.Time) (models.ContestEntry, string) {
+	policy := bluemonday.StrictPolicy()
+	entry := models.ContestEntry{
+		Name:    strings.TrimSpace(policy.Sanitize(input.Name)),
+		Email:   helpers.NormalizeEmail(input.Email),
+		Phone:   helpers.NormalizePHMobile(input.Phone),
+		Answers: map[string]string{},
+		Status:  models.EntryValid,
+	}
+	switch {
+	case entry.Name == "" || len(entry.Name) > 100:
+		return entry, "Name is required (max 100 characters)"
+	case entry.Email == "":
+		return entry, "Invalid email address"
+	case entry.Phone == "":
+		return entry, "Invalid mobile number"
+	}
+
+	if contest.Rules.Min_age > 0 {
+		birth, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(input.Birthdate), utils.LocationAsiaManila)
+		if err != nil {
+			return entry, "Birthdate is required (YYYY-MM-DD)"
+		}
+		if ageOn(birth, now.In(utils.LocationAsiaManila)) < contest.Rules.Min_age {
+			return entry, "You must be at least " + strconv.Itoa(contest.Rules.Min_age) + " years old to join"
+		}
+		entry.Birthdate = birth.Format("2006-01-02")
+	}
+
+	if len(contest.Rules.Regions) > 0 {
+		for _, region := range contest.Rules.Regions {
+			if strings.EqualFold(region, strings.TrimSpace(input.Region)) {
+				entry.Region = region
+			}
+		}
+		if entry.Region == "" {
+			return entry, "This contest is open to " + strings.Join(contest.Rules.Regions, ", ") + " only"
+		}
+	}
+
+	for _, field := range contest.Fields {
+		answer := strings.TrimSpace(policy.Sanitize(input.Answers[field.Key]))
+		if answer == "" {
+			if field.Required {
+				return entry, field.Label + " is required"
+			}
+			continue
+		}
+		if len([]rune(answer)) > field.Max_length {
+			return entry, field.Label + " is too long"
+		}
+		if field.Type == "select" {
+			valid := false
+			for _, option := range field.Options {
+				if option == answer {
+					valid = true
+				}
+			}
+			if !valid {
+				return entry, "Invalid choice for " + field.Label
+			}
+		}
+		entry.Answers[field.Key] = answer
+	}
+	return entry, ""
+}
+
+// bindContestEntry reads a JSON entry, or a multipart one with answer_<key> fields and a photo
+func bindContestEntry(c fiber.Ctx) (models.ContestEntryInput, *multipart.FileHeader, error) {
+	var input models.ContestEntryInput
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		err := c.Bind().JSON(&input)
+		return input, nil, err
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return input, nil, err
+	}
+	value := func(key string) string {
+		if values := form.Value[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	input.Name, input.Email, input.Phone = value("name"), value("email"), value("phone")
+	input.Birthdate, input.Region = value("birthdate"), value("region")
+	input.Answers = map[string]string{}
+	for key := range form.Value {
+		if name, ok := strings.CutPrefix(key, "answer_"); ok {
+			input.Answers[name] = value(key)
+		}
+	}
+
+	var photo *multipart.FileHeader
+	if files := form.File["photo"]; len(files) > 0 {
+		photo = files[0]
+	}
+	return input, photo, nil
+}
+
+// GetContests - Published contests, optionally filtered by ?state=open|upcoming|closed
+func GetContests(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	filter := bson.M{"published": true}
+	sortOrder := bson.D{{Key: "closes_at", Value: 1}}
+	switch c.Query("state") {
+	case "":
+	case "open":
+		filter["opens_at"] = bson.M{"$lte": now}
+		filter["closes_at"] = bson.M{"$gt": now}
+	case "upcoming":
+		filter["opens_at"] = bson.M{"$gt": now}
+		sortOrder = bson.D{{Key: "opens_at", Value: 1}}
+	case "closed":
+		filter["closes_at"] = bson.M{"$lte": now}
+		sortOrder = bson.D{{Key: "closes_at", Value: -1}}
+	default:
+		return errorResponse(c, http.StatusBadRequest, "state must be open, upcoming or closed")
+	}
+
+	cursor, err := ContestCollectionInit().Find(ctx, filter, options.Find().SetSort(sortOrder).SetLimit(100))
+	if err != nil {
+		log.Println("Find contests error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch contests")
+	}
+	defer cursor.Close(ctx)
+
+	contests := []models.Contest{}
+	if err := cursor.All(ctx, &contests); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse contests")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Contests fetched successfully", fiber.Map{"contests": contests})
+}
+
+// GetContest - A published contest with its rules, form and seed commitment
+func GetContest(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, false)
+	if err != nil {
+		return contestError(c, err)
+	}
+
+	return jsonResponse(c, http.StatusOK, "Contest fetched successfully", fiber.Map{
+		"contest": contest,
+		"state":   contestState(contest, time.Now()),
+	})
+}
+
+// EnterContest - Submit an entry; eligibility rules are checked before it is stored
+func EnterContest(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, false)
+	if err != nil {
+		return contestError(c, err)
+	}
+	now := time.Now()
+	if state := contestState(contest, now); state != "open" || contest.Draw != nil {
+		return errorResponse(c, http.StatusConflict, "This contest is not accepting entries")
+	}
+
+	input, photo, err := bindContestEntry(c)
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	entry, problem := checkContestEntry(contest, input, now)
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	switch {
+	case photo == nil && contest.Rules.Photo == models.ContestPhotoRequired:
+		return errorResponse(c, http.StatusBadRequest, "A photo is required for this contest")
+	case photo != nil && contest.Rules.Photo == models.ContestPhotoNone:
+		return errorResponse(c, http.StatusBadRequest, "This contest does not take photos")
+	}
+
+	collection := ContestEntryCollectionInit()
+	count, err := collection.CountDocuments(ctx, bson.M{
+		"contest_id": contest.ID,
+		"$or":        bson.A{bson.M{"email": entry.Email}, bson.M{"phone": entry.Phone}},
+	})
+	if err != nil {
+		log.Println("Find entry error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to submit entry")
+	}
+	if count > 0 {
+		return errorResponse(c, http.StatusConflict, "Only one entry per person is allowed")
+	}
+
+	if photo != nil {
+		if entry.Photo, err = saveUploadedImage(photo, "contest-"+contest.ID.Hex(), contestPhotoMaxBytes); err != nil {
+			return errorResponse(c, http.StatusBadRequest, "Invalid photo: "+err.Error())
+		}
+	}
+
+	// entry numbers come from the contest so they are sequential and stable for the audit
+	var counter models.Contest
+	err = ContestCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": contest.ID},
+		bson.M{"$inc": bson.M{"entry_count": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"entry_count": 1}),
+	).Decode(&counter)
+	if err != nil {
+		log.Println("Entry number error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to submit entry")
+	}
+
+	entry.ID = primitive.NewObjectID()
+	entry.Contest_id = contest.ID
+	entry.Entry_no = counter.Entry_count
+	entry.Created_at = primitive.NewDateTimeFromTime(now)
+	if _, err := collection.InsertOne(ctx, entry); err != nil {
+		// identical photos share a file, so only remove one no other entry uses
+		if entry.Photo != "" {
+			if n, err := collection.CountDocuments(ctx, bson.M{"photo": entry.Photo}); err == nil && n == 0 {
+				removeStoredFile(storage.Images, entry.Photo)
+			}
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "Only one entry per person is allowed")
+		}
+		log.Println("Insert entry error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to submit entry")
+	}
+
+	return jsonResponse(c, http.StatusCreated, "Entry received", fiber.Map{
+		"entry_no": entry.Entry_no,
+		"entry":    entry,
+	})
+}
+
+// GetContestDraw - Public audit record: seed, entries hash, drawn order and winners
+func GetContestDraw(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, false)
+	if err != nil {
+		return contestError(c, err)
+	}
+	if contest.Draw == nil {
+		return jsonResponse(c, http.StatusOK, "Contest not drawn yet", fiber.Map{
+			"seed_hash": contest.Seed_hash,
+			"drawn":     false,
+		})
+	}
+	if err := settleContestClaims(ctx, contest); err != nil {
+		log.Println("[CONTESTS] claim settlement failed:", err)
+	}
+
+	winners, err := findContestWinners(ctx, contest.ID)
+	if err != nil {
+		log.Println("Find winners error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch winners")
+	}
+	public := make([]fiber.Map, 0, len(winners))
+	for _, winner := range winners {
+		public = append(public, fiber.Map{
+			"slot":          winner.Slot,
+			"entry_no":      winner.Entry_no,
+			"draw_position": winner.Draw_position,
+			"name":          helpers.MaskName(winner.Name),
+			"status":        winner.Status,
+		})
+	}
+
+	return jsonResponse(c, http.StatusOK, "Contest draw fetched successfully", fiber.Map{
+		"drawn":     true,
+		"seed_hash": contest.Seed_hash,
+		"draw":      contest.Draw,
+		"winners":   public,
+		"algorithm": "hmac-sha256-fisher-yates-v1",
+	})
+}
+
+// findContestClaim resolves a claim token to its winner
+func findContestClaim(ctx context.Context, c fiber.Ctx, token string) (models.Contest, models.ContestWinner, int, string) {
+	var contest models.Contest
+	var winner models.ContestWinner
+	winnerID, contestID, err := helpers.VerifyContestClaim(token)
+	if err != nil || contestID.Hex() != c.Params("id") {
+		return contest, winner, http.StatusBadRequest, "Invalid claim link"
+	}
+	if contest, err = findContest(ctx, c, false); err != nil {
+		return contest, winner, http.StatusNotFound, "Contest not found"
+	}
+	if err := settleContestClaims(ctx, contest); err != nil {
+		log.Println("[CONTESTS] claim settlement failed:", err)
+	}
+	if err := ContestWinnerCollectionInit().FindOne(ctx, bson.M{"_id": winnerID, "contest_id": contestID}).Decode(&winner); err != nil {
+		return contest, winner, http.StatusNotFound, "Claim not found"
+	}
+	return contest, winner, 0, ""
+}
+
+// GetContestClaim - What a winner sees when opening their claim link
+func GetContestClaim(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	contest, winner, status, problem := findContestClaim(ctx, c, c.Query("token"))
+	if problem != "" {
+		return errorResponse(c, status, problem)
+	}
+
+	return jsonResponse(c, http.StatusOK, "Claim fetched successfully", fiber.Map{
+		"contest":        contest.Title,
+		"prize":          contest.Prize,
+		"name":           winner.Name,
+		"status":         winner.Status,
+		"claim_deadline": winner.Claim_deadline,
+	})
+}
+
+// ClaimContestPrize - Winner confirms their prize before the deadline
+func ClaimContestPrize(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var body struct {
+		Token   string            `json:"token"`
+		Details map[string]string `json:"details"` // e.g. valid ID, pickup date
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	_, winner, status, problem := findContestClaim(ctx, c, body.Token)
+	if problem != "" {
+		return errorResponse(c, status, problem)
+	}
+
+	details := map[string]string{}
+	policy := bluemonday.StrictPolicy()
+	for key, value := range body.Details {
+		key = utils.Slugify(key)
+		value = strings.TrimSpace(policy.Sanitize(value))
+		if key == "" || value == "" || len(details) >= 20 {
+			continue
+		}
+		if len(value) > contestAnswerMaxChars {
+			value = value[:contestAnswerMaxChars]
+		}
+		details[key] = value
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := ContestWinnerCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": winner.ID, "status": models.WinnerPending, "claim_deadline": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"status": models.WinnerClaimed, "claimed_at": now, "claim_details": details, "updated_at": now}},
+	)
+	if err != nil {
+		log.Println("Claim error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to claim prize")
+	}
+	if result.ModifiedCount == 0 {
+		switch winner.Status {
+		case models.WinnerClaimed:
+			return errorResponse(c, http.StatusConflict, "This prize has already been claimed")
+		case models.WinnerPending:
+			return errorResponse(c, http.StatusGone, "The claim deadline has passed")
+		}
+		return errorResponse(c, http.StatusGone, "This prize can no longer be claimed")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Prize claimed", fiber.Map{"claimed_at": now})
+}
+
+// CreateContest - Staff create a contest; the draw seed is committed here
+func CreateContest(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var contest models.Contest
+	if err := c.Bind().JSON(&contest); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if problem := normalizeContest(&contest); problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	seed, seedHash, err := helpers.NewContestSeed()
+	if err != nil {
+		log.Println("Seed error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create contest")
+	}
+
+	_, author := revisionAuthor(c)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	contest.ID = primitive.NewObjectID()
+	contest.Seed, contest.Seed_hash = seed, seedHash
+	contest.Entry_count, contest.Draw = 0, nil
+	contest.Created_by = author
+	contest.Created_at, contest.Updated_at = now, now
+
+	if _, err := ContestCollectionInit().InsertOne(ctx, contest); err != nil {
+		log.Println("Insert contest error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create contest")
+	}
+
+	return jsonResponse(c, http.StatusCreated, "Contest created successfully", fiber.Map{"contest": contest})
+}
+
+// UpdateContest - Staff edit a contest that has not been drawn; the seed never changes
+func UpdateContest(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := findContest(ctx, c, true)
+	if err != nil {
+		return contestError(c, err)
+	}
+	if current.Draw != nil {
+		return errorResponse(c, http.StatusConflict, "A drawn contest cannot be edited")
+	}
+
+	var contest models.Contest
+	if err := c.Bind().JSON(&contest); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if problem := normalizeContest(&contest); problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	contest.ID = current.ID
+	contest.Seed, contest.Seed_hash = current.Seed, current.Seed_hash
+	contest.Entry_count = current.Entry_count
+	contest.Created_by, contest.Created_at = current.Created_by, current.Created_at
+	contest.Updated_at = primitive.NewDateTimeFromTime(time.Now())
+
+	// entry_count is only ever changed by $inc, so replace everything else
+	update := bson.M{
+		"title": contest.Title, "description": contest.Description, "prize": contest.Prize,
+		"kind": contest.Kind, "image": contest.Image, "published": contest.Published,
+		"opens_at": contest.Opens_at, "closes_at": contest.Closes_at,
+		"winners": contest.Winners, "alternates": contest.Alternates, "claim_days": contest.Claim_days,
+		"rules": contest.Rules, "fields": contest.Fields, "updated_at": contest.Updated_at,
+	}
+	result, err := ContestCollectionInit().UpdateOne(ctx, bson.M{"_id": current.ID, "draw": bson.M{"$exists": false}}, bson.M{"$set": update})
+	if err != nil {
+		log.Println("Update contest error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update contest")
+	}
+	if result.MatchedCount == 0 {
+		return errorResponse(c, http.StatusConflict, "A drawn contest cannot be edited")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Contest updated successfully", fiber.Map{"contest": contest})
+}
+
+// GetContestEntries - Staff list of entries in entry number order
+func GetContestEntries(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, true)
+	if err != nil {
+		return contestError(c, err)
+	}
+
+	filter := bson.M{"contest_id": contest.ID}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	cursor, err := ContestEntryCollectionInit().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "entry_no", Value: 1}}))
+	if err != nil {
+		log.Println("Find entries error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch entries")
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.ContestEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse entries")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Entries fetched successfully", fiber.Map{"entries": entries})
+}
+
+// UpdateContestEntry - Staff disqualify or reinstate an entry before the draw
+func UpdateContestEntry(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, true)
+	if err != nil {
+		return contestError(c, err)
+	}
+	if contest.Draw != nil {
+		return errorResponse(c, http.StatusConflict, "Entries are frozen after the draw; forfeit the winner instead")
+	}
+	entryID, err := primitive.ObjectIDFromHex(c.Params("entryId"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Entry ID")
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	update := bson.M{}
+	switch body.Status {
+	case models.EntryDisqualified:
+		reason := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(body.Reason))
+		if reason == "" {
+			return errorResponse(c, http.StatusBadRequest, "A reason is required to disqualify an entry")
+		}
+		update["$set"] = bson.M{"status": models.EntryDisqualified, "disqualified_reason": reason}
+	case models.EntryValid:
+		update["$set"] = bson.M{"status": models.EntryValid}
+		update["$unset"] = bson.M{"disqualified_reason": ""}
+	default:
+		return errorResponse(c, http.StatusBadRequest, "status must be valid or disqualified")
+	}
+
+	var entry models.ContestEntry
+	err = ContestEntryCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": entryID, "contest_id": contest.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Entry not found")
+	}
+	if err != nil {
+		log.Println("Update entry error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update entry")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Entry updated successfully", fiber.Map{"entry": entry})
+}
+
+// DrawContest - Staff draw winners and alternates once entries have closed
+func DrawContest(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, true)
+	if err != nil {
+		return contestError(c, err)
+	}
+	now := time.Now()
+	if contestState(contest, now) != "closed" {
+		return errorResponse(c, http.StatusConflict, "Entries are still open")
+	}
+	if contest.Draw != nil {
+		// a draw whose winners were not all saved is completed from the stored order
+		return saveDrawnWinners(ctx, c, contest, *contest.Draw)
+	}
+
+	cursor, err := ContestEntryCollectionInit().Find(ctx,
+		bson.M{"contest_id": contest.ID, "status": models.EntryValid},
+		options.Find().SetSort(bson.D{{Key: "entry_no", Value: 1}}),
+	)
+	if err != nil {
+		log.Println("Find entries error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to draw contest")
+	}
+	var entries []models.ContestEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to draw contest")
+	}
+	if len(entries) == 0 {
+		return errorResponse(c, http.StatusConflict, "There are no eligible entries to draw")
+	}
+
+	numbers := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		numbers = append(numbers, entry.Entry_no)
+	}
+	sort.Ints(numbers)
+
+	entriesHash := helpers.ContestEntriesHash(numbers)
+	order, err := helpers.ContestDrawOrder(contest.Seed, entriesHash, numbers, contest.Winners+contest.Alternates)
+	if err != nil {
+		log.Println("Draw error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to draw contest")
+	}
+
+	_, staff := revisionAuthor(c)
+	slots := min(contest.Winners, len(order))
+	draw := models.ContestDraw{
+		Seed:          contest.Seed,
+		Entries_hash:  entriesHash,
+		Entry_numbers: numbers,
+		Order:         order,
+		Next_position: slots,
+		Drawn_by:      staff,
+		Drawn_at:      primitive.NewDateTimeFromTime(now),
+	}
+
+	// the draw is stored first and only once, so a retried request cannot redraw
+	result, err := ContestCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": contest.ID, "draw": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"draw": draw, "updated_at": draw.Drawn_at}},
+	)
+	if err != nil {
+		log.Println("Store draw error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to draw contest")
+	}
+	if result.ModifiedCount == 0 {
+		return errorResponse(c, http.StatusConflict, "This contest has already been drawn")
+	}
+	return saveDrawnWinners(ctx, c, contest, draw)
+}
+
+// saveDrawnWinners saves the winners of a stored draw, one per slot. Each is
+// inserted only if missing, so it is safe to repeat after a failed save;
+// only the winners inserted now are emailed.
+func saveDrawnWinners(ctx context.Context, c fiber.Ctx, contest models.Contest, draw models.ContestDraw) error {
+	slots := min(contest.Winners, len(draw.Order))
+	deadline := primitive.NewDateTimeFromTime(draw.Drawn_at.Time().AddDate(0, 0, contest.Claim_days))
+
+	cursor, err := ContestEntryCollectionInit().Find(ctx, bson.M{"contest_id": contest.ID, "entry_no": bson.M{"$in": draw.Order[:slots]}})
+	if err != nil {
+		log.Println("Find drawn entries error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to save winners")
+	}
+	var entries []models.ContestEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to save winners")
+	}
+	byNumber := make(map[int]models.ContestEntry, len(entries))
+	for _, entry := range entries {
+		byNumber[entry.Entry_no] = entry
+	}
+
+	var saved []models.ContestWinner
+	for position := 0; position < slots; position++ {
+		entry, ok := byNumber[draw.Order[position]]
+		if !ok {
+			log.Printf("[CONTESTS] drawn entry #%d of %s is missing", draw.Order[position], contest.ID.Hex())
+			return errorResponse(c, http.StatusInternalServerError, "Failed to save winners")
+		}
+		winner := newContestWinner(contest, entry, position+1, position, deadline)
+		result, err := ContestWinnerCollectionInit().UpdateOne(ctx,
+			bson.M{"contest_id": contest.ID, "slot": winner.Slot, "draw_position": position},
+			bson.M{"$setOnInsert": winner},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Println("Save winner error:", err)
+			return errorResponse(c, http.StatusInternalServerError, "Draw stored but winners were not saved; draw again to finish")
+		}
+		if err == nil && result.UpsertedCount == 1 {
+			saved = append(saved, winner)
+		}
+	}
+	if len(saved) == 0 {
+		return errorResponse(c, http.StatusConflict, "This contest has already been drawn")
+	}
+	for _, winner := range saved {
+		notifyContestWinner(ctx, contest, winner)
+	}
+
+	winners, err := findContestWinners(ctx, contest.ID)
+	if err != nil {
+		log.Println("Find winners error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch winners")
+	}
+	return jsonResponse(c, http.StatusOK, "Contest drawn", fiber.Map{
+		"draw":    draw,
+		"winners": winners,
+	})
+}
+
+// notifyContestWinner queues the email with a winner's claim link
+func notifyContestWinner(ctx context.Context, contest models.Contest, winner models.ContestWinner) {
+	if winner.Email == "" {
+		return
+	}
+	rendered, err := mailer.Render(ctx, "contest-winner", map[string]interface{}{
+		"Name":           html.UnescapeString(winner.Name),
+		"Contest":        html.UnescapeString(contest.Title),
+		"Prize":          html.UnescapeString(contest.Prize),
+		"Entry_no":       winner.Entry_no,
+		"Claim_url":      helpers.ContestClaimURL(contest.ID.Hex(), helpers.SignContestClaim(winner.ID, contest.ID)),
+		"Claim_deadline": winner.Claim_deadline.Time().In(utils.LocationAsiaManila).Format("Mon, Jan 2 3:04 PM"),
+	})
+	if err == nil {
+		msg := rendered.Message()
+		msg.To, msg.From_name, msg.Tag = []string{winner.Email}, "Magic 89.9", "contest.winner"
+		_, err = mailer.Enqueue(ctx, msg)
+	}
+	if err != nil {
+		log.Println("Contest winner email not queued:", err)
+	}
+}
+
+func newContestWinner(contest models.Contest, entry models.ContestEntry, slot, position int, deadline primitive.DateTime) models.ContestWinner {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	return models.ContestWinner{
+		ID:             primitive.NewObjectID(),
+		Contest_id:     contest.ID,
+		Entry_id:       entry.ID,
+		Entry_no:       entry.Entry_no,
+		Slot:           slot,
+		Draw_position:  position,
+		Name:           entry.Name,
+		Email:          entry.Email,
+		Phone:          entry.Phone,
+		Status:         models.WinnerPending,
+		Claim_deadline: deadline,
+		Created_at:     now,
+		Updated_at:     now,
+	}
+}
+
+func findContestWinners(ctx context.Context, contestID primitive.ObjectID) ([]models.ContestWinner, error) {
+	cursor, err := ContestWinnerCollectionInit().Find(ctx, bson.M{"contest_id": contestID},
+		options.Find().SetSort(bson.D{{Key: "slot", Value: 1}, {Key: "draw_position", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	winners := []models.ContestWinner{}
+	err = cursor.All(ctx, &winners)
+	return winners, err
+}
+
+// promoteContestAlternate gives a slot to the next drawn alternate, if any are left
+func promoteContestAlternate(ctx context.Context, contest models.Contest, slot int) (*models.ContestWinner, error) {
+	// claim the next position atomically so two settlements never hand out the same one
+	var updated models.Contest
+	err := ContestCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": contest.ID, "draw.next_position": bson.M{"$lt": len(contest.Draw.Order)}},
+		bson.M{"$inc": bson.M{"draw.next_position": 1}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"draw.next_position": 1}),
+	).Decode(&updated) // the document before the increment
+	if err == mongo.ErrNoDocuments {
+		return nil, nil // no alternates left
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	position := updated.Draw.Next_position
+	var entry models.ContestEntry
+	if err := ContestEntryCollectionInit().FindOne(ctx, bson.M{"contest_id": contest.ID, "entry_no": contest.Draw.Order[position]}).Decode(&entry); err != nil {
+		return nil, err
+	}
+
+	deadline := primitive.NewDateTimeFromTime(time.Now().AddDate(0, 0, contest.Claim_days))
+	winner := newContestWinner(contest, entry, slot, position, deadline)
+	if _, err := ContestWinnerCollectionInit().InsertOne(ctx, winner); err != nil {
+		return nil, err
+	}
+	notifyContestWinner(ctx, contest, winner)
+	log.Printf("[CONTESTS] slot %d of %s passed to alternate entry #%d", slot, contest.ID.Hex(), entry.Entry_no)
+	return &winner, nil
+}
+
+// settleContestClaims expires pending winners past their deadline and passes their slots on
+func settleContestClaims(ctx context.Context, contest models.Contest) error {
+	if contest.Draw == nil {
+		return nil
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	for {
+		var expired models.ContestWinner
+		err := ContestWinnerCollectionInit().FindOneAndUpdate(ctx,
+			bson.M{"contest_id": contest.ID, "status": models.WinnerPending, "claim_deadline": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"status": models.WinnerExpired, "updated_at": now}},
+		).Decode(&expired)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := promoteContestAlternate(ctx, contest, expired.Slot); err != nil {
+			return err
+		}
+	}
+}
+
+// GetContestWinners - Staff view of every winner and alternate with contact details
+func GetContestWinners(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, true)
+	if err != nil {
+		return contestError(c, err)
+	}
+	if err := settleContestClaims(ctx, contest); err != nil {
+		log.Println("[CONTESTS] claim settlement failed:", err)
+	}
+
+	winners, err := findContestWinners(ctx, contest.ID)
+	if err != nil {
+		log.Println("Find winners error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch winners")
+	}
+
+	// claim links are emailed to pending winners; staff can pass them on by hand too
+	claims := make([]fiber.Map, 0, len(winners))
+	for _, winner := range winners {
+		item := fiber.Map{"winner": winner}
+		if winner.Status == models.WinnerPending {
+			item["claim_token"] = helpers.SignContestClaim(winner.ID, contest.ID)
+		}
+		claims = append(claims, item)
+	}
+
+	return jsonResponse(c, http.StatusOK, "Winners fetched successfully", fiber.Map{"winners": claims})
+}
+
+// ForfeitContestWinner - Staff remove an ineligible or unreachable winner; the slot goes to the next alternate
+func ForfeitContestWinner(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	contest, err := findContest(ctx, c, true)
+	if err != nil {
+		return contestError(c, err)
+	}
+	winnerID, err := primitive.ObjectIDFromHex(c.Params("winnerId"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Winner ID")
+	}
+
+	var body struct {
+		Note string `json:"note"`
+	}
+	_ = c.Bind().JSON(&body)
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var winner models.ContestWinner
+	err = ContestWinnerCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": winnerID, "contest_id": contest.ID, "status": bson.M{"$in": bson.A{models.WinnerPending, models.WinnerClaimed}}},
+		bson.M{"$set": bson.M{
+			"status":     models.WinnerForfeited,
+			"note":       strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(body.Note)),
+			"updated_at": now,
+		}},
+	).Decode(&winner)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Winner not found or no longer holding a prize")
+	}
+	if err != nil {
+		log.Println("Forfeit winner error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to forfeit winner")
+	}
+
+	alternate, err := promoteContestAlternate(ctx, contest, winner.Slot)
+	if err != nil {
+		log.Println("Promote alternate error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Winner forfeited but no alternate was promoted")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Winner forfeited", fiber.Map{"alternate": alternate})
+}
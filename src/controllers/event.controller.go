@@ -0,0 +1,675 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Event Controller (station events calendar)
+   -----------------------------------
+   1. Calendar for a month        GET    /events?month=YYYY-MM&series=&q= (current month by default)
+   2. Upcoming / past events      GET    /events/upcoming, /events/past?series=&page=&limit=
+   3. Get an event                GET    /events/:slug
+   4. Add to calendar (public)    GET    /events/:slug.ics (app root, linkable)
+   Staff only:
+   5. Unpublished events          GET    /events/drafts
+   6. Create / update / delete    POST   /events, PUT /events/:slug, DELETE /events/:slug
+   -----------------------------------
+   Lineup entries link to the station and the charts: a DJ is matched to
+   the show whose show_host is exactly their name (ignoring case) unless a
+   show_id is given, and an artist is shown with their chart tracks.
+   Names are stored as plain text, like the chart's artist names.
+   album_id links the photo gallery (album.controller.go).
+   An event is upcoming until it ends, so ongoing events are listed
+   there too. Cancelled events stay listed and their .ics says
+   STATUS:CANCELLED, so calendar apps pick up the change.
+   -----------------------------------
+   PATH: /api/v1/events
+*/
+
+const (
+	eventMaxTitleLength = 150
+	eventMaxDescLength  = 10000
+	eventMaxTextLength  = 500
+	eventMaxLineup      = 100
+	eventMaxSponsors    = 50
+	eventMaxDuration    = 14 * 24 * time.Hour
+	eventDefaultLimit   = 20
+	eventMaxLimit       = 100
+	eventMonthLimit     = 200
+	eventArtistTracks   = 3
+	eventCalendarCache  = "public, max-age=300"
+)
+
+// eventReservedSlugs collide with routes under /events
+var eventReservedSlugs = map[string]bool{"upcoming": true, "past": true, "drafts": true}
+
+var eventLineupKinds = map[string]bool{models.LineupDJ: true, models.LineupArtist: true, models.LineupGuest: true}
+
+var eventIndexesOnce sync.Once
+
+func EventCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "events")
+	eventIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "published", Value: 1}, {Key: "starts_at", Value: 1}}},
+			{Keys: bson.D{{Key: "published", Value: 1}, {Key: "ends_at", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[EVENTS] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+var errEventNotFound = errors.New("event not found")
+
+// findEvent loads the event named by slug; unpublished events only for staff
+func findEvent(ctx context.Context, slug string, staff bool) (models.Event, error) {
+	var event models.Event
+	filter := bson.M{"slug": slug}
+	if !staff {
+		filter["published"] = true
+	}
+	err := EventCollectionInit().FindOne(ctx, filter).Decode(&event)
+	if err == mongo.ErrNoDocuments {
+		return event, errEventNotFound
+	}
+	return event, err
+}
+
+func eventError(c fiber.Ctx, err error) error {
+	if err == errEventNotFound {
+		return errorResponse(c, http.StatusNotFound, "Event not found")
+	}
+	log.Println("Find event error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch event")
+}
+
+// eventState is upcoming, ongoing or past relative to now
+func eventState(event models.Event, now time.Time) string {
+	switch {
+	case now.Before(event.Starts_at.Time()):
+		return "upcoming"
+	case now.Before(event.Ends_at.Time()):
+		return "ongoing"
+	}
+	return "past"
+}
+
+// eventLink checks an optional http(s) URL
+func eventLink(field, raw string) (string, string) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", field + " must be an http(s) URL"
+	}
+	return u.String(), ""
+}
+
+// eventImage checks an optional media store image
+func eventImage(ctx context.Context, field, name string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", nil
+	}
+	key, err := storage.Key(storage.Images, name)
+	if err != nil {
+		return "", field + " must be a media store filename", nil
+	}
+	if _, err := storage.Default().Stat(ctx, key); errors.Is(err, storage.ErrNotFound) {
+		return "", field + " is not in the media store", nil
+	} else if err != nil {
+		return "", "", err
+	}
+	return name, "", nil
+}
+
+// normalizeEvent validates and cleans a staff event; a non-empty string is a problem for the client
+func normalizeEvent(ctx context.Context, event *models.Event) (string, error) {
+	policy := bluemonday.StrictPolicy()
+	text := func(value string) string { return strings.TrimSpace(policy.Sanitize(value)) }
+	var problem string
+	var err error
+
+	event.Title = text(event.Title)
+	event.Series = text(event.Series)
+	event.Description = strings.TrimSpace(bluemonday.UGCPolicy().Sanitize(event.Description))
+	if event.Slug == "" {
+		event.Slug = strings.ReplaceAll(event.Title, " ", "-")
+	}
+	event.Slug = utils.Slugify(strings.ReplaceAll(strings.TrimSpace(event.Slug), " ", "-"))
+	switch {
+	case event.Title == "" || len(event.Title) > eventMaxTitleLength:
+		return fmt.Sprintf("title is required (max %d characters)", eventMaxTitleLength), nil
+	case event.Slug == "" || eventReservedSlugs[event.Slug]:
+		return "slug is invalid", nil
+	case len(event.Series) > eventMaxTextLength:
+		return "series is too long", nil
+	case len(event.Description) > eventMaxDescLength:
+		return "description is too long", nil
+	case event.Starts_at == 0 || event.Ends_at == 0 || event.Ends_at <= event.Starts_at:
+		return "starts_at and ends_at are required and ends_at must be later", nil
+	case event.Ends_at.Time().Sub(event.Starts_at.Time()) > eventMaxDuration:
+		return "an event cannot be longer than 14 days", nil
+	}
+	if event.Image, problem, err = eventImage(ctx, "image", event.Image); problem != "" || err != nil {
+		return problem, err
+	}
+
+	venue := &event.Venue
+	venue.Name, venue.Address, venue.City = text(venue.Name), text(venue.Address), text(venue.City)
+	switch {
+	case venue.Name == "":
+		return "venue.name is required", nil
+	case len(venue.Name) > eventMaxTextLength || len(venue.Address) > eventMaxTextLength || len(venue.City) > eventMaxTextLength:
+		return "venue is too long", nil
+	case (venue.Lat == nil) != (venue.Lng == nil):
+		return "venue.lat and venue.lng go together", nil
+	case venue.Lat != nil && (*venue.Lat < -90 || *venue.Lat > 90 || *venue.Lng < -180 || *venue.Lng > 180):
+		return "venue.lat or venue.lng is out of range", nil
+	}
+	if venue.Map_url, problem = eventLink("venue.map_url", venue.Map_url); problem != "" {
+		return problem, nil
+	}
+
+	if problem, err = normalizeEventLineup(ctx, event); problem != "" || err != nil {
+		return problem, err
+	}
+
+	tickets := &event.Tickets
+	tickets.Notes = text(tickets.Notes)
+	if tickets.Free {
+		tickets.Price_min, tickets.Price_max = 0, 0
+	}
+	switch {
+	case tickets.Price_min < 0 || tickets.Price_max < 0:
+		return "ticket prices cannot be negative", nil
+	case tickets.Price_max != 0 && tickets.Price_max < tickets.Price_min:
+		return "tickets.price_max cannot be lower than price_min", nil
+	case len(tickets.Notes) > eventMaxTextLength:
+		return "tickets.notes is too long", nil
+	}
+	if tickets.Url, problem = eventLink("tickets.url", tickets.Url); problem != "" {
+		return problem, nil
+	}
+
+	if len(event.Sponsors) > eventMaxSponsors {
+		return fmt.Sprintf("an event cannot have more than %d sponsors", eventMaxSponsors), nil
+	}
+	sponsors := []models.EventSponsor{}
+	for _, sponsor := range event.Sponsors {
+		sponsor.Name, sponsor.Tier = text(sponsor.Name), text(sponsor.Tier)
+		if sponsor.Name == "" || len(sponsor.Name) > eventMaxTextLength || len(sponsor.Tier) > eventMaxTextLength {
+			return "every sponsor needs a name (max 500 characters)", nil
+		}
+		if sponsor.Url, problem = eventLink("sponsor url", sponsor.Url); problem != "" {
+			return problem, nil
+		}
+		if sponsor.Logo, problem, err = eventImage(ctx, "sponsor logo", sponsor.Logo); problem != "" || err != nil {
+			return problem, err
+		}
+		sponsors = append(sponsors, sponsor)
+	}
+	event.Sponsors = sponsors
+
+	if event.Album_id != nil {
+		count, err := AlbumCollectionInit().CountDocuments(ctx, bson.M{"_id": *event.Album_id})
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "album_id does not match any album", nil
+		}
+	}
+	return "", nil
+}
+
+// eventNamePattern matches a stored name that is exactly name, ignoring case and
+// surrounding spaces, whether it was stored as text or HTML-escaped
+func eventNamePattern(name string) primitive.Regex {
+	pattern := regexp.QuoteMeta(name)
+	if escaped := html.EscapeString(name); escaped != name {
+		pattern = "(?:" + pattern + "|" + regexp.QuoteMeta(escaped) + ")"
+	}
+	return primitive.Regex{Pattern: `^\s*` + pattern + `\s*$`, Options: "i"}
+}
+
+// normalizeEventLineup cleans the lineup and links DJs to their shows
+func normalizeEventLineup(ctx context.Context, event *models.Event) (string, error) {
+	if len(event.Lineup) > eventMaxLineup {
+		return fmt.Sprintf("a lineup cannot have more than %d entries", eventMaxLineup), nil
+	}
+	lineup := []models.EventLineupEntry{}
+	for _, entry := range event.Lineup {
+		entry.Name = helpers.PlainText(entry.Name)
+		entry.Kind = strings.ToLower(strings.TrimSpace(entry.Kind))
+		if entry.Kind == "" {
+			entry.Kind = models.LineupArtist
+		}
+		switch {
+		case entry.Name == "" || len(entry.Name) > eventMaxTextLength:
+			return "every lineup entry needs a name (max 500 characters)", nil
+		case !eventLineupKinds[entry.Kind]:
+			return "lineup kind must be dj, artist or guest", nil
+		case entry.Set_time != 0 && (entry.Set_time < event.Starts_at || entry.Set_time > event.Ends_at):
+			return entry.Name + ": set_time must be during the event", nil
+		}
+
+		if entry.Kind != models.LineupDJ {
+			entry.Show_id = nil
+		} else if entry.Show_id != nil {
+			count, err := ShowsCollectionInit().CountDocuments(ctx, bson.M{"_id": *entry.Show_id})
+			if err != nil {
+				return "", err
+			}
+			if count == 0 {
+				return entry.Name + ": show_id does not match any show", nil
+			}
+		} else {
+			var show models.Shows
+			err := ShowsCollectionInit().FindOne(ctx, bson.M{
+				"show_host": eventNamePattern(entry.Name),
+			}).Decode(&show)
+			if err == nil {
+				entry.Show_id = &show.ID
+			} else if err != mongo.ErrNoDocuments {
+				return "", err
+			}
+		}
+		lineup = append(lineup, entry)
+	}
+	event.Lineup = lineup
+	return "", nil
+}
+
+// eventSummary is what calendar and list views need
+func eventSummary(event models.Event, now time.Time) fiber.Map {
+	return fiber.Map{
+		"id":           event.ID,
+		"slug":         event.Slug,
+		"title":        event.Title,
+		"series":       event.Series,
+		"image":        event.Image,
+		"image_url":    helpers.PublicImageURL(event.Image),
+		"venue":        event.Venue,
+		"starts_at":    event.Starts_at,
+		"ends_at":      event.Ends_at,
+		"state":        eventState(event, now),
+		"cancelled":    event.Cancelled,
+		"published":    event.Published,
+		"tickets":      helpers.EventTicketSummary(event.Tickets),
+		"url":          helpers.EventURL(event.Slug),
+		"calendar_url": helpers.EventCalendarURL(event.Slug),
+	}
+}
+
+// eventDetail adds the lineup with its show and chart links, sponsors and the gallery
+func eventDetail(ctx context.Context, event models.Event) (fiber.Map, error) {
+	detail := eventSummary(event, time.Now())
+	detail["description"] = event.Description
+	detail["tickets"] = event.Tickets
+	detail["ticket_summary"] = helpers.EventTicketSummary(event.Tickets)
+	detail["created_at"], detail["updated_at"] = event.Created_at, event.Updated_at
+
+	var showIDs []primitive.ObjectID
+	var artists bson.A
+	// older events stored the names HTML-escaped
+	names := make([]string, len(event.Lineup))
+	for i, entry := range event.Lineup {
+		names[i] = html.UnescapeString(entry.Name)
+		if entry.Show_id != nil {
+			showIDs = append(showIDs, *entry.Show_id)
+		}
+		if entry.Kind == models.LineupArtist {
+			artists = append(artists, eventNamePattern(names[i]))
+		}
+	}
+
+	shows := map[primitive.ObjectID]models.Shows{}
+	if len(showIDs) > 0 {
+		cursor, err := ShowsCollectionInit().Find(ctx, bson.M{"_id": bson.M{"$in": showIDs}})
+		if err != nil {
+			return nil, err
+		}
+		var found []models.Shows
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		for _, show := range found {
+			shows[show.ID] = show
+		}
+	}
+
+	tracks := map[string][]fiber.Map{}
+	if len(artists) > 0 {
+		cursor, err := MusicCollectionInit().Find(ctx, bson.M{"artist": bson.M{"$in": artists}},
+			options.Find().SetSort(bson.D{{Key: "votes", Value: -1}}).SetLimit(200))
+		if err != nil {
+			return nil, err
+		}
+		var found []models.Music
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		for _, music := range found {
+			for _, artist := range music.Artist {
+				key := strings.ToLower(strings.TrimSpace(artist))
+				if len(tracks[key]) < eventArtistTracks {
+					tracks[key] = append(tracks[key], fiber.Map{"id": music.ID, "title": music.Title, "url": helpers.MusicURL(music)})
+				}
+			}
+		}
+	}
+
+	lineup := make([]fiber.Map, 0, len(event.Lineup))
+	for i, entry := range event.Lineup {
+		item := fiber.Map{"name": names[i], "kind": entry.Kind}
+		if entry.Set_time != 0 {
+			item["set_time"] = entry.Set_time
+		}
+		if entry.Show_id != nil {
+			if show, ok := shows[*entry.Show_id]; ok && show.Show_name != nil {
+				item["show"] = fiber.Map{"id": show.ID, "name": *show.Show_name, "url": helpers.ShowURL(show)}
+			}
+		}
+		if entry.Kind == models.LineupArtist {
+			found := tracks[strings.ToLower(strings.TrimSpace(names[i]))]
+			if found == nil {
+				found = []fiber.Map{}
+			}
+			item["tracks"] = found
+		}
+		lineup = append(lineup, item)
+	}
+	detail["lineup"] = lineup
+
+	sponsors := make([]fiber.Map, 0, len(event.Sponsors))
+	for _, sponsor := range event.Sponsors {
+		sponsors = append(sponsors, fiber.Map{
+			"name": sponsor.Name, "tier": sponsor.Tier, "url": sponsor.Url,
+			"logo": sponsor.Logo, "logo_url": helpers.PublicImageURL(sponsor.Logo),
+		})
+	}
+	detail["sponsors"] = sponsors
+
+	detail["gallery"] = nil
+	if event.Album_id != nil {
+		var album models.PhotoAlbum
+		err := AlbumCollectionInit().FindOne(ctx, bson.M{"_id": *event.Album_id, "published": true},
+			options.FindOne().SetProjection(bson.M{"images": 0})).Decode(&album)
+		if err == nil {
+			detail["gallery"] = albumSummary(album)
+		} else if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	}
+	return detail, nil
+}
+
+// eventSeriesFilter adds ?series= (case-insensitive) to filter
+func eventSeriesFilter(c fiber.Ctx, filter bson.M) {
+	if series := strings.TrimSpace(c.Query("series")); series != "" {
+		filter["series"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(series) + "$", Options: "i"}
+	}
+}
+
+// findEvents runs an event query and returns the summaries
+func findEvents(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]fiber.Map, error) {
+	cursor, err := EventCollectionInit().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Event
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	events := make([]fiber.Map, 0, len(found))
+	for _, event := range found {
+		events = append(events, eventSummary(event, now))
+	}
+	return events, nil
+}
+
+// GetEvents - Calendar: published events that overlap a month (Asia/Manila)
+func GetEvents(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	month := time.Now().In(utils.LocationAsiaManila)
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, utils.LocationAsiaManila)
+	if raw := c.Query("month"); raw != "" {
+		parsed, err := time.ParseInLocation("2006-01", raw, utils.LocationAsiaManila)
+		if err != nil {
+			return errorResponse(c, http.StatusBadRequest, "month must be YYYY-MM")
+		}
+		month = parsed
+	}
+	from := primitive.NewDateTimeFromTime(month)
+	to := primitive.NewDateTimeFromTime(month.AddDate(0, 1, 0))
+
+	filter := bson.M{"published": true, "starts_at": bson.M{"$lt": to}, "ends_at": bson.M{"$gt": from}}
+	eventSeriesFilter(c, filter)
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"title": pattern}, bson.M{"venue.name": pattern}, bson.M{"lineup.name": pattern}}
+	}
+
+	events, err := findEvents(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "starts_at", Value: 1}}).
+		SetLimit(eventMonthLimit))
+	if err != nil {
+		log.Println("Find events error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch events")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Events fetched successfully", fiber.Map{
+		"month":  month.Format("2006-01"),
+		"from":   from,
+		"to":     to,
+		"events": events,
+	})
+}
+
+// GetUpcomingEvents - Events that have not ended, soonest first
+func GetUpcomingEvents(c fiber.Ctx) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	return listEvents(c, bson.M{"published": true, "ends_at": bson.M{"$gt": now}}, 1)
+}
+
+// GetPastEvents - Events that have ended, latest first
+func GetPastEvents(c fiber.Ctx) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	return listEvents(c, bson.M{"published": true, "ends_at": bson.M{"$lte": now}}, -1)
+}
+
+// GetEventDrafts - Unpublished events, soonest first
+func GetEventDrafts(c fiber.Ctx) error {
+	return listEvents(c, bson.M{"published": false}, 1)
+}
+
+// listEvents is a paginated list sorted by start time
+func listEvents(c fiber.Ctx, filter bson.M, order int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	eventSeriesFilter(c, filter)
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(eventDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = eventDefaultLimit
+	}
+	limit = min(limit, eventMaxLimit)
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	total, err := EventCollectionInit().CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count events error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch events")
+	}
+	events, err := findEvents(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "starts_at", Value: order}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		log.Println("Find events error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch events")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Events fetched successfully", fiber.Map{
+		"events": events,
+		"page":   page,
+		"limit":  limit,
+		"total":  total,
+	})
+}
+
+// GetEvent - A published event with its lineup, tickets, sponsors and gallery
+func GetEvent(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event, err := findEvent(ctx, c.Params("slug"), false)
+	if err != nil {
+		return eventError(c, err)
+	}
+	detail, err := eventDetail(ctx, event)
+	if err != nil {
+		log.Println("Event links error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch event")
+	}
+	return jsonResponse(c, http.StatusOK, "Event fetched successfully", fiber.Map{"event": detail})
+}
+
+// GetEventCalendar - The event as an .ics file for calendar apps
+func GetEventCalendar(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event, err := findEvent(ctx, c.Params("slug"), false)
+	if err != nil {
+		return eventError(c, err)
+	}
+	c.Attachment(event.Slug + ".ics")
+	return sendCacheable(c, []byte(helpers.EventICS(event)), "text/calendar; charset=utf-8", event.Updated_at.Time(), eventCalendarCache)
+}
+
+// CreateEvent - New event; slug defaults to the title
+func CreateEvent(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	var event models.Event
+	if err := c.Bind().JSON(&event); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	problem, err := normalizeEvent(ctx, &event)
+	if err != nil {
+		log.Println("Event validation error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create event")
+	}
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	_, author := revisionAuthor(c)
+	event.ID, event.Sequence = primitive.NewObjectID(), 0
+	event.Created_by, event.Created_at, event.Updated_at = author, now, now
+	if _, err := EventCollectionInit().InsertOne(ctx, event); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "An event with this slug already exists")
+		}
+		log.Println("Insert event error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create event")
+	}
+	return jsonResponse(c, http.StatusCreated, "Event created successfully", fiber.Map{"event": event})
+}
+
+// UpdateEvent - Replace an event; every edit bumps the calendar SEQUENCE
+func UpdateEvent(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	current, err := findEvent(ctx, c.Params("slug"), true)
+	if err != nil {
+		return eventError(c, err)
+	}
+	var event models.Event
+	if err := c.Bind().JSON(&event); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	problem, err := normalizeEvent(ctx, &event)
+	if err != nil {
+		log.Println("Event validation error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update event")
+	}
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	event.ID, event.Sequence = current.ID, current.Sequence+1
+	event.Created_by, event.Created_at = current.Created_by, current.Created_at
+	event.Updated_at = primitive.NewDateTimeFromTime(time.Now())
+	if _, err := EventCollectionInit().ReplaceOne(ctx, bson.M{"_id": current.ID}, event); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "An event with this slug already exists")
+		}
+		log.Println("Update event error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update event")
+	}
+	return jsonResponse(c, http.StatusOK, "Event updated successfully", fiber.Map{"event": event})
+}
+
+// DeleteEvent - Remove an event; cancelling (cancelled: true) is kinder to people who saved it
+func DeleteEvent(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event, err := findEvent(ctx, c.Params("slug"), true)
+	if err != nil {
+		return eventError(c, err)
+	}
+	if _, err := EventCollectionInit().DeleteOne(ctx, bson.M{"_id": event.ID}); err != nil {
+		log.Println("Delete event error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to delete event")
+	}
+	_, author := revisionAuthor(c)
+	log.Printf("[EVENTS] %s deleted %s", author, event.Slug)
+	return jsonResponse(c, http.StatusOK, "Event deleted successfully", fiber.Map{"slug": event.Slug})
+}
@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"log"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   News Feeds (public, no RSP token)
+   -----------------------------------
+   1. RSS 2.0   /feeds/news.xml   /feeds/news/:category.xml
+   2. Atom 1.0  /feeds/news.atom  /feeds/news/:category.atom
+   3. JSON Feed /feeds/news.json  /feeds/news/:category.json
+   -----------------------------------
+   Only approved news. Supports If-None-Match / If-Modified-Since.
+*/
+
+const (
+	feedLimit    = 50
+	feedTitle    = "Magic 89.9"
+	feedSubtitle = "News from Magic 89.9"
+)
+
+// feedSource is the list of approved news items (newest first) for a feed
+type feedSource struct {
+	Category string
+	News     []models.News
+	Updated  time.Time
+}
+
+func findFeedCategory(param string) (string, bool) {
+	for _, category := range models.NewsCategories {
+		if strings.EqualFold(category, param) {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+func loadFeedSource(c fiber.Ctx) (*feedSource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	source := &feedSource{}
+	filter := bson.M{"status": "approved"}
+
+	if param := c.Params("category"); param != "" {
+		category, ok := findFeedCategory(param)
+		if !ok {
+			return nil, fiber.ErrNotFound
+		}
+		source.Category = category
+		filter["category"] = bson.M{"$regex": "^" + regexp.QuoteMeta(category) + "$", "$options": "i"}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(feedLimit)
+	cursor, err := NewsCollectionInit().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &source.News); err != nil {
+		return nil, err
+	}
+
+	for _, news := range source.News {
+		if t := news.Updated_at.Time(); t.After(source.Updated) {
+			source.Updated = t
+		}
+	}
+	if source.Updated.IsZero() {
+		source.Updated = time.Unix(0, 0)
+	}
+	source.Updated = source.Updated.UTC().Truncate(time.Second)
+
+	return source, nil
+}
+
+// feedURL is the absolute URL of the current feed (self link)
+func feedURL(c fiber.Ctx) string {
+	return utils.ServerOrigin() + c.Path()
+}
+
+func feedTitleFor(source *feedSource) string {
+	if source.Category == "" {
+		return feedTitle + " News"
+	}
+	return feedTitle + " " + source.Category
+}
+
+// newsGUID never changes for an item, even if its title or category is edited
+func newsGUID(id primitive.ObjectID) string {
+	return "tag:magic899.com,2026:news/" + id.Hex()
+}
+
+// feedImage describes the lead image of a news item for enclosures
+type feedImage struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+func newsFeedImage(news models.News) *feedImage {
+	if news.News_Image == "" {
+		return nil
+	}
+
+	image := &feedImage{
+		URL:  helpers.PublicImageURL(news.News_Image),
+		Type: mime.TypeByExtension(strings.ToLower(filepath.Ext(news.News_Image))),
+	}
+	if image.Type == "" {
+		image.Type = "image/jpeg"
+	}
+
+	// Media store files carry their real size
+	if key, err := storage.Key(storage.Images, news.News_Image); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if obj, err := storage.Default().Stat(ctx, key); err == nil {
+			image.Length = obj.Size
+		}
+		cancel()
+	}
+	return image
+}
+
+func newsSummary(news models.News) string {
+	return helpers.Summarize(helpers.ContentPlainText(news.Content), 280)
+}
+
+func publishedAt(news models.News) time.Time {
+	return news.Created_at.Time().UTC()
+}
+
+// sendFeed writes a feed body with validators and answers conditional requests with 304
+func sendFeed(c fiber.Ctx, body []byte, contentType string, lastModified time.Time) error {
+	return sendCacheable(c, body, contentType, lastModified, "public, max-age=300")
+}
+
+// sendCacheable sends body with a strong ETag (hash of the bytes) and Last-Modified,
+// answering conditional requests with 304
+func sendCacheable(c fiber.Ctx, body []byte, contentType string, lastModified time.Time, cacheControl string) error {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	// Feeds and images are cacheable, unlike the API responses CORSMiddleware marks as no-store
+	c.Response().Header.Del("Pragma")
+	c.Response().Header.Del("Expires")
+	c.Set("Cache-Control", cacheControl)
+	c.Set("ETag", etag)
+	c.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if match := c.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+		}
+	} else if since := c.Get("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	c.Set("Content-Type", contentType)
+	return c.Status(http.StatusOK).Send(body)
+}
+
+func feedError(c fiber.Ctx, err error) error {
+	if err == fiber.ErrNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Feed not found"})
+	}
+	log.Println("Feed error:", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build feed"})
+}
+
+// GetNewsRSS - RSS 2.0 feed of approved news
+func GetNewsRSS(c fiber.Ctx) error {
+	source, err := loadFeedSource(c)
+	if err != nil {
+		return feedError(c, err)
+	}
+
+	channel := models.RSSChannel{
+		Title:         feedTitleFor(source),
+		Link:          utils.SiteOrigin() + "/news",
+		Description:   feedSubtitle,
+		Language:      "en-ph",
+		LastBuildDate: source.Updated.Format(time.RFC1123Z),
+		AtomLink:      models.RSSAtomLink{Href: feedURL(c), Rel: "self", Type: "application/rss+xml"},
+		Items:         []models.RSSItem{},
+	}
+
+	for _, news := range source.News {
+		item := models.RSSItem{
+			Title:          news.Title,
+			Link:           helpers.NewsURL(news),
+			Description:    newsSummary(news),
+			ContentEncoded: models.RSSCDATA{Text: helpers.RenderContentHTML(news.Content, helpers.PublicImageBase())},
+			Creator:        news.Writer,
+			Category:       news.Category,
+			GUID:           models.RSSGUID{IsPermaLink: "false", Value: newsGUID(news.ID)},
+			PubDate:        publishedAt(news).Format(time.RFC1123Z),
+		}
+		if image := newsFeedImage(news); image != nil {
+			item.Enclosure = &models.RSSEnclosure{URL: image.URL, Length: image.Length, Type: image.Type}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	body, err := xml.MarshalIndent(models.RSS{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel:   channel,
+	}, "", "  ")
+	if err != nil {
+		return feedError(c, err)
+	}
+
+	return sendFeed(c, append([]byte(xml.Header), body...), "application/rss+xml; charset=utf-8", source.Updated)
+}
+
+// GetNewsAtom - Atom 1.0 feed of approved news
+func GetNewsAtom(c fiber.Ctx) error {
+	source, err := loadFeedSource(c)
+	if err != nil {
+		return feedError(c, err)
+	}
+
+	feed := models.AtomFeed{
+		Title:    feedTitleFor(source),
+		ID:       "tag:magic899.com,2026:feeds/news/" + strings.ToLower(source.Category),
+		Updated:  source.Updated.Format(time.RFC3339),
+		Subtitle: feedSubtitle,
+		Links: []models.AtomLink{
+			{Href: feedURL(c), Rel: "self", Type: "application/atom+xml"},
+			{Href: utils.SiteOrigin() + "/news", Rel: "alternate", Type: "text/html"},
+		},
+		Entries: []models.AtomEntry{},
+	}
+
+	for _, news := range source.News {
+		entry := models.AtomEntry{
+			Title:     news.Title,
+			ID:        newsGUID(news.ID),
+			Updated:   news.Updated_at.Time().UTC().Format(time.RFC3339),
+			Published: publishedAt(news).Format(time.RFC3339),
+			Links:     []models.AtomLink{{Href: helpers.NewsURL(news), Rel: "alternate", Type: "text/html"}},
+			Summary:   newsSummary(news),
+			Content:   models.AtomContent{Type: "html", Body: helpers.RenderContentHTML(news.Content, helpers.PublicImageBase())},
+		}
+		if news.Writer != "" {
+			entry.Author = &models.AtomAuthor{Name: news.Writer}
+		}
+		if news.Category != "" {
+			entry.Category = &models.AtomCategory{Term: news.Category}
+		}
+		if image := newsFeedImage(news); image != nil {
+			entry.Links = append(entry.Links, models.AtomLink{Href: image.URL, Rel: "enclosure", Type: image.Type, Length: image.Length})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return feedError(c, err)
+	}
+
+	return sendFeed(c, append([]byte(xml.Header), body...), "application/atom+xml; charset=utf-8", source.Updated)
+}
+
+// GetNewsJSONFeed - JSON Feed 1.1 of approved news
+func GetNewsJSONFeed(c fiber.Ctx) error {
+	source, err := loadFeedSource(c)
+	if err != nil {
+		return feedError(c, err)
+	}
+
+	feed := models.JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitleFor(source),
+		HomePageURL: utils.SiteOrigin() + "/news",
+		FeedURL:     feedURL(c),
+		Description: feedSubtitle,
+		Language:    "en-PH",
+		Items:       []models.JSONFeedItem{},
+	}
+
+	for _, news := range source.News {
+		item := models.JSONFeedItem{
+			ID:            newsGUID(news.ID),
+			URL:           helpers.NewsURL(news),
+			Title:         news.Title,
+			ContentHTML:   helpers.RenderContentHTML(news.Content, helpers.PublicImageBase()),
+			Summary:       newsSummary(news),
+			DatePublished: publishedAt(news).Format(time.RFC3339),
+			DateModified:  news.Updated_at.Time().UTC().Format(time.RFC3339),
+		}
+		if news.Writer != "" {
+			item.Authors = []models.JSONFeedAuthor{{Name: news.Writer}}
+		}
+		if news.Category != "" {
+			item.Tags = []string{news.Category}
+		}
+		if image := newsFeedImage(news); image != nil {
+			item.Image = image.URL
+			item.Attachments = []models.JSONFeedAttachment{{URL: image.URL, MimeType: image.Type, SizeInBytes: image.Length}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	body, err := json.Marshal(feed)
+	if err != nil {
+		return feedError(c, err)
+	}
+
+	return sendFeed(c, body, "application/feed+json; charset=utf-8", source.Updated)
+}
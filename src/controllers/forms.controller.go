@@ -0,0 +1,893 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/mailer"
+	"magic-server-2026/src/media"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Forms Engine
+   -----------------------------------
+   Public:
+   1. Get a form                  GET  /forms/:slug
+   2. Submit                      POST /forms/:slug/submissions (JSON, or multipart for file fields)
+   Staff only:
+   3. List / create forms         GET  /forms, POST /forms
+   4. Update a form               PUT  /forms/:slug
+   5. Browse submissions          GET  /forms/:slug/submissions?page=&limit=&from=&to=
+   6. Export submissions          GET  /forms/:slug/submissions/export (CSV)
+   7. Submitted files             GET  /forms/:slug/files/:file
+   -----------------------------------
+   Forms are data (models.Form): fields with their validation rules, staff
+   recipients and an auto-reply. models.ShoutboxFormDefinition is seeded
+   as "shoutbox" (published, notifying MAIL_STAFF_INBOX) to serve
+   RequestShoutbox as a form.
+   -----------------------------------
+   PATH: /api/v1/forms
+*/
+
+const (
+	formMaxFields        = 50
+	formTextMaxChars     = 500
+	formTextareaMaxChars = 5000
+	formFileDefaultMB    = 10
+	formFileMaxMB        = 60
+	formDefaultLimit     = 20
+	formMaxLimit         = 100
+)
+
+var (
+	formKeyPattern         = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+	formPlaceholderPattern = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)\s*\}\}`)
+	formFieldTypes         = map[string]bool{
+		models.FieldText: true, models.FieldTextarea: true, models.FieldEmail: true, models.FieldPhone: true,
+		models.FieldNumber: true, models.FieldDate: true, models.FieldSelect: true, models.FieldMultiselect: true,
+		models.FieldCheckbox: true, models.FieldFile: true,
+	}
+	formFileKinds = map[string]bool{models.FileImage: true, models.FileAudio: true, models.FileVideo: true, models.FilePDF: true}
+)
+
+var formIndexesOnce sync.Once
+
+func FormCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "forms")
+	formIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Println("[FORMS] form index creation failed:", err)
+		}
+
+		_, err = FormSubmissionCollectionInit().Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "form_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{
+				Keys: bson.D{{Key: "form_id", Value: 1}, {Key: "unique_value", Value: 1}},
+				Options: options.Index().SetUnique(true).
+					SetPartialFilterExpression(bson.M{"unique_value": bson.M{"$type": "string"}}),
+			},
+		})
+		if err != nil {
+			log.Println("[FORMS] submission index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+func FormSubmissionCollectionInit() *mongo.Collection {
+	return db.GetCollection("magic899_db", "form_submissions")
+}
+
+// InitForms seeds the built-in form definitions that are missing
+func InitForms() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	form := models.ShoutboxFormDefinition()
+	form.Notify = []string{mailer.StaffInbox()}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	form.ID, form.Created_by, form.Created_at, form.Updated_at = primitive.NewObjectID(), "system", now, now
+	_, err := FormCollectionInit().UpdateOne(ctx,
+		bson.M{"slug": form.Slug},
+		bson.M{"$setOnInsert": form},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Println("[FORMS] seeding failed:", err)
+		return
+	}
+
+	// earlier seeds were unpublished and went to a fixed inbox; bring them up
+	// to date unless staff have edited the form since
+	_, err = FormCollectionInit().UpdateOne(ctx,
+		bson.M{
+			"slug":       form.Slug,
+			"created_by": "system",
+			"$expr":      bson.M{"$eq": bson.A{"$updated_at", "$created_at"}},
+		},
+		bson.M{"$set": bson.M{"published": true, "notify": form.Notify}},
+	)
+	if err != nil {
+		log.Println("[FORMS] publishing the seeded form failed:", err)
+	}
+}
+
+// normalizeForm validates and cleans a staff form definition
+func normalizeForm(form *models.Form) string {
+	policy := bluemonday.StrictPolicy()
+	form.Title = strings.TrimSpace(policy.Sanitize(form.Title))
+	form.Description = strings.TrimSpace(bluemonday.UGCPolicy().Sanitize(form.Description))
+	form.Success_message = strings.TrimSpace(policy.Sanitize(form.Success_message))
+	if form.Slug == "" {
+		form.Slug = utils.Slugify(strings.ReplaceAll(form.Title, " ", "-"))
+	}
+	form.Slug = utils.Slugify(form.Slug)
+
+	switch {
+	case form.Title == "":
+		return "title is required"
+	case form.Slug == "":
+		return "slug is invalid"
+	case len(form.Fields) == 0 || len(form.Fields) > formMaxFields:
+		return "a form needs between 1 and " + strconv.Itoa(formMaxFields) + " fields"
+	case form.Opens_at != 0 && form.Closes_at != 0 && form.Closes_at <= form.Opens_at:
+		return "closes_at must be later than opens_at"
+	}
+
+	keys := map[string]string{}
+	for i := range form.Fields {
+		field := &form.Fields[i]
+		field.Label = strings.TrimSpace(policy.Sanitize(field.Label))
+		field.Help = strings.TrimSpace(policy.Sanitize(field.Help))
+		if field.Type == "" {
+			field.Type = models.FieldText
+		}
+		switch {
+		case !formKeyPattern.MatchString(field.Key) || keys[field.Key] != "":
+			return "field keys must be unique, lowercase letters, digits and _"
+		case field.Label == "":
+			return field.Key + ": label is required"
+		case !formFieldTypes[field.Type]:
+			return field.Key + ": unknown field type " + field.Type
+		case (field.Type == models.FieldSelect || field.Type == models.FieldMultiselect) && len(field.Options) == 0:
+			return field.Key + ": options are required"
+		case field.Min_length < 0 || field.Max_length < 0 || (field.Max_length > 0 && field.Min_length > field.Max_length):
+			return field.Key + ": invalid length limits"
+		case field.Min != nil && field.Max != nil && *field.Min > *field.Max:
+			return field.Key + ": min is greater than max"
+		}
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				return field.Key + ": invalid pattern"
+			}
+		}
+		if field.Type == models.FieldFile {
+			if len(field.Accept) == 0 {
+				return field.Key + ": accept is required for file fields"
+			}
+			for _, kind := range field.Accept {
+				if !formFileKinds[kind] {
+					return field.Key + ": accept must be image, audio, video or pdf"
+				}
+			}
+			if field.Max_size_mb <= 0 {
+				field.Max_size_mb = formFileDefaultMB
+			}
+			field.Max_size_mb = min(field.Max_size_mb, formFileMaxMB)
+		}
+		keys[field.Key] = field.Type
+	}
+
+	if form.Unique_field != "" && keys[form.Unique_field] == "" {
+		return "unique_field must be one of the form's fields"
+	}
+	notify := []string{}
+	for _, address := range form.Notify {
+		address = helpers.NormalizeEmail(address)
+		if address == "" {
+			return "notify contains an invalid email address"
+		}
+		notify = append(notify, address)
+	}
+	form.Notify = notify
+
+	if reply := form.Auto_reply; reply != nil {
+		if keys[reply.Email_field] != models.FieldEmail {
+			return "auto_reply.email_field must be an email field"
+		}
+		reply.Subject = strings.TrimSpace(policy.Sanitize(reply.Subject))
+		reply.Body = bluemonday.UGCPolicy().Sanitize(reply.Body)
+		if reply.Subject == "" || strings.TrimSpace(reply.Body) == "" {
+			return "auto_reply needs a subject and body"
+		}
+	}
+	return ""
+}
+
+// formOpen reports whether a published form takes submissions now
+func formOpen(form models.Form, now time.Time) bool {
+	if form.Opens_at != 0 && now.Before(form.Opens_at.Time()) {
+		return false
+	}
+	return form.Closes_at == 0 || now.Before(form.Closes_at.Time())
+}
+
+func findForm(ctx context.Context, slug string, staff bool) (models.Form, error) {
+	var form models.Form
+	filter := bson.M{"slug": slug}
+	if !staff {
+		filter["published"] = true
+	}
+	err := FormCollectionInit().FindOne(ctx, filter).Decode(&form)
+	return form, err
+}
+
+func formError(c fiber.Ctx, err error) error {
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Form not found")
+	}
+	log.Println("Find form error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch form")
+}
+
+// bindFormValues reads a submission as raw strings per key, plus uploaded files
+func bindFormValues(c fiber.Ctx) (map[string][]string, map[string]*multipart.FileHeader, error) {
+	files := map[string]*multipart.FileHeader{}
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return nil, nil, err
+		}
+		for key, headers := range form.File {
+			if len(headers) > 0 {
+				files[key] = headers[0]
+			}
+		}
+		return form.Value, files, nil
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return nil, nil, err
+	}
+	values := map[string][]string{}
+	for key, value := range body {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				values[key] = append(values[key], fmt.Sprint(item))
+			}
+		case nil:
+		default:
+			values[key] = []string{fmt.Sprint(v)}
+		}
+	}
+	return values, files, nil
+}
+
+// checkFormValue validates one field's raw input and returns the typed value to store.
+// Values are stored as typed, not HTML-escaped; emails and exports escape them when rendering.
+func checkFormValue(field models.FormField, raw []string) (interface{}, string) {
+	first := ""
+	if len(raw) > 0 {
+		first = strings.TrimSpace(raw[0])
+	}
+	if first == "" && field.Type != models.FieldMultiselect && field.Type != models.FieldCheckbox {
+		if field.Required {
+			return nil, field.Label + " is required"
+		}
+		return nil, ""
+	}
+
+	switch field.Type {
+	case models.FieldText, models.FieldTextarea:
+		maxChars := field.Max_length
+		if maxChars == 0 {
+			maxChars = formTextMaxChars
+			if field.Type == models.FieldTextarea {
+				maxChars = formTextareaMaxChars
+			}
+		}
+		length := len([]rune(first))
+		if length < field.Min_length {
+			return nil, field.Label + " must be at least " + strconv.Itoa(field.Min_length) + " characters"
+		}
+		if length > maxChars {
+			return nil, field.Label + " must be at most " + strconv.Itoa(maxChars) + " characters"
+		}
+		if field.Pattern != "" {
+			if pattern, err := regexp.Compile(field.Pattern); err == nil && !pattern.MatchString(first) {
+				return nil, field.Label + " is not in the expected format"
+			}
+		}
+		return first, ""
+
+	case models.FieldEmail:
+		if email := helpers.NormalizeEmail(first); email != "" {
+			return email, ""
+		}
+		return nil, field.Label + " is not a valid email address"
+
+	case models.FieldPhone:
+		if phone := helpers.NormalizePHMobile(first); phone != "" {
+			return phone, ""
+		}
+		return nil, field.Label + " is not a valid mobile number"
+
+	case models.FieldNumber:
+		n, err := strconv.ParseFloat(first, 64)
+		if err != nil {
+			return nil, field.Label + " must be a number"
+		}
+		if (field.Min != nil && n < *field.Min) || (field.Max != nil && n > *field.Max) {
+			return nil, field.Label + " is out of range"
+		}
+		return n, ""
+
+	case models.FieldDate:
+		day, err := time.ParseInLocation("2006-01-02", first, utils.LocationAsiaManila)
+		if err != nil {
+			return nil, field.Label + " must be a date (YYYY-MM-DD)"
+		}
+		return day.Format("2006-01-02"), ""
+
+	case models.FieldSelect:
+		for _, option := range field.Options {
+			if option == first {
+				return first, ""
+			}
+		}
+		return nil, "Invalid choice for " + field.Label
+
+	case models.FieldMultiselect:
+		chosen := []string{}
+		for _, value := range raw {
+			value = strings.TrimSpace(value)
+			valid := false
+			for _, option := range field.Options {
+				valid = valid || option == value
+			}
+			if !valid {
+				return nil, "Invalid choice for " + field.Label
+			}
+			chosen = append(chosen, value)
+		}
+		if field.Required && len(chosen) == 0 {
+			return nil, field.Label + " is required"
+		}
+		return chosen, ""
+
+	case models.FieldCheckbox:
+		checked := first == "true" || first == "on" || first == "1" || first == "yes"
+		if field.Required && !checked {
+			return nil, field.Label + " must be checked"
+		}
+		return checked, ""
+	}
+	return nil, "Unsupported field " + field.Label
+}
+
+// storeFormFile checks an upload against the field's accepted kinds and stores it
+func storeFormFile(form models.Form, field models.FormField, header *multipart.FileHeader) (string, string) {
+	data, err := readUpload(header, int64(field.Max_size_mb)<<20)
+	if err != nil {
+		return "", field.Label + ": " + err.Error()
+	}
+
+	ext := ""
+	for _, kind := range field.Accept {
+		switch kind {
+		case models.FileImage:
+			limits := media.DefaultImageLimits
+			limits.MaxBytes = int64(field.Max_size_mb) << 20
+			if img, err := media.Ingest(data, limits); err == nil {
+				data, ext = img.Data, img.Ext() // metadata stripped
+			}
+		case models.FileAudio:
+			if helpers.IsMP3(data) {
+				ext = ".mp3"
+			}
+		case models.FileVideo:
+			if _, err := helpers.MP4Duration(data); err == nil {
+				ext = ".mp4"
+			}
+		case models.FilePDF:
+			if bytes.HasPrefix(data, []byte("%PDF-")) {
+				ext = ".pdf"
+			}
+		}
+		if ext != "" {
+			break
+		}
+	}
+	if ext == "" {
+		return "", field.Label + " must be one of: " + strings.Join(field.Accept, ", ")
+	}
+
+	// submitted files are kept in the forms area; only staff can fetch them
+	name, err := randomFileName(form.Slug+"-"+field.Key, ext)
+	if err == nil {
+		err = storeUploadedFile(storage.Forms, name, data)
+	}
+	if err != nil {
+		log.Println("Store form file error:", err)
+		return "", "Failed to store " + field.Label
+	}
+	return name, ""
+}
+
+// formValueText renders a stored value for emails and CSV
+func formValueText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ", ")
+	case primitive.A:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ", ")
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// fillFormTemplate replaces {{key}} placeholders; escape is applied to the values
+func fillFormTemplate(template string, values map[string]string, escape func(string) string) string {
+	return formPlaceholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		key := formPlaceholderPattern.FindStringSubmatch(match)[1]
+		return escape(values[key])
+	})
+}
+
+// queueFormMail puts the staff notification and the auto-reply in the mail outbox
+func queueFormMail(ctx context.Context, form models.Form, submission models.FormSubmission) {
+	// the definition's texts are stored escaped; the values are raw
+	title := html.UnescapeString(form.Title)
+	values := map[string]string{"form_title": title}
+	for key, value := range submission.Values {
+		values[key] = formValueText(value)
+	}
+	plain := func(s string) string { return strings.NewReplacer("\r", " ", "\n", " ").Replace(s) }
+
+	var messages []mailer.Message
+	if len(form.Notify) > 0 {
+		fields := make([]interface{}, 0, len(form.Fields))
+		for _, field := range form.Fields {
+			value := values[field.Key]
+			if field.Type == models.FieldFile {
+				value = submission.Files[field.Key]
+			}
+			fields = append(fields, map[string]interface{}{"Label": html.UnescapeString(field.Label), "Value": value})
+		}
+		rendered, err := mailer.Render(ctx, "form-staff-notification", map[string]interface{}{"Form": title, "Fields": fields})
+		if err != nil {
+			log.Println("[FORMS] staff email not rendered:", err)
+		} else {
+			msg := rendered.Message()
+			msg.To, msg.From_name, msg.Tag = form.Notify, "Magic 899 Forms", "forms."+form.Slug+".staff"
+			messages = append(messages, msg)
+		}
+	}
+	if reply := form.Auto_reply; reply != nil && values[reply.Email_field] != "" {
+		messages = append(messages, mailer.Message{
+			To: []string{values[reply.Email_field]}, From_name: "Magic 899",
+			Subject: fillFormTemplate(html.UnescapeString(reply.Subject), values, plain),
+			HTML:    "<html><body>" + fillFormTemplate(reply.Body, values, html.EscapeString) + "</body></html>",
+			Tag:     "forms." + form.Slug + ".auto-reply",
+		})
+	}
+
+	for _, msg := range messages {
+		if _, err := mailer.Enqueue(ctx, msg); err != nil {
+			log.Println("[FORMS] email not queued:", err)
+		}
+	}
+}
+
+// GetForm - Public form definition
+func GetForm(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	form, err := findForm(ctx, c.Params("slug"), false)
+	if err != nil {
+		return formError(c, err)
+	}
+	form.Notify, form.Auto_reply = nil, nil
+
+	return jsonResponse(c, http.StatusOK, "Form fetched successfully", fiber.Map{
+		"form": form,
+		"open": formOpen(form, time.Now()),
+	})
+}
+
+// SubmitForm - Validate a submission against the form definition and store it
+func SubmitForm(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	form, err := findForm(ctx, c.Params("slug"), false)
+	if err != nil {
+		return formError(c, err)
+	}
+	now := time.Now()
+	if !formOpen(form, now) {
+		return errorResponse(c, http.StatusConflict, "This form is not accepting submissions")
+	}
+
+	raw, uploads, err := bindFormValues(c)
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	submission := models.FormSubmission{
+		ID:         primitive.NewObjectID(),
+		Form_id:    form.ID,
+		Form_slug:  form.Slug,
+		Values:     map[string]interface{}{},
+		Files:      map[string]string{},
+		IP_address: c.IP(),
+		Created_at: primitive.NewDateTimeFromTime(now),
+	}
+	for _, field := range form.Fields {
+		if field.Type == models.FieldFile {
+			continue
+		}
+		value, problem := checkFormValue(field, raw[field.Key])
+		if problem != "" {
+			return errorResponse(c, http.StatusBadRequest, problem)
+		}
+		if value != nil {
+			submission.Values[field.Key] = value
+		}
+	}
+	if form.Unique_field != "" {
+		if value := formValueText(submission.Values[form.Unique_field]); value != "" {
+			submission.Unique_value = strings.ToLower(value)
+		}
+	}
+
+	// files last, so a rejected submission leaves nothing behind
+	removeFiles := func() {
+		for _, name := range submission.Files {
+			removeStoredFile(storage.Forms, name)
+		}
+	}
+	for _, field := range form.Fields {
+		if field.Type != models.FieldFile {
+			continue
+		}
+		header := uploads[field.Key]
+		if header == nil {
+			if field.Required {
+				removeFiles()
+				return errorResponse(c, http.StatusBadRequest, field.Label+" is required")
+			}
+			continue
+		}
+		name, problem := storeFormFile(form, field, header)
+		if problem != "" {
+			removeFiles()
+			return errorResponse(c, http.StatusBadRequest, problem)
+		}
+		submission.Files[field.Key] = name
+	}
+
+	if _, err := FormSubmissionCollectionInit().InsertOne(ctx, submission); err != nil {
+		removeFiles()
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "You have already submitted this form")
+		}
+		log.Println("Insert submission error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to submit form")
+	}
+	if _, err := FormCollectionInit().UpdateOne(ctx, bson.M{"_id": form.ID}, bson.M{"$inc": bson.M{"submission_count": 1}}); err != nil {
+		log.Println("Submission count error:", err)
+	}
+
+	queueFormMail(ctx, form, submission)
+
+	message := form.Success_message
+	if message == "" {
+		message = "Thank you! Your submission has been received."
+	}
+	return jsonResponse(c, http.StatusCreated, message, fiber.Map{"id": submission.ID})
+}
+
+// GetForms - Staff list of every form definition
+func GetForms(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := FormCollectionInit().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}))
+	if err != nil {
+		log.Println("Find forms error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch forms")
+	}
+	defer cursor.Close(ctx)
+
+	forms := []models.Form{}
+	if err := cursor.All(ctx, &forms); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse forms")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Forms fetched successfully", fiber.Map{"forms": forms})
+}
+
+// CreateForm - Staff define a new form
+func CreateForm(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var form models.Form
+	if err := c.Bind().JSON(&form); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if problem := normalizeForm(&form); problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	_, author := revisionAuthor(c)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	form.ID = primitive.NewObjectID()
+	form.Submission_count = 0
+	form.Created_by, form.Created_at, form.Updated_at = author, now, now
+
+	if _, err := FormCollectionInit().InsertOne(ctx, form); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "A form with this slug already exists")
+		}
+		log.Println("Insert form error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create form")
+	}
+
+	return jsonResponse(c, http.StatusCreated, "Form created successfully", fiber.Map{"form": form})
+}
+
+// UpdateForm - Staff replace a form definition; past submissions keep their values
+func UpdateForm(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := findForm(ctx, c.Params("slug"), true)
+	if err != nil {
+		return formError(c, err)
+	}
+
+	var form models.Form
+	if err := c.Bind().JSON(&form); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if form.Slug == "" {
+		form.Slug = current.Slug
+	}
+	if problem := normalizeForm(&form); problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	form.ID = current.ID
+	form.Submission_count = current.Submission_count
+	form.Created_by, form.Created_at = current.Created_by, current.Created_at
+	form.Updated_at = primitive.NewDateTimeFromTime(time.Now())
+
+	update := bson.M{
+		"slug": form.Slug, "title": form.Title, "description": form.Description, "fields": form.Fields,
+		"unique_field": form.Unique_field, "notify": form.Notify, "auto_reply": form.Auto_reply,
+		"success_message": form.Success_message, "published": form.Published,
+		"opens_at": form.Opens_at, "closes_at": form.Closes_at, "updated_at": form.Updated_at,
+	}
+	if _, err := FormCollectionInit().UpdateOne(ctx, bson.M{"_id": current.ID}, bson.M{"$set": update}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "A form with this slug already exists")
+		}
+		log.Println("Update form error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update form")
+	}
+	if form.Slug != current.Slug {
+		if _, err := FormSubmissionCollectionInit().UpdateMany(ctx, bson.M{"form_id": current.ID}, bson.M{"$set": bson.M{"form_slug": form.Slug}}); err != nil {
+			log.Println("Rename submissions error:", err)
+		}
+	}
+
+	return jsonResponse(c, http.StatusOK, "Form updated successfully", fiber.Map{"form": form})
+}
+
+// formSubmissionFilter reads ?from=&to= (YYYY-MM-DD, inclusive) for a form's submissions
+func formSubmissionFilter(c fiber.Ctx, form models.Form) (bson.M, bool) {
+	filter := bson.M{"form_id": form.ID}
+	from, okFrom := parseDayParam(c, "from", time.Time{})
+	to, okTo := parseDayParam(c, "to", time.Time{})
+	if !okFrom || !okTo {
+		return nil, false
+	}
+	dates := bson.M{}
+	if !from.IsZero() {
+		dates["$gte"] = primitive.NewDateTimeFromTime(from)
+	}
+	if !to.IsZero() {
+		dates["$lt"] = primitive.NewDateTimeFromTime(to.AddDate(0, 0, 1))
+	}
+	if len(dates) > 0 {
+		filter["created_at"] = dates
+	}
+	return filter, true
+}
+
+// GetFormSubmissions - Staff browse a form's submissions, newest first
+func GetFormSubmissions(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	form, err := findForm(ctx, c.Params("slug"), true)
+	if err != nil {
+		return formError(c, err)
+	}
+	filter, ok := formSubmissionFilter(c, form)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "from and to must be YYYY-MM-DD")
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(formDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = formDefaultLimit
+	}
+	limit = min(limit, formMaxLimit)
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	collection := FormSubmissionCollectionInit()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count submissions error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch submissions")
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		log.Println("Find submissions error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch submissions")
+	}
+	defer cursor.Close(ctx)
+
+	submissions := []models.FormSubmission{}
+	if err := cursor.All(ctx, &submissions); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse submissions")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Submissions fetched successfully", fiber.Map{
+		"form":        form,
+		"submissions": submissions,
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+	})
+}
+
+// csvCell keeps spreadsheet apps from running submitted values as formulas
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ExportFormSubmissions - Staff download every matching submission as CSV
+func ExportFormSubmissions(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	form, err := findForm(ctx, c.Params("slug"), true)
+	if err != nil {
+		return formError(c, err)
+	}
+	filter, ok := formSubmissionFilter(c, form)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "from and to must be YYYY-MM-DD")
+	}
+
+	cursor, err := FormSubmissionCollectionInit().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		log.Println("Find submissions error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to export submissions")
+	}
+	defer cursor.Close(ctx)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := []string{"Submitted At", "ID"}
+	for _, field := range form.Fields {
+		header = append(header, csvCell(html.UnescapeString(field.Label)))
+	}
+	writer.Write(header)
+
+	for cursor.Next(ctx) {
+		var submission models.FormSubmission
+		if err := cursor.Decode(&submission); err != nil {
+			log.Println("Cursor decode error:", err)
+			return errorResponse(c, http.StatusInternalServerError, "Failed to export submissions")
+		}
+		row := []string{
+			submission.Created_at.Time().In(utils.LocationAsiaManila).Format("2006-01-02 15:04:05"),
+			submission.ID.Hex(),
+		}
+		for _, field := range form.Fields {
+			value := formValueText(submission.Values[field.Key])
+			if field.Type == models.FieldFile {
+				value = submission.Files[field.Key]
+			}
+			row = append(row, csvCell(value))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	if err := cursor.Err(); err != nil {
+		log.Println("Cursor error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to export submissions")
+	}
+
+	filename := form.Slug + "-submissions-" + time.Now().In(utils.LocationAsiaManila).Format("20060102") + ".csv"
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Send(buf.Bytes())
+}
+
+// GetFormFile - Staff download a file submitted through a form
+func GetFormFile(c fiber.Ctx) error {
+	key, err := storage.Key(storage.Forms, c.Params("file"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid file name")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	obj, err := storage.Default().Stat(ctx, key)
+	if err != nil {
+		return errorResponse(c, http.StatusNotFound, "File not found")
+	}
+	return sendStoredObject(c, obj, "private, max-age=3600")
+}
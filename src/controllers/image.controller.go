@@ -1,17 +1,28 @@
 package controllers
 
 import (
-	"os"
-	"path/filepath"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/imaging"
+	"magic-server-2026/src/media"
+	"magic-server-2026/src/storage"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
-// UploadImageHandler handles image uploads
+// UploadImageHandler ingests an image into the media store under its content-hash name
 func UploadImageHandler(c fiber.Ctx) error {
-	// Extract tokens
-
-	// Get file from request
 	file, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -20,35 +31,295 @@ func UploadImageHandler(c fiber.Ctx) error {
 		})
 	}
 
-	// Save file
-	err = c.SaveFile(file, "./src/uploads/images/"+file.Filename)
+	img, err := ingestUpload(file, media.DefaultImageLimits)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, media.ErrTooLarge):
+			status = fiber.StatusRequestEntityTooLarge
+		case errors.Is(err, media.ErrUnsupportedType):
+			status = fiber.StatusUnsupportedMediaType
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"status":  "fail",
 			"message": err.Error(),
 		})
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	name := img.Name()
+	obj, err := storeImage(ctx, name, img)
+	if err != nil {
+		log.Println("Store image error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "fail",
+			"message": "Failed to store image",
+		})
+	}
+	uploader, _ := c.Locals("username").(string)
+	recordUploadedMedia(ctx, img, obj, uploader)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":    "success",
 		"message":   "File uploaded successfully",
-		"file_name": file.Filename,
+		"file_name": name,
+		"url":       helpers.PublicImageURL(name),
+		"srcset":    imageSrcset(name, img.Type, img.Width),
+		"type":      img.Type,
+		"width":     img.Width,
+		"height":    img.Height,
+		"bytes":     len(img.Data),
 	})
 }
 
-// GetImageHandler serves an image from the uploads directory
+// GetImageHandler serves an image from the media store
 func GetImageHandler(c fiber.Ctx) error {
+	return sendMediaImage(c, "")
+}
 
-	// Get filename
+// GetPublicImageHandler serves media store images outside /api for clients that
+// cannot send Origin/Referer or RSP headers (feed readers, social crawlers)
+func GetPublicImageHandler(c fiber.Ctx) error {
+	return sendMediaImage(c, imageCacheControl)
+}
+
+// imageSrcset lists the resized variants of a stored image of the given type ("" for AVIF or unknown widths)
+func imageSrcset(name, kind string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	// WebP variants are lossless, which only pays off for graphics (PNG);
+	// WebP uploads are almost always photos, so their variants are JPEG
+	switch kind {
+	case media.TypeJPEG, media.TypeWebP:
+		return helpers.PublicImageSrcset(name, width, imaging.FormatJPEG)
+	case media.TypePNG:
+		return helpers.PublicImageSrcset(name, width, imaging.FormatWebP)
+	}
+	return ""
+}
+
+// sendMediaImage serves :filename from the media store, or a resized variant of it
+func sendMediaImage(c fiber.Ctx, cacheControl string) error {
 	filename := c.Params("filename")
-	filePath := filepath.Join("./src/uploads/images", filename)
+	key, err := storage.Key(storage.Images, filename)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "fail",
+			"message": "Invalid file name",
+		})
+	}
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	obj, err := storage.Default().Stat(ctx, key)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Println("Stat image error:", err)
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "fail",
 			"message": "File not found",
 		})
 	}
 
-	return c.SendFile(filePath)
+	variant, requested, problem := parseImageVariant(c, filename)
+	if problem != "" {
+		return errorResponse(c, fiber.StatusBadRequest, problem)
+	}
+	if requested {
+		return sendImageVariant(c, obj, variant)
+	}
+	return sendStoredObject(c, obj, cacheControl)
+}
+
+// sendStoredObject sends a stored file: as a redirect when presigned downloads are on,
+// straight from disk on the local driver, otherwise proxied from the bucket.
+// Single byte ranges are honoured so videos can seek.
+func sendStoredObject(c fiber.Ctx, obj storage.Object, cacheControl string) error {
+	if cacheControl != "" {
+		// cacheable, unlike the API responses CORSMiddleware marks as no-store
+		c.Response().Header.Del("Pragma")
+		c.Response().Header.Del("Expires")
+		c.Set(fiber.HeaderCacheControl, cacheControl)
+	}
+
+	presigned, err := storage.PresignedURL(context.Background(), obj.Key)
+	if err != nil {
+		log.Println("Presign error:", err)
+		return errorResponse(c, fiber.StatusInternalServerError, "Failed to open file")
+	}
+	if presigned != "" {
+		// the URL expires, so the redirect itself must not be cached for long
+		c.Set(fiber.HeaderCacheControl, "private, max-age=60")
+		return c.Redirect().Status(fiber.StatusFound).To(presigned)
+	}
+
+	if local, ok := storage.Default().(*storage.LocalDriver); ok {
+		path, err := local.Path(obj.Key)
+		if err != nil {
+			return errorResponse(c, fiber.StatusBadRequest, "Invalid file name")
+		}
+		return c.SendFile(path)
+	}
+
+	offset, length, status := int64(0), obj.Size, fiber.StatusOK
+	if header := c.Get(fiber.HeaderRange); header != "" {
+		start, end, ok := parseByteRange(header, obj.Size)
+		if !ok {
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", obj.Size))
+			return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+		}
+		offset, length, status = start, end-start+1, fiber.StatusPartialContent
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size))
+	}
+
+	// the body is streamed after the handler returns, so no deadline here
+	body, _, err := storage.Default().GetRange(context.Background(), obj.Key, offset, length)
+	if err != nil {
+		log.Println("Open stored file error:", err)
+		return errorResponse(c, fiber.StatusBadGateway, "Failed to open file")
+	}
+	c.Set(fiber.HeaderContentType, obj.ContentType)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if !obj.ModTime.IsZero() {
+		c.Set(fiber.HeaderLastModified, obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if obj.ETag != "" {
+		c.Set(fiber.HeaderETag, `"`+obj.ETag+`"`)
+	}
+	return c.Status(status).SendStream(body, int(length))
+}
+
+// parseByteRange reads a single "bytes=start-end" range (end inclusive)
+func parseByteRange(header string, size int64) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") || size <= 0 {
+		return 0, 0, false
+	}
+	from, to, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+	if from == "" {
+		// suffix range: the last n bytes
+		n, err := strconv.ParseInt(to, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		return max(size-n, 0), size - 1, true
+	}
+	start, err := strconv.ParseInt(from, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end = size - 1
+	if to != "" {
+		if end, err = strconv.ParseInt(to, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end, true
+}
+
+// readUpload reads an uploaded file, refusing anything over maxBytes
+func readUpload(header *multipart.FileHeader, maxBytes int64) ([]byte, error) {
+	tooLarge := fmt.Errorf("file is larger than %d MB", maxBytes>>20)
+	if header.Size > maxBytes {
+		return nil, tooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, tooLarge
+	}
+	return data, nil
+}
+
+// randomFileName returns prefix-<random hex><ext>
+func randomFileName(prefix, ext string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return prefix + "-" + hex.EncodeToString(suffix) + ext, nil
+}
+
+// ingestUpload reads an uploaded image and runs it through the media pipeline
+func ingestUpload(header *multipart.FileHeader, limits media.Limits) (media.Image, error) {
+	if header.Size > limits.MaxBytes {
+		return media.Image{}, fmt.Errorf("%w (max %d MB)", media.ErrTooLarge, limits.MaxBytes>>20)
+	}
+	data, err := readUpload(header, limits.MaxBytes)
+	if err != nil {
+		return media.Image{}, err
+	}
+	return media.Ingest(data, limits)
+}
+
+// storeImage puts an ingested image in the media store unless it is already there
+// (names are content hashes, so an existing object has the same bytes)
+func storeImage(ctx context.Context, name string, img media.Image) (storage.Object, error) {
+	key, err := storage.Key(storage.Images, name)
+	if err != nil {
+		return storage.Object{}, err
+	}
+	if obj, err := storage.Default().Stat(ctx, key); err == nil {
+		return obj, nil
+	}
+	if err := storage.PutBytes(ctx, key, img.Data, img.Type); err != nil {
+		return storage.Object{}, err
+	}
+	return storage.Default().Stat(ctx, key)
+}
+
+// saveUploadedImage ingests an upload into the media store as <prefix>-<content hash>;
+// the prefix keeps files owned by one record apart from identical uploads elsewhere
+func saveUploadedImage(header *multipart.FileHeader, prefix string, maxBytes int64) (string, error) {
+	limits := media.DefaultImageLimits
+	limits.MaxBytes = maxBytes
+	img, err := ingestUpload(header, limits)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	name := prefix + "-" + img.Name()
+	_, err = storeImage(ctx, name, img)
+	return name, err
+}
+
+// storeUploadedFile puts an accepted upload under area/name
+func storeUploadedFile(area, name string, data []byte) error {
+	key, err := storage.Key(area, name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return storage.PutBytes(ctx, key, data, storage.ContentType(name))
+}
+
+// removeStoredFile deletes an uploaded file, logging (not failing) when it cannot
+func removeStoredFile(area, name string) {
+	key, err := storage.Key(area, name)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := storage.Default().Delete(ctx, key); err != nil {
+		log.Printf("[STORAGE] %s not removed: %v", key, err)
+	}
 }
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/imaging"
+	"magic-server-2026/src/media"
+	"magic-server-2026/src/storage"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/*
+   Image variants
+   -----------------------------------
+   GET /media/images/:filename?w=480&fmt=jpeg&q=75   (and /api/view/:filename)
+   w    one of helpers.ImageVariantWidths; an image is never upscaled
+   fmt  jpeg | png | webp, default png for PNG originals and jpeg for
+        everything else. WebP variants are lossless (we have no lossy
+        encoder), which only pays off for graphics, so WebP originals
+        (photos, in practice) default to jpeg too
+   q    quality of lossy formats (jpeg), one of imageVariantQualities
+        (default 80)
+   Without any of them the original is served. Variants are rendered
+   once, kept in ImageCacheDir under a key of the source file and the
+   parameters, and sent with a strong ETag. AVIF originals cannot be
+   decoded here and are always sent as they are. The cache is local to
+   each instance even when the originals live in a bucket.
+   -----------------------------------
+*/
+
+const (
+	ImageCacheDir              = "./src/uploads/image-cache"
+	imageVariantDefaultQuality = 80
+	imageVariantMaxSourceBytes = 32 << 20
+	imageCacheControl          = "public, max-age=86400"
+)
+
+var imageVariantQualities = []int{50, 60, 70, 75, 80, 85, 90}
+
+var (
+	// imageVariantLocks keeps concurrent requests for the same variant from rendering it twice
+	imageVariantLocks sync.Map
+	// imageVariantSlots bounds how many images are resized at once
+	imageVariantSlots = make(chan struct{}, max(1, runtime.NumCPU()))
+)
+
+type imageVariant struct {
+	width   int
+	format  string
+	quality int
+}
+
+// parseImageVariant reads ?w, ?fmt and ?q; requested is false when none was given
+func parseImageVariant(c fiber.Ctx, filename string) (variant imageVariant, requested bool, problem string) {
+	w, format, q := c.Query("w"), strings.ToLower(c.Query("fmt")), c.Query("q")
+	if w == "" && format == "" && q == "" {
+		return variant, false, ""
+	}
+
+	if w != "" {
+		width, err := strconv.Atoi(w)
+		if err != nil || !slices.Contains(helpers.ImageVariantWidths, width) {
+			return variant, true, "w must be one of " + joinInts(helpers.ImageVariantWidths)
+		}
+		variant.width = width
+	}
+
+	switch format {
+	case "":
+		format = imaging.FormatJPEG
+		if strings.ToLower(filepath.Ext(filename)) == ".png" {
+			format = imaging.FormatPNG
+		}
+	case "jpg":
+		format = imaging.FormatJPEG
+	case imaging.FormatJPEG, imaging.FormatPNG, imaging.FormatWebP:
+	default:
+		return variant, true, "fmt must be jpeg, png or webp"
+	}
+	variant.format = format
+
+	if format == imaging.FormatJPEG {
+		variant.quality = imageVariantDefaultQuality
+		if q != "" {
+			quality, err := strconv.Atoi(q)
+			if err != nil || !slices.Contains(imageVariantQualities, quality) {
+				return variant, true, "q must be one of " + joinInts(imageVariantQualities)
+			}
+			variant.quality = quality
+		}
+	}
+	return variant, true, ""
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// imageVariantPath is where the variant of a source file version is cached
+func imageVariantPath(source storage.Object, variant imageVariant) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%d\x00%s\x00%d\x00%s\x00%d", source.Name(), source.Size, source.ModTime.UnixNano(), source.ETag, variant.width, variant.format, variant.quality)
+	ext := variant.format
+	if ext == imaging.FormatJPEG {
+		ext = "jpg"
+	}
+	return filepath.Join(ImageCacheDir, hex.EncodeToString(h.Sum(nil))[:32]+"."+ext)
+}
+
+var errNotResizable = errors.New("image cannot be resized")
+
+// renderImageVariant decodes, turns upright, resizes and encodes the source, then caches the result
+func renderImageVariant(source storage.Object, path string, variant imageVariant) ([]byte, error) {
+	if source.Size > imageVariantMaxSourceBytes {
+		return nil, errNotResizable
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	data, _, err := storage.ReadAll(ctx, source.Key, imageVariantMaxSourceBytes)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if media.Sniff(data) == media.TypeAVIF {
+		return nil, errNotResizable
+	}
+
+	imageVariantSlots <- struct{}{}
+	defer func() { <-imageVariantSlots }()
+
+	img, err := imaging.DecodeImage(data)
+	if err != nil {
+		return nil, errNotResizable
+	}
+	img = imaging.Resize(imaging.Orient(img, media.JPEGOrientation(data)), variant.width)
+
+	var buf bytes.Buffer
+	if variant.format == imaging.FormatJPEG {
+		err = imaging.EncodeJPEG(&buf, img, variant.quality)
+	} else {
+		err = imaging.Encode(&buf, img, variant.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := media.Store(ImageCacheDir, filepath.Base(path), buf.Bytes()); err != nil {
+		log.Println("[IMAGES] variant not cached:", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sendImageVariant serves the requested variant of a media store file
+func sendImageVariant(c fiber.Ctx, source storage.Object, variant imageVariant) error {
+	path := imageVariantPath(source, variant)
+	body, err := os.ReadFile(path)
+	if err != nil {
+		lock, _ := imageVariantLocks.LoadOrStore(path, &sync.Mutex{})
+		mu := lock.(*sync.Mutex)
+		mu.Lock()
+		// another request may have rendered it while we waited
+		if body, err = os.ReadFile(path); err != nil {
+			body, err = renderImageVariant(source, path, variant)
+		}
+		mu.Unlock()
+		imageVariantLocks.Delete(path)
+	}
+	if errors.Is(err, errNotResizable) {
+		// better the original than a broken image in a srcset
+		return sendStoredObject(c, source, imageCacheControl)
+	}
+	if err != nil {
+		log.Println("Image variant error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "fail",
+			"message": "Failed to resize image",
+		})
+	}
+
+	return sendCacheable(c, body, imaging.ContentType(variant.format), source.ModTime.UTC().Truncate(1e9), imageCacheControl)
+}
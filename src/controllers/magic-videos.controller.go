@@ -5,6 +5,7 @@ import (
 	"log"
 	"magic-server-2026/src/db"
 	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
 	"net/http"
 	"time"
 
@@ -106,6 +107,7 @@ func CreateMagicVideo(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create magic video"})
 	}
+	search.Refresh()
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Magic Video created successfully",
@@ -148,6 +150,7 @@ func UpdateMagicVideo(c fiber.Ctx) error {
 	if updateResult.MatchedCount == 0 {
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Magic Video not found"})
 	}
+	search.Refresh()
 
 	// Retrieve the updated document
 	var updatedMagicVideo models.MagicVideos
@@ -182,6 +185,7 @@ func DeleteMagicVideo(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete magic video"})
 	}
+	search.Refresh()
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Magic Video deleted successfully"})
 }
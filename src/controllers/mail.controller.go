@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"magic-server-2026/src/mailer"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Mail Outbox (admin only)
+   -----------------------------------
+   1. Browse the outbox       GET  /mail-outbox?status=queued|sending|sent|dead&tag=
+   2. Requeue a message       POST /mail-outbox/:id/retry
+   -----------------------------------
+   PATH: /api/v1/mail-outbox
+*/
+
+const (
+	outboxDefaultLimit = 50
+	outboxMaxLimit     = 200
+)
+
+// GetMailOutbox - Newest messages first, with their delivery state and last error
+func GetMailOutbox(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if tag := c.Query("tag"); tag != "" {
+		filter["tag"] = tag
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(outboxDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = outboxDefaultLimit
+	}
+	limit = min(limit, outboxMaxLimit)
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	collection := mailer.Collection()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count outbox error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch outbox")
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"html": 0, "text": 0}))
+	if err != nil {
+		log.Println("Find outbox error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch outbox")
+	}
+	defer cursor.Close(ctx)
+
+	messages := []mailer.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse outbox")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Outbox fetched successfully", fiber.Map{
+		"messages": messages,
+		"page":     page,
+		"limit":    limit,
+		"total":    total,
+	})
+}
+
+// RetryMail - Put a dead or stuck message back in the queue with fresh attempts
+func RetryMail(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Message ID")
+	}
+	found, err := mailer.Requeue(ctx, objID)
+	if err != nil {
+		log.Println("Requeue mail error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to requeue message")
+	}
+	if !found {
+		return errorResponse(c, http.StatusNotFound, "Message not found or already sent")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Message queued for sending", fiber.Map{"id": objID})
+}
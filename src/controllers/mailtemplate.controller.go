@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"magic-server-2026/src/mailer"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/*
+   Mail Templates (staff)
+   -----------------------------------
+   1. List templates            GET  /mail-templates
+   2. Versions of a template    GET  /mail-templates/:name
+   3. Save a new version        POST /mail-templates/:name
+   4. Activate a version        POST /mail-templates/:name/activate   (admin; 0 = built-in)
+   5. Preview                   POST /mail-templates/:name/preview[?format=eml]
+      body: { version?, subject?, html?, text?, data? } - an unsaved draft when html is set,
+      otherwise the given (or active) version; data defaults to the template's sample
+   -----------------------------------
+   PATH: /api/v1/mail-templates
+*/
+
+type mailTemplateInput struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+	Note    string `json:"note"`
+}
+
+type mailTemplatePreviewInput struct {
+	Version *int                   `json:"version"`
+	Subject string                 `json:"subject"`
+	HTML    string                 `json:"html"`
+	Text    string                 `json:"text"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// mailTemplateError maps registry errors to a response
+func mailTemplateError(c fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, mailer.ErrUnknownTemplate):
+		return errorResponse(c, http.StatusNotFound, "Template not found")
+	case errors.Is(err, mailer.ErrTemplateVersion):
+		return errorResponse(c, http.StatusNotFound, "Template version not found")
+	case errors.Is(err, mailer.ErrBadTemplate):
+		return errorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	log.Println(action+" error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to "+action)
+}
+
+// GetMailTemplates - Built-in templates with the version currently sent
+func GetMailTemplates(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	templates := []fiber.Map{}
+	for _, def := range mailer.Templates() {
+		active, err := mailer.ActiveTemplate(ctx, def.Name)
+		if err != nil {
+			return mailTemplateError(c, err, "fetch templates")
+		}
+		templates = append(templates, fiber.Map{
+			"name":           def.Name,
+			"description":    def.Description,
+			"active_version": active.Version,
+		})
+	}
+
+	return jsonResponse(c, http.StatusOK, "Templates fetched successfully", fiber.Map{"templates": templates})
+}
+
+// GetMailTemplate - A template's definition, sample data and every version
+func GetMailTemplate(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	def, ok := mailer.LookupTemplate(c.Params("name"))
+	if !ok {
+		return errorResponse(c, http.StatusNotFound, "Template not found")
+	}
+	versions, err := mailer.TemplateVersions(ctx, def.Name)
+	if err != nil {
+		return mailTemplateError(c, err, "fetch template")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Template fetched successfully", fiber.Map{
+		"template": def,
+		"versions": versions,
+	})
+}
+
+// CreateMailTemplateVersion - Save an edited template as a new, inactive version
+func CreateMailTemplateVersion(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input mailTemplateInput
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	_, username := revisionAuthor(c)
+
+	version, err := mailer.SaveTemplateVersion(ctx, mailer.TemplateVersion{
+		Name:       c.Params("name"),
+		Subject:    input.Subject,
+		HTML:       input.HTML,
+		Text:       input.Text,
+		Note:       input.Note,
+		Created_by: username,
+	})
+	if err != nil {
+		return mailTemplateError(c, err, "save template")
+	}
+
+	return jsonResponse(c, http.StatusCreated, "Template version saved; activate it to start sending it", fiber.Map{"version": version})
+}
+
+// ActivateMailTemplateVersion - Choose which version is sent
+func ActivateMailTemplateVersion(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input struct {
+		Version *int `json:"version"`
+	}
+	if err := c.Bind().Body(&input); err != nil || input.Version == nil {
+		return errorResponse(c, http.StatusBadRequest, "version is required")
+	}
+	if err := mailer.ActivateTemplateVersion(ctx, c.Params("name"), *input.Version); err != nil {
+		return mailTemplateError(c, err, "activate template")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Template version activated", fiber.Map{
+		"name":    c.Params("name"),
+		"version": *input.Version,
+	})
+}
+
+// PreviewMailTemplate - Render a version or an unsaved draft with sample data
+func PreviewMailTemplate(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	def, ok := mailer.LookupTemplate(c.Params("name"))
+	if !ok {
+		return errorResponse(c, http.StatusNotFound, "Template not found")
+	}
+	var input mailTemplatePreviewInput
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&input); err != nil {
+			return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	var version mailer.TemplateVersion
+	var err error
+	switch {
+	case input.HTML != "":
+		version = mailer.TemplateVersion{Name: def.Name, Version: -1, Subject: input.Subject, HTML: input.HTML, Text: input.Text}
+		if version.Subject == "" {
+			version.Subject = def.Subject
+		}
+	case input.Version != nil:
+		version, err = mailer.GetTemplateVersion(ctx, def.Name, *input.Version)
+	default:
+		version, err = mailer.ActiveTemplate(ctx, def.Name)
+	}
+	if err != nil {
+		return mailTemplateError(c, err, "preview template")
+	}
+
+	data := input.Data
+	if data == nil {
+		data = def.Sample
+	}
+	rendered, err := version.Render(data)
+	if err != nil {
+		return mailTemplateError(c, err, "preview template")
+	}
+
+	if c.Query("format") == "eml" {
+		raw, err := rendered.MIME()
+		if err != nil {
+			return mailTemplateError(c, err, "preview template")
+		}
+		c.Set(fiber.HeaderContentType, "message/rfc822")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+def.Name+"-v"+strconv.Itoa(version.Version)+`.eml"`)
+		return c.Send(raw)
+	}
+
+	return jsonResponse(c, http.StatusOK, "Template rendered successfully", fiber.Map{"preview": rendered})
+}
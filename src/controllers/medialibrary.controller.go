@@ -0,0 +1,810 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/imaging"
+	"magic-server-2026/src/media"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Media Library Controller
+   -----------------------------------
+   Staff only:
+   1. List assets                       GET    /media?q=&tag=&type=&status=used|orphan|missing
+   2. Get one asset                     GET    /media/:id
+   3. Edit alt / caption / credit / tags PUT   /media/:id
+   4. Delete an unused file             DELETE /media/:id
+   5. Scan the store and content        POST   /media/scan
+   6. Duplicate groups                  GET    /media/duplicates
+   7. Orphans                           GET    /media/orphans
+   -----------------------------------
+   A scan records every file in the media store (size, dimensions,
+   SHA-256 and a perceptual fingerprint) and walks the content
+   collections for references to it, as a bare filename or as a
+   /media/images/ or /api/view/ URL, including URLs inside legacy HTML.
+   Files are only rehashed when their size or mtime changed. The scan
+   runs at startup and every mediaScanInterval; uploads are recorded
+   as they come in.
+   Usages are a snapshot, so deleting re-checks the content first.
+   -----------------------------------
+   PATH: /api/v1/media
+*/
+
+const (
+	mediaScanInterval = 6 * time.Hour
+	// mediaOrphanGrace keeps fresh uploads off the orphan list while the article that uses them is being written
+	mediaOrphanGrace = 24 * time.Hour
+	// mediaDuplicateDistance is how many fingerprint bits two copies of a picture may differ by
+	mediaDuplicateDistance = 3
+	mediaMaxTags           = 20
+	mediaMaxTagLength      = 40
+	mediaMaxTextLength     = 500
+)
+
+// mediaSource is a collection that may reference media store files
+type mediaSource struct {
+	database   string
+	collection string
+	title      string // field shown as the usage title, dotted for nested fields
+}
+
+var mediaSources = []mediaSource{
+	{"magic899_db", "news", "title"},
+	// old versions of an article keep its images in use until restored or dropped
+	{"magic899_db", "news_revisions", "snapshot.title"},
+	{"magic899_db", "shows", "show_name"},
+	{"magic899_db", "movies", "title"},
+	{"magic899_db", "posts", "title"},
+	{"magic899_db", "magic_videos", "title"},
+	{"magic899_db", "contests", "title"},
+	{"magic899_db", "contest_entries", "name"},
+	{"magic899_db", "talent_applicants", "name"},
+	{"magic899", "users", "username"},
+	{"magic899_db", "newsletter_issues", "label"},
+	{"magic899_db", "albums", "title"},
+	{"magic899_db", "events", "title"},
+}
+
+// mediaURLPaths are the routes a media store file can be linked by
+var mediaURLPaths = []string{"/media/images/", "/api/view/"}
+
+var (
+	mediaIndexesOnce sync.Once
+	// mediaScanMu allows one scan at a time
+	mediaScanMu sync.Mutex
+)
+
+func MediaAssetCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "media_assets")
+	mediaIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "file_name", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "hash", Value: 1}}},
+			{Keys: bson.D{{Key: "tags", Value: 1}}},
+			{Keys: bson.D{{Key: "usage_count", Value: 1}, {Key: "created_at", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[MEDIA] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+// InitMediaLibrary scans the media store at startup and then periodically
+func InitMediaLibrary() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		summary, err := scanMediaLibrary(ctx)
+		cancel()
+		if err != nil {
+			log.Println("[MEDIA] scan failed:", err)
+		} else {
+			log.Printf("[MEDIA] scanned %d files: %d orphaned, %d missing", summary.Files, summary.Orphans, summary.Missing)
+		}
+		time.Sleep(mediaScanInterval)
+	}
+}
+
+// mediaFileInfo is what a scan learns about one stored file
+type mediaFileInfo struct {
+	name        string
+	kind        string
+	width       int
+	height      int
+	bytes       int64
+	hash        string
+	fingerprint string
+	modified    primitive.DateTime
+}
+
+// inspectMediaFile hashes and measures a media store file
+func inspectMediaFile(ctx context.Context, obj storage.Object) (mediaFileInfo, error) {
+	data, _, err := storage.ReadAll(ctx, obj.Key, 0)
+	if err != nil {
+		return mediaFileInfo{}, err
+	}
+	sum := sha256.Sum256(data)
+	file := mediaFileInfo{
+		name:     obj.Name(),
+		kind:     media.Sniff(data),
+		bytes:    int64(len(data)),
+		hash:     hex.EncodeToString(sum[:]),
+		modified: primitive.NewDateTimeFromTime(obj.ModTime),
+	}
+	if file.kind == "" {
+		return file, nil
+	}
+	file.width, file.height, _ = media.Dimensions(data)
+	if file.kind != media.TypeAVIF {
+		if img, err := imaging.DecodeImage(data); err == nil {
+			file.fingerprint = fmt.Sprintf("%016x", media.Fingerprint(img))
+		}
+	}
+	return file, nil
+}
+
+// listMediaFiles returns the files in the media store by name
+func listMediaFiles(ctx context.Context) (map[string]storage.Object, error) {
+	objects, err := storage.Default().List(ctx, storage.Images+"/")
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]storage.Object, len(objects))
+	for _, obj := range objects {
+		// the store is flat; anything deeper is not a media file
+		if name := strings.TrimPrefix(obj.Key, storage.Images+"/"); !strings.Contains(name, "/") && !strings.HasPrefix(name, ".") {
+			files[name] = obj
+		}
+	}
+	return files, nil
+}
+
+// mediaRefs returns the media store files a stored string points to
+func mediaRefs(value string, known map[string]bool) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if known[value] {
+		return []string{value}
+	}
+	var refs []string
+	for _, prefix := range mediaURLPaths {
+		rest := value
+		for {
+			i := strings.Index(rest, prefix)
+			if i < 0 {
+				break
+			}
+			rest = rest[i+len(prefix):]
+			end := strings.IndexAny(rest, "\"'?#)<> \t\r\n,")
+			if end < 0 {
+				end = len(rest)
+			}
+			if name, err := url.PathUnescape(rest[:end]); err == nil && known[name] {
+				refs = append(refs, name)
+			} else if name := knownMediaPrefix(rest, known); name != "" {
+				refs = append(refs, name)
+			}
+		}
+	}
+	return refs
+}
+
+// knownMediaPrefix returns the longest known file name a URL path starts
+// with, as written or percent-decoded. Names may hold spaces, commas and
+// parentheses ("untoldmelody - Copy (2).webp"), so they are matched against
+// the text instead of cutting it at a delimiter
+func knownMediaPrefix(rest string, known map[string]bool) string {
+	if end := strings.IndexAny(rest, "\"'?#<>\r\n"); end >= 0 {
+		rest = rest[:end]
+	}
+	// an encoded path has no spaces of its own
+	encoded, _, _ := strings.Cut(rest, " ")
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		decoded = encoded
+	}
+	best := ""
+	for name := range known {
+		if len(name) > len(best) && (strings.HasPrefix(rest, name) || strings.HasPrefix(decoded, name)) {
+			best = name
+		}
+	}
+	return best
+}
+
+// walkMediaRefs visits every string in a document, reporting references with their dotted field path
+func walkMediaRefs(value interface{}, path string, known map[string]bool, found func(name, field string)) {
+	switch v := value.(type) {
+	case string:
+		for _, name := range mediaRefs(v, known) {
+			found(name, path)
+		}
+	case bson.M:
+		for key, child := range v {
+			if path == "" && key == "_id" {
+				continue
+			}
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			walkMediaRefs(child, field, known, found)
+		}
+	case bson.D:
+		for _, e := range v {
+			walkMediaRefs(e.Value, strings.TrimPrefix(path+"."+e.Key, "."), known, found)
+		}
+	case bson.A:
+		for _, child := range v {
+			walkMediaRefs(child, path, known, found)
+		}
+	}
+}
+
+// mediaSourceField looks up a dotted field path in a decoded document
+func mediaSourceField(doc bson.M, path string) interface{} {
+	var value interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case bson.M:
+			value = v[key]
+		case bson.D:
+			value = v.Map()[key]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// findMediaUsages walks the content collections for references to the known files
+func findMediaUsages(ctx context.Context, known map[string]bool) (map[string][]models.MediaUsage, error) {
+	usages := map[string][]models.MediaUsage{}
+	for _, source := range mediaSources {
+		cursor, err := db.GetCollection(source.database, source.collection).Find(ctx, bson.M{})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.collection, err)
+		}
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				cursor.Close(ctx)
+				return nil, fmt.Errorf("%s: %w", source.collection, err)
+			}
+			id, _ := doc["_id"].(primitive.ObjectID)
+			title, _ := mediaSourceField(doc, source.title).(string)
+			seen := map[string]bool{}
+			walkMediaRefs(doc, "", known, func(name, field string) {
+				if key := name + "\x00" + field; !seen[key] {
+					seen[key] = true
+					usages[name] = append(usages[name], models.MediaUsage{
+						Collection: source.collection, Document_id: id, Field: field, Title: title,
+					})
+				}
+			})
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.collection, err)
+		}
+	}
+	for name := range usages {
+		sort.Slice(usages[name], func(i, j int) bool {
+			a, b := usages[name][i], usages[name][j]
+			if a.Collection != b.Collection {
+				return a.Collection < b.Collection
+			}
+			if a.Document_id != b.Document_id {
+				return a.Document_id.Hex() < b.Document_id.Hex()
+			}
+			return a.Field < b.Field
+		})
+	}
+	return usages, nil
+}
+
+// mediaScanSummary is what a scan did
+type mediaScanSummary struct {
+	Files      int     `json:"files"`
+	Hashed     int     `json:"hashed"` // new or changed files read from disk
+	Referenced int     `json:"referenced"`
+	Orphans    int     `json:"orphans"`
+	Missing    int     `json:"missing"`
+	Broken     int     `json:"broken"` // missing files that content still points to
+	Seconds    float64 `json:"seconds"`
+}
+
+var errMediaScanRunning = errors.New("a media scan is already running")
+
+// scanMediaLibrary brings the media_assets collection in line with the disk and the content
+func scanMediaLibrary(ctx context.Context) (mediaScanSummary, error) {
+	if !mediaScanMu.TryLock() {
+		return mediaScanSummary{}, errMediaScanRunning
+	}
+	defer mediaScanMu.Unlock()
+	started := time.Now()
+
+	files, err := listMediaFiles(ctx)
+	if err != nil {
+		return mediaScanSummary{}, err
+	}
+	collection := MediaAssetCollectionInit()
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"usages": 0}))
+	if err != nil {
+		return mediaScanSummary{}, err
+	}
+	var existing []models.MediaAsset
+	if err := cursor.All(ctx, &existing); err != nil {
+		return mediaScanSummary{}, err
+	}
+	records := make(map[string]models.MediaAsset, len(existing))
+	known := make(map[string]bool, len(files)+len(existing))
+	for _, asset := range existing {
+		records[asset.File_name] = asset
+		known[asset.File_name] = true
+	}
+	for name := range files {
+		known[name] = true
+	}
+
+	usages, err := findMediaUsages(ctx, known)
+	if err != nil {
+		return mediaScanSummary{}, err
+	}
+
+	summary := mediaScanSummary{Files: len(files)}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var writes []mongo.WriteModel
+	for name := range known {
+		used := usages[name]
+		if used == nil {
+			used = []models.MediaUsage{}
+		}
+		set := bson.M{"usages": used, "usage_count": len(used), "scanned_at": now}
+
+		obj, onDisk := files[name]
+		if !onDisk {
+			summary.Missing++
+			if len(used) > 0 {
+				summary.Broken++
+			}
+			set["missing"] = true
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"file_name": name}).
+				SetUpdate(bson.M{"$set": set}))
+			continue
+		}
+		if len(used) > 0 {
+			summary.Referenced++
+		} else {
+			summary.Orphans++
+		}
+
+		record, ok := records[name]
+		if !ok || record.Hash == "" || record.Bytes != obj.Size ||
+			record.Modified_at != primitive.NewDateTimeFromTime(obj.ModTime) {
+			file, err := inspectMediaFile(ctx, obj)
+			if err != nil {
+				log.Println("[MEDIA] cannot read", name+":", err)
+				continue
+			}
+			summary.Hashed++
+			set["type"], set["width"], set["height"], set["bytes"] = file.kind, file.width, file.height, file.bytes
+			set["hash"], set["fingerprint"], set["modified_at"] = file.hash, file.fingerprint, file.modified
+			set["updated_at"] = now
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"file_name": name}).
+			SetUpdate(bson.M{
+				"$set":   set,
+				"$unset": bson.M{"missing": ""},
+				"$setOnInsert": bson.M{
+					"_id": primitive.NewObjectID(), "tags": []string{}, "created_at": now,
+				},
+			}).
+			SetUpsert(true))
+	}
+
+	for start := 0; start < len(writes); start += 500 {
+		end := min(start+500, len(writes))
+		if _, err := collection.BulkWrite(ctx, writes[start:end], options.BulkWrite().SetOrdered(false)); err != nil {
+			return summary, err
+		}
+	}
+	summary.Seconds = time.Since(started).Seconds()
+	return summary, nil
+}
+
+// recordUploadedMedia adds a fresh upload to the library without waiting for the next scan
+func recordUploadedMedia(ctx context.Context, img media.Image, obj storage.Object, uploader string) {
+	name := obj.Name()
+	fingerprint := ""
+	if img.Type != media.TypeAVIF {
+		if decoded, err := imaging.DecodeImage(img.Data); err == nil {
+			fingerprint = fmt.Sprintf("%016x", media.Fingerprint(decoded))
+		}
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	_, err := MediaAssetCollectionInit().UpdateOne(ctx, bson.M{"file_name": name}, bson.M{
+		"$set": bson.M{
+			"type": img.Type, "width": img.Width, "height": img.Height, "bytes": obj.Size,
+			"hash": img.Hash, "fingerprint": fingerprint,
+			"modified_at": primitive.NewDateTimeFromTime(obj.ModTime), "updated_at": now,
+		},
+		"$unset": bson.M{"missing": ""},
+		"$setOnInsert": bson.M{
+			"_id": primitive.NewObjectID(), "tags": []string{}, "usages": []models.MediaUsage{}, "usage_count": 0,
+			"scanned_at": now, "created_by": uploader, "created_at": now,
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Println("[MEDIA] upload not recorded:", err)
+	}
+}
+
+// GetMediaAssets - Browse the library
+func GetMediaAssets(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"file_name": pattern}, bson.M{"alt": pattern}, bson.M{"caption": pattern}, bson.M{"credit": pattern},
+		}
+	}
+	if tag := strings.ToLower(strings.TrimSpace(c.Query("tag"))); tag != "" {
+		filter["tags"] = tag
+	}
+	if kind := c.Query("type"); kind != "" {
+		filter["type"] = kind
+	}
+	switch c.Query("status") {
+	case "":
+	case "used":
+		filter["usage_count"] = bson.M{"$gt": 0}
+	case "orphan":
+		filter["usage_count"] = 0
+		filter["missing"] = bson.M{"$ne": true}
+	case "missing":
+		filter["missing"] = true
+	default:
+		return errorResponse(c, http.StatusBadRequest, "status must be used, orphan or missing")
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	limit = min(limit, 200)
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	collection := MediaAssetCollectionInit()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch media")
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "file_name", Value: 1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		log.Println("Find media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch media")
+	}
+	defer cursor.Close(ctx)
+
+	assets := []models.MediaAsset{}
+	if err := cursor.All(ctx, &assets); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse media")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Media fetched successfully", fiber.Map{
+		"media": assets,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+var errMediaNotFound = errors.New("media not found")
+
+// findMediaAsset loads an asset by its hex ID
+func findMediaAsset(ctx context.Context, hexID string) (models.MediaAsset, error) {
+	var asset models.MediaAsset
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return asset, errMediaNotFound
+	}
+	err = MediaAssetCollectionInit().FindOne(ctx, bson.M{"_id": id}).Decode(&asset)
+	if err == mongo.ErrNoDocuments {
+		return asset, errMediaNotFound
+	}
+	return asset, err
+}
+
+func mediaError(c fiber.Ctx, err error) error {
+	if err == errMediaNotFound {
+		return errorResponse(c, http.StatusNotFound, "Media not found")
+	}
+	log.Println("Find media error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch media")
+}
+
+// GetMediaAsset - One asset with its usages and srcset
+func GetMediaAsset(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	asset, err := findMediaAsset(ctx, c.Params("id"))
+	if err != nil {
+		return mediaError(c, err)
+	}
+	return jsonResponse(c, http.StatusOK, "Media fetched successfully", fiber.Map{
+		"media": asset,
+		"url":   helpers.PublicImageURL(asset.File_name),
+	})
+}
+
+// normalizeMediaTags lowercases, trims and de-duplicates tags
+func normalizeMediaTags(tags []string) ([]string, error) {
+	policy := bluemonday.StrictPolicy()
+	clean := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(policy.Sanitize(tag)))
+		if tag == "" || slices.Contains(clean, tag) {
+			continue
+		}
+		if len(tag) > mediaMaxTagLength {
+			return nil, fmt.Errorf("tags cannot be longer than %d characters", mediaMaxTagLength)
+		}
+		clean = append(clean, tag)
+	}
+	if len(clean) > mediaMaxTags {
+		return nil, fmt.Errorf("an image cannot have more than %d tags", mediaMaxTags)
+	}
+	return clean, nil
+}
+
+// UpdateMediaAsset - Edit the alt text, caption, credit and tags
+func UpdateMediaAsset(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	asset, err := findMediaAsset(ctx, c.Params("id"))
+	if err != nil {
+		return mediaError(c, err)
+	}
+	var input models.MediaAssetInput
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	policy := bluemonday.StrictPolicy()
+	set := bson.M{"updated_at": primitive.NewDateTimeFromTime(time.Now())}
+	for field, value := range map[string]*string{"alt": input.Alt, "caption": input.Caption, "credit": input.Credit} {
+		if value == nil {
+			continue
+		}
+		text := strings.TrimSpace(policy.Sanitize(*value))
+		if len(text) > mediaMaxTextLength {
+			return errorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s cannot be longer than %d characters", field, mediaMaxTextLength))
+		}
+		set[field] = text
+	}
+	if input.Tags != nil {
+		tags, err := normalizeMediaTags(input.Tags)
+		if err != nil {
+			return errorResponse(c, http.StatusBadRequest, err.Error())
+		}
+		set["tags"] = tags
+	}
+
+	err = MediaAssetCollectionInit().FindOneAndUpdate(ctx, bson.M{"_id": asset.ID}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&asset)
+	if err != nil {
+		log.Println("Update media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update media")
+	}
+	return jsonResponse(c, http.StatusOK, "Media updated successfully", fiber.Map{"media": asset})
+}
+
+// DeleteMediaAsset - Remove a file nothing references any more
+func DeleteMediaAsset(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	asset, err := findMediaAsset(ctx, c.Params("id"))
+	if err != nil {
+		return mediaError(c, err)
+	}
+
+	// the stored usages may be hours old; check the content as it is now
+	usages, err := findMediaUsages(ctx, map[string]bool{asset.File_name: true})
+	if err != nil {
+		log.Println("Media usage check error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to check where the image is used")
+	}
+	collection := MediaAssetCollectionInit()
+	if used := usages[asset.File_name]; len(used) > 0 {
+		collection.UpdateOne(ctx, bson.M{"_id": asset.ID}, bson.M{"$set": bson.M{"usages": used, "usage_count": len(used)}})
+		return c.Status(http.StatusConflict).JSON(fiber.Map{
+			"error":  "Image is still in use",
+			"usages": used,
+		})
+	}
+
+	key, err := storage.Key(storage.Images, asset.File_name)
+	if err == nil {
+		err = storage.Default().Delete(ctx, key)
+	}
+	if err != nil {
+		log.Println("Delete media file error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to delete image")
+	}
+	if _, err := collection.DeleteOne(ctx, bson.M{"_id": asset.ID}); err != nil {
+		log.Println("Delete media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to delete media record")
+	}
+	_, author := revisionAuthor(c)
+	log.Printf("[MEDIA] %s deleted %s", author, asset.File_name)
+	return jsonResponse(c, http.StatusOK, "Media deleted successfully", fiber.Map{"file_name": asset.File_name})
+}
+
+// ScanMediaLibrary - Rescan the store and the content now
+func ScanMediaLibrary(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	summary, err := scanMediaLibrary(ctx)
+	if errors.Is(err, errMediaScanRunning) {
+		return errorResponse(c, http.StatusConflict, "A scan is already running, try again in a minute")
+	}
+	if err != nil {
+		log.Println("Media scan error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to scan media")
+	}
+	return jsonResponse(c, http.StatusOK, "Media scanned successfully", fiber.Map{"scan": summary})
+}
+
+// GetMediaDuplicates - Byte-identical files, and copies of the same picture in
+// another size or format. In each group the most used file comes first.
+func GetMediaDuplicates(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := MediaAssetCollectionInit().Find(ctx, bson.M{"missing": bson.M{"$ne": true}})
+	if err != nil {
+		log.Println("Find media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch media")
+	}
+	var assets []models.MediaAsset
+	if err := cursor.All(ctx, &assets); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse media")
+	}
+
+	// union-find over exact and perceptual matches
+	parent := make([]int, len(assets))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	fingerprints := make([]uint64, len(assets))
+	hasFingerprint := make([]bool, len(assets))
+	for i, asset := range assets {
+		if fp, err := strconv.ParseUint(asset.Fingerprint, 16, 64); err == nil && asset.Fingerprint != "" {
+			fingerprints[i], hasFingerprint[i] = fp, true
+		}
+	}
+	for i := range assets {
+		for j := i + 1; j < len(assets); j++ {
+			same := assets[i].Hash != "" && assets[i].Hash == assets[j].Hash
+			if !same && hasFingerprint[i] && hasFingerprint[j] {
+				same = media.FingerprintDistance(fingerprints[i], fingerprints[j]) <= mediaDuplicateDistance
+			}
+			if same {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	members := map[int][]models.MediaAsset{}
+	for i, asset := range assets {
+		members[root(i)] = append(members[root(i)], asset)
+	}
+	groups := []models.MediaDuplicateGroup{}
+	for _, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Usage_count != group[j].Usage_count {
+				return group[i].Usage_count > group[j].Usage_count
+			}
+			return group[i].File_name < group[j].File_name
+		})
+		exact := true
+		for _, asset := range group[1:] {
+			exact = exact && asset.Hash == group[0].Hash
+		}
+		groups = append(groups, models.MediaDuplicateGroup{Exact: exact, Assets: group})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Assets[0].File_name < groups[j].Assets[0].File_name })
+
+	return jsonResponse(c, http.StatusOK, "Duplicates fetched successfully", fiber.Map{"groups": groups})
+}
+
+// GetMediaOrphans - Files nothing referenced at the last scan, oldest first
+func GetMediaOrphans(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cutoff := primitive.NewDateTimeFromTime(time.Now().Add(-mediaOrphanGrace))
+	cursor, err := MediaAssetCollectionInit().Find(ctx, bson.M{
+		"usage_count": 0,
+		"missing":     bson.M{"$ne": true},
+		"created_at":  bson.M{"$lt": cutoff},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "file_name", Value: 1}}))
+	if err != nil {
+		log.Println("Find media error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch orphans")
+	}
+	orphans := []models.MediaAsset{}
+	if err := cursor.All(ctx, &orphans); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse orphans")
+	}
+
+	var bytes int64
+	for _, asset := range orphans {
+		bytes += asset.Bytes
+	}
+	return jsonResponse(c, http.StatusOK, "Orphans fetched successfully", fiber.Map{
+		"orphans": orphans,
+		"total":   len(orphans),
+		"bytes":   bytes,
+	})
+}
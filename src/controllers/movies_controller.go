@@ -3,11 +3,17 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"log"
 	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
 	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"math"
 	"net/http"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,6 +49,161 @@ func errorResponse(c fiber.Ctx, status int, message string) error {
 	return c.Status(status).JSON(fiber.Map{"error": message})
 }
 
+// findMovieParam loads the movie named by the :id route param
+func findMovieParam(ctx context.Context, c fiber.Ctx) (models.Movies, error) {
+	var movie models.Movies
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return movie, mongo.ErrNoDocuments
+	}
+	err = MoviesCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&movie)
+	return movie, err
+}
+
+func movieParamError(c fiber.Ctx, err error) error {
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Movie not found")
+	}
+	log.Println("Find movie error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch movie")
+}
+
+// movieRunDays is how long a movie counts as "now showing" after its release
+const movieRunDays = 28
+
+const (
+	movieListDefaultLimit = 20
+	movieListMaxLimit     = 50
+)
+
+func movieListLimit(c fiber.Ctx) int {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(movieListDefaultLimit)))
+	if err != nil || limit < 1 {
+		return movieListDefaultLimit
+	}
+	return min(limit, movieListMaxLimit)
+}
+
+// startOfManilaDay is midnight of now's date in Asia/Manila
+func startOfManilaDay(now time.Time) time.Time {
+	now = now.In(utils.LocationAsiaManila)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, utils.LocationAsiaManila)
+}
+
+// nowShowingFilter matches movies released today or within the last movieRunDays days
+func nowShowingFilter(now time.Time) bson.M {
+	today := startOfManilaDay(now)
+	return bson.M{"release_at": bson.M{
+		"$lte": primitive.NewDateTimeFromTime(today),
+		"$gt":  primitive.NewDateTimeFromTime(today.AddDate(0, 0, -movieRunDays)),
+	}}
+}
+
+// comingSoonFilter matches movies releasing after today
+func comingSoonFilter(now time.Time) bson.M {
+	return bson.M{"release_at": bson.M{"$gt": primitive.NewDateTimeFromTime(startOfManilaDay(now))}}
+}
+
+func findMoviesByDate(ctx context.Context, filter bson.M, field string, order, limit int) ([]models.Movies, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(int64(limit))
+	cursor, err := MoviesCollectionInit().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	movies := []models.Movies{}
+	err = cursor.All(ctx, &movies)
+	return movies, err
+}
+
+// syncMovieDates stores the typed dates parsed from a movie's date strings
+func syncMovieDates(ctx context.Context, movie *models.Movies) error {
+	set, unset := helpers.MovieDateFields(*movie)
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if _, err := MoviesCollectionInit().UpdateOne(ctx, bson.M{"_id": movie.ID}, update); err != nil {
+		return err
+	}
+
+	movie.Release_at, movie.Screening_at = 0, 0
+	if release, ok := set["release_at"].(primitive.DateTime); ok {
+		movie.Release_at = release
+	}
+	if screening, ok := set["screening_at"].(primitive.DateTime); ok {
+		movie.Screening_at = screening
+	}
+	return nil
+}
+
+// BackfillMovieDates types the date strings of movies missing release_at or
+// screening_at (every movie when all is set). unparsed lists the movies whose
+// strings could not be read, so staff can correct them.
+func BackfillMovieDates(ctx context.Context, all bool) (updated int, unparsed []fiber.Map, err error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"release_at": bson.M{"$exists": false}},
+		bson.M{"screening_at": bson.M{"$exists": false}},
+	}}
+	if all {
+		filter = bson.M{}
+	}
+
+	cursor, err := MoviesCollectionInit().Find(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var movies []models.Movies
+	if err := cursor.All(ctx, &movies); err != nil {
+		return 0, nil, err
+	}
+
+	unparsed = []fiber.Map{}
+	for i := range movies {
+		movie := &movies[i]
+		if err := syncMovieDates(ctx, movie); err != nil {
+			return updated, unparsed, err
+		}
+		updated++
+
+		var fields []string
+		if movie.Release_at == 0 {
+			fields = append(fields, "release_date")
+		}
+		if movie.Screening_at == 0 {
+			fields = append(fields, "advanced_screening_date")
+		}
+		if len(fields) > 0 {
+			unparsed = append(unparsed, fiber.Map{"id": movie.ID, "title": movie.Title, "fields": fields})
+		}
+	}
+	return updated, unparsed, nil
+}
+
+// InitMovieDates runs the date backfill once at startup so movies saved before
+// release_at / screening_at existed show up in the date-based listings
+func InitMovieDates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	updated, unparsed, err := BackfillMovieDates(ctx, false)
+	if err != nil {
+		log.Println("[MOVIES] date backfill failed:", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("[MOVIES] typed dates backfilled for %d movies (%d with unreadable dates)", updated, len(unparsed))
+	}
+}
+
 // -------------------------------
 // Handlers
 // -------------------------------
@@ -142,36 +303,73 @@ func GetLatestMovie(c fiber.Ctx) error {
 	return jsonResponse(c, http.StatusOK, "Latest movie fetched successfully", fiber.Map{"movie": movie})
 }
 
-// Get upcoming movies after the latest release
+// Get upcoming movies (next releases after today)
 func GetUpNextMovies(c fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var latestMovie models.Movies
-	opts := options.FindOne().SetSort(bson.D{{Key: "release_date", Value: -1}})
-	moviesCollection := MoviesCollectionInit()
+	upNext, err := findMoviesByDate(ctx, comingSoonFilter(time.Now()), "release_at", 1, 5)
+	if err != nil {
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch upcoming movies")
+	}
 
-	if err := moviesCollection.FindOne(ctx, bson.M{}, opts).Decode(&latestMovie); err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errorResponse(c, http.StatusNotFound, "No movies found")
-		}
-		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch latest movie")
+	return jsonResponse(c, http.StatusOK, "Upcoming movies fetched successfully", fiber.Map{"movies": upNext})
+}
+
+// Get movies in cinemas now (released within the last movieRunDays days)
+func GetNowShowingMovies(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movies, err := findMoviesByDate(ctx, nowShowingFilter(time.Now()), "release_at", -1, movieListLimit(c))
+	if err != nil {
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch now showing movies")
 	}
 
-	filter := bson.M{"release_date": bson.M{"$gt": latestMovie.Release_date}}
-	findOpts := options.Find().SetSort(bson.D{{Key: "release_date", Value: 1}}).SetLimit(5)
-	cursor, err := moviesCollection.Find(ctx, filter, findOpts)
+	return jsonResponse(c, http.StatusOK, "Now showing movies fetched successfully", fiber.Map{"movies": movies})
+}
+
+// Get movies releasing after today, soonest first
+func GetComingSoonMovies(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movies, err := findMoviesByDate(ctx, comingSoonFilter(time.Now()), "release_at", 1, movieListLimit(c))
 	if err != nil {
-		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch upcoming movies")
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch coming soon movies")
 	}
-	defer cursor.Close(ctx)
 
-	var upNext []models.Movies
-	if err := cursor.All(ctx, &upNext); err != nil {
-		return errorResponse(c, http.StatusInternalServerError, "Failed to decode upcoming movies")
+	return jsonResponse(c, http.StatusOK, "Coming soon movies fetched successfully", fiber.Map{"movies": movies})
+}
+
+// Get movies whose advanced screening has already started, latest first
+func GetPastScreeningMovies(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"screening_at": bson.M{"$lte": primitive.NewDateTimeFromTime(time.Now())}}
+	movies, err := findMoviesByDate(ctx, filter, "screening_at", -1, movieListLimit(c))
+	if err != nil {
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch past screenings")
 	}
 
-	return jsonResponse(c, http.StatusOK, "Upcoming movies fetched successfully", fiber.Map{"movies": upNext})
+	return jsonResponse(c, http.StatusOK, "Past screenings fetched successfully", fiber.Map{"movies": movies})
+}
+
+// Fill release_at / screening_at from the date strings of movies that lack them
+func BackfillMovieDatesHandler(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	updated, unparsed, err := BackfillMovieDates(ctx, c.Query("all") == "true")
+	if err != nil {
+		return errorResponse(c, http.StatusInternalServerError, "Failed to backfill movie dates")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Movie dates backfilled", fiber.Map{
+		"updated":  updated,
+		"unparsed": unparsed,
+	})
 }
 
 // Get all previous movies except the latest one
@@ -208,6 +406,23 @@ func CreateMovie(c fiber.Ctx) error {
 		})
 	}
 
+	content, err := helpers.SanitizeContentBlocks(movie.Content)
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid content: "+err.Error())
+	}
+	movie.Content = content
+
+	// computed fields are never taken from the request
+	movie.Release_at, movie.Screening_at = 0, 0
+	if release, ok := helpers.MovieReleaseTime(movie); ok {
+		movie.Release_at = primitive.NewDateTimeFromTime(release)
+	}
+	if screening, ok := helpers.MovieScreeningTime(movie); ok {
+		movie.Screening_at = primitive.NewDateTimeFromTime(screening)
+	}
+	movie.Screening_seats_taken = 0
+	movie.Rating, movie.Review_count, movie.Rating_distribution = 0, 0, nil
+
 	movie.ID = primitive.NewObjectID()
 	movie.Created_at = primitive.NewDateTimeFromTime(time.Now())
 	movie.Updated_at = movie.Created_at
@@ -216,6 +431,7 @@ func CreateMovie(c fiber.Ctx) error {
 	if _, err := moviesCollection.InsertOne(ctx, movie); err != nil {
 		return errorResponse(c, http.StatusInternalServerError, "Failed to create movie")
 	}
+	search.Refresh()
 
 	return jsonResponse(c, http.StatusCreated, "Movie created successfully", fiber.Map{"movie": movie})
 }
@@ -238,6 +454,30 @@ func UpdateMovie(c fiber.Ctx) error {
 	}
 
 	delete(updateData, "created_at")
+	for _, computed := range []string{"release_at", "screening_at", "screening_seats_taken", "rating", "review_count", "rating_distribution"} {
+		delete(updateData, computed)
+	}
+	if err := helpers.SanitizeContentField(updateData, "content"); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid content: "+err.Error())
+	}
+	// seats are set after the update, through the screening code that fills new ones from the waitlist
+	capacity := -1
+	if value, ok := updateData["screening_capacity"]; ok {
+		var seats float64
+		switch v := value.(type) {
+		case float64:
+			seats = v
+		case string: // form bodies
+			seats, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+		default:
+			err = fmt.Errorf("%T", value)
+		}
+		if err != nil || seats < 0 || seats != math.Trunc(seats) {
+			return errorResponse(c, http.StatusBadRequest, "screening_capacity must be a number of seats (0 closes registration)")
+		}
+		capacity = int(seats)
+		delete(updateData, "screening_capacity")
+	}
 	updateData["updated_at"] = primitive.NewDateTimeFromTime(time.Now())
 	moviesCollection := MoviesCollectionInit()
 
@@ -249,13 +489,28 @@ func UpdateMovie(c fiber.Ctx) error {
 	if updateResult.MatchedCount == 0 {
 		return errorResponse(c, http.StatusNotFound, "Movie not found")
 	}
+	var promoted []models.ScreeningRegistration
+	if capacity >= 0 {
+		if promoted, err = setScreeningCapacity(ctx, objID, capacity); err != nil {
+			log.Println("Update capacity error:", err)
+			return errorResponse(c, http.StatusInternalServerError, "Failed to update screening capacity")
+		}
+	}
+	search.Refresh()
 
 	var updatedMovie models.Movies
 	if err := moviesCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&updatedMovie); err != nil {
 		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch updated movie")
 	}
+	if err := syncMovieDates(ctx, &updatedMovie); err != nil {
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update movie dates")
+	}
 
-	return jsonResponse(c, http.StatusOK, "Movie updated successfully", fiber.Map{"movie": updatedMovie})
+	data := fiber.Map{"movie": updatedMovie}
+	if capacity >= 0 {
+		data["promoted"] = len(promoted)
+	}
+	return jsonResponse(c, http.StatusOK, "Movie updated successfully", data)
 }
 
 // Delete a movie
@@ -277,6 +532,7 @@ func DeleteMovie(c fiber.Ctx) error {
 	if result.DeletedCount == 0 {
 		return errorResponse(c, http.StatusNotFound, "Movie not found")
 	}
+	search.Refresh()
 
 	return jsonResponse(c, http.StatusOK, "Movie deleted successfully", fiber.Map{})
 }
@@ -285,22 +541,23 @@ func DeleteMovie(c fiber.Ctx) error {
 func GetMovieHandler(c fiber.Ctx) error {
 	filename := c.Params("filename")
 
-	if strings.Contains(filename, "..") {
+	key, err := storage.Key(storage.Videos, filename)
+	if err != nil {
 		return errorResponse(c, http.StatusBadRequest, "Invalid file path")
 	}
 
-	uploadDir := "./server/uploads"
-	filePath := filepath.Join(uploadDir, filename)
-
 	if filepath.Ext(filename) != ".mp4" {
 		return errorResponse(c, http.StatusBadRequest, "Invalid file type. Only MP4 files are allowed.")
 	}
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	obj, err := storage.Default().Stat(ctx, key)
+	if err != nil {
 		return errorResponse(c, http.StatusNotFound, "File not found")
 	}
 
 	c.Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
-	c.Set("Content-Type", "video/mp4")
-	return c.SendFile(filePath) // Enable range streaming
+	obj.ContentType = "video/mp4"
+	return sendStoredObject(c, obj, "") // Enable range streaming
 }
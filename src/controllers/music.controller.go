@@ -6,6 +6,7 @@ import (
 	"log"
 	"magic-server-2026/src/db"
 	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
 	"magic-server-2026/src/utils"
 	"net/http"
 	"strings"
@@ -183,6 +184,7 @@ func CreateMusic(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create music"})
 	}
+	search.Refresh()
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Music created successfully",
@@ -222,6 +224,7 @@ func UpdateMusic(c fiber.Ctx) error {
 	if err != nil || updateResult.MatchedCount == 0 {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update music or music not found"})
 	}
+	search.Refresh()
 
 	// Retrieve updated music
 	var updatedMusic models.Music
@@ -249,6 +252,7 @@ func DeleteMusic(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete music"})
 	}
+	search.Refresh()
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Music deleted successfully"})
 }
@@ -6,6 +6,7 @@ import (
 	"magic-server-2026/src/db"
 	"magic-server-2026/src/helpers"
 	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -27,6 +28,9 @@ import (
    4. Update a Post
    5. Delete a Post
    -----------------------------------
+   Every create/update is stored in news_revisions
+   (see newsrevision.controller.go)
+   -----------------------------------
    PATH: /api/v1/news
 */
 
@@ -211,6 +215,12 @@ func CreateNewsItem(c fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Title, Writer, and Category are required"})
 	}
 
+	content, err := helpers.SanitizeContentBlocks(news.Content)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid content: " + err.Error()})
+	}
+	news.Content = content
+
 	news.ID = primitive.NewObjectID()
 	news.Created_at = primitive.NewDateTimeFromTime(time.Now())
 	news.Updated_at = primitive.NewDateTimeFromTime(time.Now())
@@ -223,12 +233,23 @@ func CreateNewsItem(c fiber.Ctx) error {
 	}
 
 	log.Println("News created with ID:", result.InsertedID)
-	return c.Status(http.StatusCreated).JSON(fiber.Map{
+	search.Refresh()
+
+	response := fiber.Map{
 		"message": "News created successfully",
 		"news":    news,
-	})
+	}
+	if _, err := recordNewsRevision(ctx, c, news, "create", 0); err != nil {
+		log.Println("Error recording news revision:", err)
+		response["warning"] = newsHistoryWarning
+	}
+
+	return c.Status(http.StatusCreated).JSON(response)
 }
 
+// newsHistoryWarning tells staff a save went through but is missing from the revision history
+const newsHistoryWarning = "Saved, but this version was not recorded in the revision history"
+
 // UpdateNewsItem - Update an existing news item
 func UpdateNewsItem(c fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -245,10 +266,20 @@ func UpdateNewsItem(c fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
+	delete(updateData, "_id")
 	delete(updateData, "created_at")
+	if err := helpers.SanitizeContentField(updateData, "content"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid content: " + err.Error()})
+	}
 	updateData["updated_at"] = primitive.NewDateTimeFromTime(time.Now())
 	newsCollection := NewsCollectionInit()
 
+	// Keep the pre-history version restorable before it gets overwritten
+	if err := ensureNewsBaseline(ctx, c, objID); err != nil && err != mongo.ErrNoDocuments {
+		log.Println("Error recording news baseline:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to record news history"})
+	}
+
 	updateResult, err := newsCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateData})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update news item"})
@@ -263,10 +294,17 @@ func UpdateNewsItem(c fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch updated news"})
 	}
 
-	return c.Status(http.StatusOK).JSON(fiber.Map{
+	response := fiber.Map{
 		"message": "News item updated successfully",
 		"news":    updatedNews,
-	})
+	}
+	if _, err := recordNewsRevision(ctx, c, updatedNews, "update", 0); err != nil {
+		log.Println("Error recording news revision:", err)
+		response["warning"] = newsHistoryWarning
+	}
+	search.Refresh()
+
+	return c.Status(http.StatusOK).JSON(response)
 }
 
 // DeleteNewsItem - Delete a news item by ID
@@ -285,6 +323,7 @@ func DeleteNews(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete news item"})
 	}
+	search.Refresh()
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "News deleted successfully"})
 }
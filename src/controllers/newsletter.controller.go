@@ -0,0 +1,799 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/mailer"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Newsletter
+   -----------------------------------
+   1. Subscribe                   POST /newsletter/subscribe              { email, name?, topics? }
+      Double opt-in: nothing is sent until the emailed link is confirmed.
+   2. Confirm                     POST /newsletter/confirm                { token }
+   3. Preferences                 GET  /newsletter/preferences/:token
+   4. Change topics               PUT  /newsletter/preferences/:token     { topics }
+   5. Unsubscribe                 POST /newsletter/preferences/:token/unsubscribe
+   6. One-click unsubscribe       POST /newsletter/unsubscribe/:token     (app root, RFC 8058; GET redirects to 3)
+
+   Staff:
+   7. Subscribers                 GET  /newsletter/subscribers?status=&topic=&page=&limit=
+   8. Past issues                 GET  /newsletter/issues
+   9. Preview this week's digest  GET  /newsletter/issues/preview
+   10. Send this week's digest    POST /newsletter/issues/send            (admin; once per ISO week)
+
+   The digest goes out every Friday from 17:00 (Asia/Manila): approved
+   news of the past 7 days, the chart top 10 and screenings in the next
+   14 days, each section only to subscribers of its topic.
+   An instance sends an issue under a lease, so one interrupted by a
+   crash is picked up by the next check once the lease runs out. Only
+   the newest issue is ever resumed, and a subscriber is only claimed by
+   an issue newer than the last one they got.
+   -----------------------------------
+   PATH: /api/v1/newsletter
+*/
+
+const (
+	newsletterConfirmValidity = 7 * 24 * time.Hour
+	newsletterResendCooldown  = 10 * time.Minute
+	newsletterCheckInterval   = 15 * time.Minute
+	newsletterSendWeekday     = time.Friday
+	newsletterSendHour        = 17
+	newsletterSendTimeout     = 30 * time.Minute
+	newsletterSendLease       = newsletterSendTimeout + time.Minute
+	digestNewsLimit           = 10
+	digestChartLimit          = 10
+	digestScreeningLimit      = 5
+	digestScreeningDays       = 14
+)
+
+var (
+	newsletterSubscriberIndexOnce sync.Once
+	newsletterIssueIndexOnce      sync.Once
+)
+
+func NewsletterSubscriberCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "newsletter_subscribers")
+	newsletterSubscriberIndexOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "last_issue_id", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("Newsletter subscriber index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+func NewsletterIssueCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "newsletter_issues")
+	newsletterIssueIndexOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "week", Value: 1}}, Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Println("Newsletter issue index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+// normalizeNewsletterTopics dedupes topics and rejects unknown ones; empty means all
+func normalizeNewsletterTopics(topics []string) ([]string, bool) {
+	if len(topics) == 0 {
+		return append([]string{}, models.NewsletterTopics...), true
+	}
+	normalized := []string{}
+	for _, topic := range topics {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if !slices.Contains(models.NewsletterTopics, topic) {
+			return nil, false
+		}
+		if !slices.Contains(normalized, topic) {
+			normalized = append(normalized, topic)
+		}
+	}
+	return normalized, true
+}
+
+// queueNewsletterConfirm emails the confirmation link for the subscriber's current nonce
+func queueNewsletterConfirm(ctx context.Context, subscriber models.NewsletterSubscriber) {
+	rendered, err := mailer.Render(ctx, "newsletter-confirm", map[string]interface{}{
+		"Name":        html.UnescapeString(subscriber.Name),
+		"Confirm_url": helpers.NewsletterConfirmURL(helpers.SignNewsletterConfirm(subscriber.ID, subscriber.Confirm_nonce)),
+		"Valid_days":  int(newsletterConfirmValidity / (24 * time.Hour)),
+	})
+	if err == nil {
+		msg := rendered.Message()
+		msg.To, msg.From_name, msg.Tag = []string{subscriber.Email}, "Magic 89.9", "newsletter.confirm"
+		_, err = mailer.Enqueue(ctx, msg)
+	}
+	if err != nil {
+		log.Println("Newsletter confirmation not queued:", err)
+	}
+}
+
+// findNewsletterSubscription resolves a subscription token
+func findNewsletterSubscription(ctx context.Context, token string) (models.NewsletterSubscriber, int, string) {
+	var subscriber models.NewsletterSubscriber
+	id, err := helpers.VerifyNewsletterSubscription(token)
+	if err != nil {
+		return subscriber, http.StatusNotFound, "Subscription not found"
+	}
+	err = NewsletterSubscriberCollectionInit().FindOne(ctx, bson.M{"_id": id}).Decode(&subscriber)
+	if err == mongo.ErrNoDocuments {
+		return subscriber, http.StatusNotFound, "Subscription not found"
+	}
+	if err != nil {
+		log.Println("Find subscriber error:", err)
+		return subscriber, http.StatusInternalServerError, "Failed to fetch subscription"
+	}
+	return subscriber, 0, ""
+}
+
+func newsletterPreferencesResponse(subscriber models.NewsletterSubscriber) fiber.Map {
+	return fiber.Map{
+		"email":      subscriber.Email,
+		"name":       subscriber.Name,
+		"status":     subscriber.Status,
+		"topics":     subscriber.Topics,
+		"all_topics": models.NewsletterTopics,
+	}
+}
+
+// unsubscribeNewsletter stops all mail to a subscriber; true when it changed anything
+func unsubscribeNewsletter(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := NewsletterSubscriberCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$ne": models.SubscriberUnsubscribed}},
+		bson.M{
+			"$set":   bson.M{"status": models.SubscriberUnsubscribed, "unsubscribed_at": now, "updated_at": now},
+			"$unset": bson.M{"confirm_nonce": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// SubscribeNewsletter - Start a double opt-in signup; the response never says whether the address was known
+func SubscribeNewsletter(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input models.NewsletterSubscribeInput
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	email := helpers.NormalizeEmail(input.Email)
+	if email == "" {
+		return errorResponse(c, http.StatusBadRequest, "Invalid email address")
+	}
+	name := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(input.Name))
+	if len(name) > 100 {
+		return errorResponse(c, http.StatusBadRequest, "Name must be at most 100 characters")
+	}
+	topics, ok := normalizeNewsletterTopics(input.Topics)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "Unknown topic; choose from "+strings.Join(models.NewsletterTopics, ", "))
+	}
+
+	accepted := func() error {
+		return jsonResponse(c, http.StatusAccepted, "Check your inbox to confirm your subscription", fiber.Map{"email": email})
+	}
+
+	now := time.Now()
+	nowDT := primitive.NewDateTimeFromTime(now)
+	collection := NewsletterSubscriberCollectionInit()
+	var subscriber models.NewsletterSubscriber
+	err := collection.FindOne(ctx, bson.M{"email": email}).Decode(&subscriber)
+	switch {
+	case err == mongo.ErrNoDocuments:
+		subscriber = models.NewsletterSubscriber{
+			ID:            primitive.NewObjectID(),
+			Email:         email,
+			Name:          name,
+			Topics:        topics,
+			Status:        models.SubscriberPending,
+			Confirm_nonce: primitive.NewObjectID(),
+			IP_address:    c.IP(),
+			Created_at:    nowDT,
+			Updated_at:    nowDT,
+		}
+		if _, err := collection.InsertOne(ctx, subscriber); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return accepted()
+			}
+			log.Println("Insert subscriber error:", err)
+			return errorResponse(c, http.StatusInternalServerError, "Failed to subscribe")
+		}
+	case err != nil:
+		log.Println("Find subscriber error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to subscribe")
+	case subscriber.Status == models.SubscriberActive:
+		// already confirmed; topics only change through the preference link
+		return accepted()
+	case subscriber.Status == models.SubscriberPending && now.Sub(subscriber.Confirm_nonce.Timestamp()) < newsletterResendCooldown:
+		return accepted()
+	default:
+		subscriber.Name, subscriber.Topics = name, topics
+		subscriber.Status, subscriber.Confirm_nonce = models.SubscriberPending, primitive.NewObjectID()
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": subscriber.ID}, bson.M{"$set": bson.M{
+			"name":          subscriber.Name,
+			"topics":        subscriber.Topics,
+			"status":        subscriber.Status,
+			"confirm_nonce": subscriber.Confirm_nonce,
+			"ip_address":    c.IP(),
+			"updated_at":    nowDT,
+		}})
+		if err != nil {
+			log.Println("Update subscriber error:", err)
+			return errorResponse(c, http.StatusInternalServerError, "Failed to subscribe")
+		}
+	}
+
+	queueNewsletterConfirm(ctx, subscriber)
+	return accepted()
+}
+
+// ConfirmNewsletter - Activate a subscription from the emailed link
+func ConfirmNewsletter(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	id, nonce, err := helpers.VerifyNewsletterConfirm(input.Token)
+	if err != nil {
+		return errorResponse(c, http.StatusNotFound, "Confirmation link not found")
+	}
+	if time.Since(nonce.Timestamp()) > newsletterConfirmValidity {
+		return errorResponse(c, http.StatusGone, "This confirmation link has expired; please subscribe again")
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var subscriber models.NewsletterSubscriber
+	err = NewsletterSubscriberCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": models.SubscriberPending, "confirm_nonce": nonce},
+		bson.M{
+			"$set":   bson.M{"status": models.SubscriberActive, "confirmed_at": now, "updated_at": now},
+			"$unset": bson.M{"confirm_nonce": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&subscriber)
+	if err == mongo.ErrNoDocuments {
+		// a second click on the same link is fine; anything else is a stale link
+		err = NewsletterSubscriberCollectionInit().FindOne(ctx, bson.M{"_id": id, "status": models.SubscriberActive, "confirmed_at": bson.M{"$gte": primitive.NewDateTimeFromTime(nonce.Timestamp())}}).Decode(&subscriber)
+		if err == mongo.ErrNoDocuments {
+			return errorResponse(c, http.StatusNotFound, "Confirmation link not found or replaced by a newer one")
+		}
+	}
+	if err != nil {
+		log.Println("Confirm subscriber error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to confirm subscription")
+	}
+
+	data := newsletterPreferencesResponse(subscriber)
+	data["token"] = helpers.SignNewsletterSubscription(subscriber.ID)
+	return jsonResponse(c, http.StatusOK, "Subscription confirmed", data)
+}
+
+// GetNewsletterPreferences - What a subscription receives
+func GetNewsletterPreferences(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscriber, status, problem := findNewsletterSubscription(ctx, c.Params("token"))
+	if status != 0 {
+		return errorResponse(c, status, problem)
+	}
+	return jsonResponse(c, http.StatusOK, "Subscription fetched successfully", newsletterPreferencesResponse(subscriber))
+}
+
+// UpdateNewsletterPreferences - Pick the digest sections to receive
+func UpdateNewsletterPreferences(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscriber, status, problem := findNewsletterSubscription(ctx, c.Params("token"))
+	if status != 0 {
+		return errorResponse(c, status, problem)
+	}
+	if subscriber.Status != models.SubscriberActive {
+		return errorResponse(c, http.StatusConflict, "This subscription is not active; subscribe again to change topics")
+	}
+	var input struct {
+		Topics []string `json:"topics"`
+	}
+	if err := c.Bind().Body(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if len(input.Topics) == 0 {
+		return errorResponse(c, http.StatusBadRequest, "Choose at least one topic, or unsubscribe")
+	}
+	topics, ok := normalizeNewsletterTopics(input.Topics)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "Unknown topic; choose from "+strings.Join(models.NewsletterTopics, ", "))
+	}
+
+	subscriber.Topics = topics
+	_, err := NewsletterSubscriberCollectionInit().UpdateOne(ctx, bson.M{"_id": subscriber.ID}, bson.M{"$set": bson.M{
+		"topics": topics, "updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}})
+	if err != nil {
+		log.Println("Update subscriber topics error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update preferences")
+	}
+	return jsonResponse(c, http.StatusOK, "Preferences updated", newsletterPreferencesResponse(subscriber))
+}
+
+// UnsubscribeNewsletter - Unsubscribe from the preferences page
+func UnsubscribeNewsletter(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscriber, status, problem := findNewsletterSubscription(ctx, c.Params("token"))
+	if status != 0 {
+		return errorResponse(c, status, problem)
+	}
+	if _, err := unsubscribeNewsletter(ctx, subscriber.ID); err != nil {
+		log.Println("Unsubscribe error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to unsubscribe")
+	}
+	subscriber.Status = models.SubscriberUnsubscribed
+	return jsonResponse(c, http.StatusOK, "You have been unsubscribed", newsletterPreferencesResponse(subscriber))
+}
+
+// OneClickUnsubscribeNewsletter - RFC 8058 List-Unsubscribe-Post target; mail clients POST here without cookies
+func OneClickUnsubscribeNewsletter(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, err := helpers.VerifyNewsletterSubscription(c.Params("token"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).SendString("Subscription not found")
+	}
+	if _, err := unsubscribeNewsletter(ctx, id); err != nil {
+		log.Println("One-click unsubscribe error:", err)
+		return c.Status(http.StatusInternalServerError).SendString("Failed to unsubscribe")
+	}
+	return c.SendString("You have been unsubscribed from the Magic 89.9 newsletter.")
+}
+
+// OneClickUnsubscribeLanding - A plain GET of the List-Unsubscribe URL (link scanners, old clients) must not
+// unsubscribe, so it goes to the preferences page instead
+func OneClickUnsubscribeLanding(c fiber.Ctx) error {
+	token := c.Params("token")
+	if _, err := helpers.VerifyNewsletterSubscription(token); err != nil {
+		return c.Status(http.StatusNotFound).SendString("Subscription not found")
+	}
+	return c.Redirect().Status(http.StatusSeeOther).To(helpers.NewsletterPreferencesURL(token))
+}
+
+// GetNewsletterSubscribers - Staff list of subscribers
+func GetNewsletterSubscribers(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if topic := c.Query("topic"); topic != "" {
+		filter["topics"] = topic
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	limit = min(limit, 200)
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	collection := NewsletterSubscriberCollectionInit()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count subscribers error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch subscribers")
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		log.Println("Find subscribers error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch subscribers")
+	}
+	defer cursor.Close(ctx)
+
+	subscribers := []models.NewsletterSubscriber{}
+	if err := cursor.All(ctx, &subscribers); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse subscribers")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Subscribers fetched successfully", fiber.Map{
+		"subscribers": subscribers,
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+	})
+}
+
+// GetNewsletterIssues - Past digests, newest first
+func GetNewsletterIssues(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := NewsletterIssueCollectionInit().Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(52))
+	if err != nil {
+		log.Println("Find issues error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch issues")
+	}
+	defer cursor.Close(ctx)
+
+	issues := []models.NewsletterIssue{}
+	if err := cursor.All(ctx, &issues); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse issues")
+	}
+	return jsonResponse(c, http.StatusOK, "Issues fetched successfully", fiber.Map{"issues": issues})
+}
+
+// PreviewNewsletterIssue - This week's digest as a subscriber of every topic would get it
+func PreviewNewsletterIssue(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	issue, err := buildNewsletterIssue(ctx, time.Now())
+	if err != nil {
+		log.Println("Build digest error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to build digest")
+	}
+	sample := models.NewsletterSubscriber{Name: "Listener", Topics: models.NewsletterTopics}
+	rendered, err := mailer.Render(ctx, "newsletter-digest", digestTemplateData(issue, sample, "preview"))
+	if err != nil {
+		return mailTemplateError(c, err, "render digest")
+	}
+	return jsonResponse(c, http.StatusOK, "Digest preview built", fiber.Map{"issue": issue, "preview": rendered})
+}
+
+// SendNewsletterIssue - Build and send this week's digest now instead of waiting for Friday
+func SendNewsletterIssue(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, username := revisionAuthor(c)
+	issue, created, err := startNewsletterIssue(ctx, time.Now(), username)
+	if err != nil {
+		log.Println("Start digest error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to build digest")
+	}
+	if !created {
+		return errorResponse(c, http.StatusConflict, "This week's digest has already been sent")
+	}
+	go sendNewsletterIssue(issue)
+
+	return jsonResponse(c, http.StatusAccepted, "Digest is being sent", fiber.Map{"issue": issue})
+}
+
+// newsletterWeek is the ISO week key and the "Oct 12 - 18" label of the 7 days ending at now
+func newsletterWeek(now time.Time) (string, string) {
+	now = now.In(utils.LocationAsiaManila)
+	year, week := now.ISOWeek()
+	start := now.AddDate(0, 0, -6)
+	label := start.Format("Jan 2") + " - " + now.Format("2")
+	if start.Month() != now.Month() {
+		label = start.Format("Jan 2") + " - " + now.Format("Jan 2")
+	}
+	return fmt.Sprintf("%d-W%02d", year, week), label
+}
+
+// buildNewsletterIssue snapshots the week's approved news, the chart and upcoming screenings
+func buildNewsletterIssue(ctx context.Context, now time.Time) (models.NewsletterIssue, error) {
+	week, label := newsletterWeek(now)
+	issue := models.NewsletterIssue{
+		Week:       week,
+		Label:      label,
+		News:       []models.DigestNews{},
+		Chart:      []models.DigestChartEntry{},
+		Screenings: []models.DigestScreening{},
+	}
+
+	var news []models.News
+	cursor, err := NewsCollectionInit().Find(ctx, bson.M{
+		"status":     "approved",
+		"created_at": bson.M{"$gte": primitive.NewDateTimeFromTime(now.AddDate(0, 0, -7)), "$lte": primitive.NewDateTimeFromTime(now)},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(digestNewsLimit))
+	if err != nil {
+		return issue, err
+	}
+	if err := cursor.All(ctx, &news); err != nil {
+		return issue, err
+	}
+	for _, item := range news {
+		issue.News = append(issue.News, models.DigestNews{
+			Title: item.Title, Category: item.Category, Image: helpers.PublicImageURL(item.News_Image), URL: helpers.NewsURL(item),
+		})
+	}
+
+	var music []models.Music
+	cursor, err = MusicCollectionInit().Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "votes", Value: -1}, {Key: "title", Value: 1}}).
+		SetLimit(digestChartLimit))
+	if err != nil {
+		return issue, err
+	}
+	if err := cursor.All(ctx, &music); err != nil {
+		return issue, err
+	}
+	for i, track := range music {
+		issue.Chart = append(issue.Chart, models.DigestChartEntry{
+			Position: i + 1, Title: track.Title, Artist: strings.Join(track.Artist, ", "), Votes: track.Votes, URL: helpers.MusicURL(track),
+		})
+	}
+
+	var movies []models.Movies
+	cursor, err = MoviesCollectionInit().Find(ctx, bson.M{
+		"screening_at": bson.M{"$gte": primitive.NewDateTimeFromTime(now), "$lt": primitive.NewDateTimeFromTime(now.AddDate(0, 0, digestScreeningDays))},
+	}, options.Find().SetSort(bson.D{{Key: "screening_at", Value: 1}}).SetLimit(digestScreeningLimit))
+	if err != nil {
+		return issue, err
+	}
+	if err := cursor.All(ctx, &movies); err != nil {
+		return issue, err
+	}
+	for _, movie := range movies {
+		issue.Screenings = append(issue.Screenings, models.DigestScreening{
+			Title: movie.Title, Cinema: movie.Location_cinema, At: movie.Screening_at, URL: helpers.MovieURL(movie),
+		})
+	}
+	return issue, nil
+}
+
+// digestTemplateData is the newsletter-digest data for one subscriber: only the sections of their topics
+func digestTemplateData(issue models.NewsletterIssue, subscriber models.NewsletterSubscriber, token string) map[string]interface{} {
+	chart, news, screenings := []interface{}{}, []interface{}{}, []interface{}{}
+	if slices.Contains(subscriber.Topics, models.TopicChart) {
+		for _, entry := range issue.Chart {
+			chart = append(chart, map[string]interface{}{"Position": entry.Position, "Title": entry.Title, "Artist": entry.Artist, "URL": entry.URL})
+		}
+	}
+	if slices.Contains(subscriber.Topics, models.TopicNews) {
+		for _, item := range issue.News {
+			news = append(news, map[string]interface{}{"Title": item.Title, "Category": item.Category, "URL": item.URL})
+		}
+	}
+	if slices.Contains(subscriber.Topics, models.TopicScreenings) {
+		for _, screening := range issue.Screenings {
+			screenings = append(screenings, map[string]interface{}{
+				"Title": screening.Title, "Cinema": screening.Cinema, "URL": screening.URL,
+				"When": screening.At.Time().In(utils.LocationAsiaManila).Format("Mon, Jan 2 3:04 PM"),
+			})
+		}
+	}
+	return map[string]interface{}{
+		"Name":            html.UnescapeString(subscriber.Name),
+		"Week":            issue.Label,
+		"Chart":           chart,
+		"News":            news,
+		"Screenings":      screenings,
+		"Preferences_url": helpers.NewsletterPreferencesURL(token),
+		"Unsubscribe_url": helpers.NewsletterUnsubscribeURL(token),
+	}
+}
+
+// startNewsletterIssue builds and stores this week's issue; false when the week already has one
+func startNewsletterIssue(ctx context.Context, now time.Time, by string) (models.NewsletterIssue, bool, error) {
+	issue, err := buildNewsletterIssue(ctx, now)
+	if err != nil {
+		return issue, false, err
+	}
+	issue.ID = primitive.NewObjectID()
+	issue.Status = models.IssueSending
+	issue.Created_by = by
+	issue.Created_at = primitive.NewDateTimeFromTime(now)
+	if _, err := NewsletterIssueCollectionInit().InsertOne(ctx, issue); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return issue, false, nil
+		}
+		return issue, false, err
+	}
+	return issue, true, nil
+}
+
+// leaseNewsletterIssue takes the issue for this instance; false while another one holds it
+func leaseNewsletterIssue(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	result, err := NewsletterIssueCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.IssueSending, "$or": bson.A{
+			bson.M{"lease_until": bson.M{"$exists": false}},
+			bson.M{"lease_until": bson.M{"$lte": primitive.NewDateTimeFromTime(now)}},
+		}},
+		bson.M{"$set": bson.M{"lease_until": primitive.NewDateTimeFromTime(now.Add(newsletterSendLease))}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// sendNewsletterIssue queues the issue for every active subscriber who has not had it yet.
+// Each subscriber is claimed before their mail is queued, so a resumed or concurrent run never
+// sends twice (a crash in between loses that one email instead). Subscribers who already got
+// a newer issue are never claimed by an older one.
+func sendNewsletterIssue(issue models.NewsletterIssue) {
+	ctx, cancel := context.WithTimeout(context.Background(), newsletterSendTimeout)
+	defer cancel()
+
+	leased, err := leaseNewsletterIssue(ctx, issue.ID)
+	if err != nil {
+		log.Println("[NEWSLETTER] issue lease failed:", err)
+		return
+	}
+	if !leased {
+		return
+	}
+	release := func() {
+		NewsletterIssueCollectionInit().UpdateOne(context.Background(), bson.M{"_id": issue.ID}, bson.M{"$unset": bson.M{"lease_until": ""}})
+	}
+
+	version, err := mailer.ActiveTemplate(ctx, "newsletter-digest")
+	if err != nil {
+		log.Println("[NEWSLETTER] digest template:", err)
+		release()
+		return
+	}
+
+	collection := NewsletterSubscriberCollectionInit()
+	sent := 0
+	for {
+		var subscriber models.NewsletterSubscriber
+		err := collection.FindOneAndUpdate(ctx,
+			bson.M{"status": models.SubscriberActive, "$or": bson.A{
+				bson.M{"last_issue_id": bson.M{"$exists": false}},
+				bson.M{"last_issue_id": bson.M{"$lt": issue.ID}},
+			}},
+			bson.M{"$set": bson.M{"last_issue_id": issue.ID}},
+		).Decode(&subscriber)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			log.Printf("[NEWSLETTER] %s stopped after %d: %v", issue.Week, sent, err)
+			NewsletterIssueCollectionInit().UpdateOne(context.Background(), bson.M{"_id": issue.ID}, bson.M{
+				"$inc":   bson.M{"recipient_count": sent},
+				"$unset": bson.M{"lease_until": ""},
+			})
+			return
+		}
+
+		token := helpers.SignNewsletterSubscription(subscriber.ID)
+		data := digestTemplateData(issue, subscriber, token)
+		if len(data["Chart"].([]interface{}))+len(data["News"].([]interface{}))+len(data["Screenings"].([]interface{})) == 0 {
+			continue
+		}
+		rendered, err := version.Render(data)
+		if err != nil {
+			log.Printf("[NEWSLETTER] %s not rendered for %s: %v", issue.Week, subscriber.Email, err)
+			continue
+		}
+		msg := rendered.Message()
+		msg.To, msg.From_name, msg.Tag = []string{subscriber.Email}, "Magic 89.9", "newsletter.digest"
+		msg.Headers = map[string]string{
+			"List-Unsubscribe":      "<" + helpers.NewsletterUnsubscribeURL(token) + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+		if _, err := mailer.Enqueue(ctx, msg); err != nil {
+			log.Printf("[NEWSLETTER] %s not queued for %s: %v", issue.Week, subscriber.Email, err)
+			continue
+		}
+		sent++
+	}
+
+	_, err = NewsletterIssueCollectionInit().UpdateOne(ctx, bson.M{"_id": issue.ID}, bson.M{
+		"$set":   bson.M{"status": models.IssueSent, "sent_at": primitive.NewDateTimeFromTime(time.Now())},
+		"$inc":   bson.M{"recipient_count": sent},
+		"$unset": bson.M{"lease_until": ""},
+	})
+	if err != nil {
+		log.Println("[NEWSLETTER] issue update failed:", err)
+	}
+	log.Printf("[NEWSLETTER] %s queued for %d subscribers", issue.Week, sent)
+}
+
+// resumeNewsletterIssue finishes the newest issue if it was interrupted; older
+// unfinished issues are marked superseded instead of being sent late
+func resumeNewsletterIssue() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := NewsletterIssueCollectionInit()
+	var newest models.NewsletterIssue
+	err := collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).Decode(&newest)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$lt": newest.ID}, "status": models.IssueSending},
+		bson.M{"$set": bson.M{"status": models.IssueSuperseded}, "$unset": bson.M{"lease_until": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if newest.Status == models.IssueSending {
+		sendNewsletterIssue(newest)
+	}
+	return nil
+}
+
+// runNewsletterSchedule finishes an interrupted issue and starts the weekly one when it is due
+func runNewsletterSchedule(now time.Time) {
+	if err := resumeNewsletterIssue(); err != nil {
+		log.Println("[NEWSLETTER] schedule check failed:", err)
+		return
+	}
+
+	local := now.In(utils.LocationAsiaManila)
+	if local.Weekday() != newsletterSendWeekday || local.Hour() < newsletterSendHour {
+		return
+	}
+	// a resumed send may have taken a while, so the build gets its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	issue, created, err := startNewsletterIssue(ctx, now, "scheduler")
+	if err != nil {
+		log.Println("[NEWSLETTER] digest build failed:", err)
+		return
+	}
+	if created {
+		sendNewsletterIssue(issue)
+	}
+}
+
+// InitNewsletter runs the weekly digest schedule; call it in a goroutine at startup
+func InitNewsletter() {
+	ticker := time.NewTicker(newsletterCheckInterval)
+	defer ticker.Stop()
+	for {
+		runNewsletterSchedule(time.Now())
+		<-ticker.C
+	}
+}
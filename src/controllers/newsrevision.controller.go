@@ -0,0 +1,361 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   News Revision Controller
+   -----------------------------------
+   1. List revisions of a news item
+   2. Get a single revision
+   3. Diff two revisions (field level)
+   4. Restore a revision (as a new revision)
+   -----------------------------------
+   Revisions are numbered from 1. News items saved before the history
+   existed get their old version stored as revision 0 ("baseline") on
+   their first update. Revisions are not recorded while the unique
+   (news_id, revision) index is missing, since numbers could repeat.
+   -----------------------------------
+   PATH: /api/v1/news/:id/revisions
+*/
+
+const (
+	// newsRevisionAttempts bounds retries when concurrent saves race for the same revision number
+	newsRevisionAttempts = 5
+	// newsRevisionIndexRetry is how long to wait before trying a failed index creation again
+	newsRevisionIndexRetry = time.Minute
+)
+
+var errNewsRevisionIndex = errors.New("the unique news revision index is missing")
+
+var (
+	newsRevisionIndexMu    sync.Mutex
+	newsRevisionIndexed    bool
+	newsRevisionIndexTried time.Time
+)
+
+func NewsRevisionCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "news_revisions")
+	newsRevisionIndexReady(collection)
+	return collection
+}
+
+// newsRevisionIndexReady creates the unique (news_id, revision) index and
+// reports whether it exists; a failed creation is tried again after a while
+func newsRevisionIndexReady(collection *mongo.Collection) bool {
+	newsRevisionIndexMu.Lock()
+	defer newsRevisionIndexMu.Unlock()
+	if newsRevisionIndexed || time.Since(newsRevisionIndexTried) < newsRevisionIndexRetry {
+		return newsRevisionIndexed
+	}
+	newsRevisionIndexTried = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "news_id", Value: 1}, {Key: "revision", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("[NEWS] revision index creation failed:", err)
+		return false
+	}
+	newsRevisionIndexed = true
+	return true
+}
+
+// revisionAuthor returns the staff user set by AuthMiddleware, if any
+func revisionAuthor(c fiber.Ctx) (primitive.ObjectID, string) {
+	authorID, _ := c.Locals("user_id").(primitive.ObjectID)
+	author, _ := c.Locals("username").(string)
+	if author == "" {
+		author = "unknown"
+	}
+	return authorID, author
+}
+
+// latestNewsRevision returns the highest revision number stored for a news item (0 if none)
+func latestNewsRevision(ctx context.Context, newsID primitive.ObjectID) (int, error) {
+	var latest models.NewsRevision
+	opts := options.FindOne().SetSort(bson.D{{Key: "revision", Value: -1}})
+	err := NewsRevisionCollectionInit().FindOne(ctx, bson.M{"news_id": newsID}, opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return latest.Revision, nil
+}
+
+// recordNewsRevision appends a snapshot of news as the next revision. The
+// unique (news_id, revision) index turns a concurrent save that took the same
+// number into a duplicate key error, and the loser retries with the next one.
+func recordNewsRevision(ctx context.Context, c fiber.Ctx, news models.News, action string, restoredFrom int) (models.NewsRevision, error) {
+	collection := NewsRevisionCollectionInit()
+	if !newsRevisionIndexReady(collection) {
+		return models.NewsRevision{}, errNewsRevisionIndex
+	}
+	authorID, author := revisionAuthor(c)
+	var err error
+	for attempt := 0; attempt < newsRevisionAttempts; attempt++ {
+		var latest int
+		if latest, err = latestNewsRevision(ctx, news.ID); err != nil {
+			return models.NewsRevision{}, err
+		}
+
+		revision := models.NewsRevision{
+			ID:            primitive.NewObjectID(),
+			News_id:       news.ID,
+			Revision:      latest + 1,
+			Action:        action,
+			Restored_from: restoredFrom,
+			Author_id:     authorID,
+			Author:        author,
+			Snapshot:      news,
+			Created_at:    primitive.NewDateTimeFromTime(time.Now()),
+		}
+		if _, err = collection.InsertOne(ctx, revision); err == nil {
+			return revision, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return models.NewsRevision{}, err
+		}
+	}
+	return models.NewsRevision{}, err
+}
+
+// ensureNewsBaseline stores the current document as revision 0 for news items
+// created before revision history existed, so the original stays restorable.
+// It is an upsert on (news_id, revision 0), so two updates racing on an item
+// without history store a single baseline.
+func ensureNewsBaseline(ctx context.Context, c fiber.Ctx, newsID primitive.ObjectID) error {
+	collection := NewsRevisionCollectionInit()
+	if !newsRevisionIndexReady(collection) {
+		return errNewsRevisionIndex
+	}
+	latest, err := latestNewsRevision(ctx, newsID)
+	if err != nil || latest > 0 {
+		return err
+	}
+
+	var current models.News
+	if err := NewsCollectionInit().FindOne(ctx, bson.M{"_id": newsID}).Decode(&current); err != nil {
+		return err
+	}
+
+	authorID, author := revisionAuthor(c)
+	baseline := models.NewsRevision{
+		ID:         primitive.NewObjectID(),
+		News_id:    newsID,
+		Revision:   0,
+		Action:     "baseline",
+		Author_id:  authorID,
+		Author:     author,
+		Snapshot:   current,
+		Created_at: primitive.NewDateTimeFromTime(time.Now()),
+	}
+	_, err = collection.UpdateOne(ctx,
+		bson.M{"news_id": newsID, "revision": 0},
+		bson.M{"$setOnInsert": baseline},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil // the racing update stored it
+	}
+	return err
+}
+
+func findNewsRevision(ctx context.Context, newsID primitive.ObjectID, number int) (models.NewsRevision, error) {
+	var revision models.NewsRevision
+	err := NewsRevisionCollectionInit().FindOne(ctx, bson.M{"news_id": newsID, "revision": number}).Decode(&revision)
+	return revision, err
+}
+
+// GetNewsRevisions - List all revisions of a news item, newest first (without snapshots)
+func GetNewsRevisions(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid News ID"})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "revision", Value: -1}}).
+		SetProjection(bson.M{"snapshot": 0})
+
+	cursor, err := NewsRevisionCollectionInit().Find(ctx, bson.M{"news_id": objID}, opts)
+	if err != nil {
+		log.Println("Find revisions error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch revisions"})
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []models.NewsRevision{}
+	if err = cursor.All(ctx, &revisions); err != nil {
+		log.Println("Cursor decode error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse revisions"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message":   "Revisions fetched successfully",
+		"revisions": revisions,
+	})
+}
+
+// GetNewsRevision - Get a single revision including its snapshot
+func GetNewsRevision(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid News ID"})
+	}
+
+	number, err := strconv.Atoi(c.Params("revision"))
+	if err != nil || number < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid revision number"})
+	}
+
+	revision, err := findNewsRevision(ctx, objID, number)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch revision"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message":  "Revision fetched successfully",
+		"revision": revision,
+	})
+}
+
+// DiffNewsRevisions - Field-level diff between two revisions (?from=1&to=3)
+func DiffNewsRevisions(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid News ID"})
+	}
+
+	fromNumber, errFrom := strconv.Atoi(c.Query("from"))
+	toNumber, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || fromNumber < 0 || toNumber < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to must be revision numbers"})
+	}
+
+	from, err := findNewsRevision(ctx, objID, fromNumber)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision " + strconv.Itoa(fromNumber) + " not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch revision"})
+	}
+
+	to, err := findNewsRevision(ctx, objID, toNumber)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision " + strconv.Itoa(toNumber) + " not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch revision"})
+	}
+
+	changes, err := helpers.DiffNews(from.Snapshot, to.Snapshot)
+	if err != nil {
+		log.Println("Diff error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to diff revisions"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Revisions compared successfully",
+		"from":    fromNumber,
+		"to":      toNumber,
+		"changes": changes,
+	})
+}
+
+// RestoreNewsRevision - Restore an older revision; the restore is stored as a new revision
+func RestoreNewsRevision(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid News ID"})
+	}
+
+	number, err := strconv.Atoi(c.Params("revision"))
+	if err != nil || number < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid revision number"})
+	}
+
+	revision, err := findNewsRevision(ctx, objID, number)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch revision"})
+	}
+
+	// a restore without history could not be undone
+	if !newsRevisionIndexReady(NewsRevisionCollectionInit()) {
+		log.Println("Restore error:", errNewsRevisionIndex)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to record news history"})
+	}
+
+	restored := revision.Snapshot
+	restored.ID = objID
+	restored.Updated_at = primitive.NewDateTimeFromTime(time.Now())
+
+	var current models.News
+	newsCollection := NewsCollectionInit()
+	if err := newsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&current); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "News item not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch news item"})
+	}
+	restored.Created_at = current.Created_at
+
+	if _, err := newsCollection.ReplaceOne(ctx, bson.M{"_id": objID}, restored); err != nil {
+		log.Println("Restore error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to restore revision"})
+	}
+
+	search.Refresh()
+
+	newRevision, err := recordNewsRevision(ctx, c, restored, "restore", number)
+	if err != nil {
+		log.Println("Record revision error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Revision restored but history was not recorded"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message":  "Revision restored successfully",
+		"revision": newRevision.Revision,
+		"news":     restored,
+	})
+}
@@ -1,53 +1,100 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"magic-server-2026/src/storage"
 	"magic-server-2026/src/utils"
-	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
+// validPlayerLink checks the expires/sig pair, and the session for bound links (s=1)
+func validPlayerLink(c fiber.Ctx, filename string) bool {
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		return false
+	}
+	session := ""
+	if c.Query("s") != "" {
+		if session = c.Cookies("session_id"); session == "" {
+			return false
+		}
+	}
+	return utils.ValidateSignedURL(filename, expires, c.Query("sig"), session)
+}
+
+// GetVideoPlayer - Stream a video; only with a valid signed URL from GetVideoPlayerSignedURL
 func GetVideoPlayer(c fiber.Ctx) error {
 	filename := c.Params("filename")
+	key, err := storage.Key(storage.Videos, filename)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid file name"})
+	}
+	if !validPlayerLink(c, filename) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Invalid or expired link"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	obj, err := storage.Default().Stat(ctx, key)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	}
 
 	// Serve video
-	uploadDir := "./src/uploads/videos"
-	filePath := filepath.Join(uploadDir, filename)
-
-	contentType := "application/octet-stream"
+	obj.ContentType = "application/octet-stream"
 	switch filepath.Ext(filename) {
 	case ".mp4":
-		contentType = "video/mp4"
+		obj.ContentType = "video/mp4"
 	case ".avi":
-		contentType = "video/x-msvideo"
+		obj.ContentType = "video/x-msvideo"
 	case ".mov":
-		contentType = "video/quicktime"
+		obj.ContentType = "video/quicktime"
 	}
 
 	c.Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
-	c.Set("Content-Type", contentType)
-	return c.SendFile(filePath)
+	c.Set("Content-Type", obj.ContentType)
+	return sendStoredObject(c, obj, "")
 }
 
+// GetVideoPlayerSignedURL - Issue a player URL; ?bind=session ties it to the listener's session
 func GetVideoPlayerSignedURL(c fiber.Ctx) error {
 	filename := c.Params("filename")
 
 	// ResourceTokenMiddleware already validates X-RSP-Token
 	// Validate file exists
-	uploadDir := "./src/uploads/videos"
-	filePath := filepath.Join(uploadDir, filename)
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	key, err := storage.Key(storage.Videos, filename)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid file name"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := storage.Default().Stat(ctx, key); err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
 	}
 
-	// Generate signed URL (expires in 5 min)
-	signedURL, err := utils.GenerateSignedURL(filename, 5*time.Minute)
+	session := ""
+	switch c.Query("bind") {
+	case "":
+	case "session":
+		if session = c.Cookies("session_id"); session == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No session to bind the link to"})
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "bind must be session"})
+	}
+
+	signedURL, expiresAt, err := utils.GenerateSignedURL(filename, utils.SignedURLTTL(), session)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot generate URL"})
 	}
 
-	return c.JSON(fiber.Map{"url": signedURL})
+	return c.JSON(fiber.Map{
+		"url":        utils.ServerOrigin() + signedURL,
+		"expires_at": expiresAt,
+		"bound":      session != "",
+	})
 }
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"magic-server-2026/src/search"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/*
+   Related Content Controller
+   -----------------------------------
+   GET /api/v1/:type/:id/related?limit=6
+   "You may also like" items across news, shows, movies and videos,
+   ranked by shared categories, tags, artists and hosts plus text similarity.
+   Results are cached per item and recomputed when content changes.
+   -----------------------------------
+*/
+
+const relatedDefaultLimit = 6
+
+// relatedTypeAliases maps route names (/news, /shows, /movies, /magic-videos) to content types
+var relatedTypeAliases = map[string]string{
+	"news":         search.TypeNews,
+	"show":         search.TypeShow,
+	"shows":        search.TypeShow,
+	"movie":        search.TypeMovie,
+	"movies":       search.TypeMovie,
+	"video":        search.TypeVideo,
+	"videos":       search.TypeVideo,
+	"magic-videos": search.TypeVideo,
+}
+
+// GetRelatedContent - Recommendations for a news item, show, movie or video
+func GetRelatedContent(c fiber.Ctx) error {
+	docType, ok := relatedTypeAliases[strings.ToLower(c.Params("type"))]
+	if !ok {
+		return errorResponse(c, http.StatusNotFound, "Unknown content type")
+	}
+
+	id := c.Params("id")
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(relatedDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = relatedDefaultLimit
+	}
+	limit = min(limit, search.MaxRelated)
+
+	items, found := search.FindRelated(docType, id)
+	if !found {
+		return errorResponse(c, http.StatusNotFound, "Content not found")
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	return jsonResponse(c, http.StatusOK, "Related content fetched successfully", fiber.Map{
+		"type":    docType,
+		"id":      id,
+		"related": items,
+	})
+}
@@ -0,0 +1,380 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Movie Reviews Controller
+   -----------------------------------
+   1. List approved reviews   GET  /movies/:id/reviews?page=1&limit=10&sort=newest|oldest|highest|lowest
+   2. Rate / review a movie   POST /movies/:id/reviews (one per email, held for moderation)
+   Staff only:
+   3. Moderation queue        GET    /movie-reviews?status=pending
+   4. Approve / reject        PATCH  /movie-reviews/:reviewId
+   5. Delete                  DELETE /movie-reviews/:reviewId
+   -----------------------------------
+   rating, review_count and rating_distribution on the movie are
+   recomputed from approved reviews after every moderation change.
+   -----------------------------------
+*/
+
+const (
+	reviewDefaultLimit = 10
+	reviewMaxLimit     = 50
+)
+
+var reviewSorts = map[string]bson.D{
+	"newest":  {{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	"oldest":  {{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+	"highest": {{Key: "rating", Value: -1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	"lowest":  {{Key: "rating", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+}
+
+var reviewIndexesOnce sync.Once
+
+func MovieReviewCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "movie_reviews")
+	reviewIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[REVIEWS] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+// reviewPage reads ?page and ?limit
+func reviewPage(c fiber.Ctx) (page, limit int) {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(reviewDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = reviewDefaultLimit
+	}
+	limit = min(limit, reviewMaxLimit)
+
+	page, err = strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return page, limit
+}
+
+// findReviews returns one page of reviews matching filter and the total count
+func findReviews(ctx context.Context, filter bson.M, sort bson.D, page, limit int) ([]models.MovieReview, int64, error) {
+	collection := MovieReviewCollectionInit()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(sort).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	reviews := []models.MovieReview{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// recomputeMovieRating stores the aggregate of a movie's approved reviews on the movie
+func recomputeMovieRating(ctx context.Context, movieID primitive.ObjectID) (models.RatingSummary, error) {
+	summary := models.RatingSummary{Rating_distribution: map[string]int{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}}
+
+	cursor, err := MovieReviewCollectionInit().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"movie_id": movieID, "status": models.ReviewApproved}}},
+		{{Key: "$group", Value: bson.M{"_id": "$rating", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return summary, err
+	}
+	defer cursor.Close(ctx)
+
+	var buckets []struct {
+		Rating int `bson:"_id"`
+		Count  int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return summary, err
+	}
+
+	total := 0
+	for _, bucket := range buckets {
+		summary.Rating_distribution[strconv.Itoa(bucket.Rating)] = bucket.Count
+		summary.Review_count += bucket.Count
+		total += bucket.Rating * bucket.Count
+	}
+	if summary.Review_count > 0 {
+		summary.Rating = math.Round(float64(total)/float64(summary.Review_count)*100) / 100
+	}
+
+	_, err = MoviesCollectionInit().UpdateOne(ctx, bson.M{"_id": movieID}, bson.M{"$set": bson.M{
+		"rating":              summary.Rating,
+		"review_count":        summary.Review_count,
+		"rating_distribution": summary.Rating_distribution,
+	}})
+	return summary, err
+}
+
+// publicReview keeps the display fields of a review
+func publicReview(review models.MovieReview) models.PublicMovieReview {
+	return models.PublicMovieReview{
+		ID:         review.ID,
+		Movie_id:   review.Movie_id,
+		Name:       review.Name,
+		Rating:     review.Rating,
+		Title:      review.Title,
+		Body:       review.Body,
+		Status:     review.Status,
+		Created_at: review.Created_at,
+	}
+}
+
+// GetMovieReviews - Approved reviews of a movie with its rating summary
+func GetMovieReviews(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movie, err := findMovieParam(ctx, c)
+	if err != nil {
+		return movieParamError(c, err)
+	}
+
+	sortName := c.Query("sort", "newest")
+	sort, ok := reviewSorts[sortName]
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "sort must be newest, oldest, highest or lowest")
+	}
+	page, limit := reviewPage(c)
+
+	reviews, total, err := findReviews(ctx, bson.M{"movie_id": movie.ID, "status": models.ReviewApproved}, sort, page, limit)
+	if err != nil {
+		log.Println("Find reviews error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch reviews")
+	}
+	public := make([]models.PublicMovieReview, len(reviews))
+	for i, review := range reviews {
+		public[i] = publicReview(review)
+	}
+
+	distribution := movie.Rating_distribution
+	if distribution == nil {
+		distribution = map[string]int{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}
+	}
+	return jsonResponse(c, http.StatusOK, "Reviews fetched successfully", fiber.Map{
+		"summary": models.RatingSummary{
+			Rating:              movie.Rating,
+			Review_count:        movie.Review_count,
+			Rating_distribution: distribution,
+		},
+		"reviews": public,
+		"sort":    sortName,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+	})
+}
+
+// CreateMovieReview - Listener rating and review, held for moderation
+func CreateMovieReview(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movie, err := findMovieParam(ctx, c)
+	if err != nil {
+		return movieParamError(c, err)
+	}
+
+	var input models.MovieReviewInput
+	if err := c.Bind().JSON(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	policy := bluemonday.StrictPolicy()
+	name := strings.TrimSpace(policy.Sanitize(input.Name))
+	title := strings.TrimSpace(policy.Sanitize(input.Title))
+	body := strings.TrimSpace(policy.Sanitize(input.Body))
+	email := helpers.NormalizeEmail(input.Email)
+	switch {
+	case name == "" || len(name) > 100:
+		return errorResponse(c, http.StatusBadRequest, "Name is required (max 100 characters)")
+	case email == "":
+		return errorResponse(c, http.StatusBadRequest, "Invalid email address")
+	case input.Rating < 1 || input.Rating > 5:
+		return errorResponse(c, http.StatusBadRequest, "Rating must be 1 to 5 stars")
+	case len(title) > 120:
+		return errorResponse(c, http.StatusBadRequest, "Title is too long (max 120 characters)")
+	case len(body) > 2000:
+		return errorResponse(c, http.StatusBadRequest, "Review is too long (max 2000 characters)")
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	review := models.MovieReview{
+		ID:         primitive.NewObjectID(),
+		Movie_id:   movie.ID,
+		Name:       name,
+		Email:      email,
+		Rating:     input.Rating,
+		Title:      title,
+		Body:       body,
+		Status:     models.ReviewPending,
+		Created_at: now,
+		Updated_at: now,
+	}
+	if _, err := MovieReviewCollectionInit().InsertOne(ctx, review); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "You have already reviewed this movie")
+		}
+		log.Println("Insert review error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to submit review")
+	}
+
+	return jsonResponse(c, http.StatusCreated, "Review submitted for moderation", fiber.Map{"review": publicReview(review)})
+}
+
+// GetReviewQueue - Staff list of reviews by status (default pending), oldest first
+func GetReviewQueue(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	switch status := c.Query("status", models.ReviewPending); status {
+	case models.ReviewPending, models.ReviewApproved, models.ReviewRejected:
+		filter["status"] = status
+	case "all":
+	default:
+		return errorResponse(c, http.StatusBadRequest, "Invalid status")
+	}
+	if movieID := c.Query("movie_id"); movieID != "" {
+		objID, err := primitive.ObjectIDFromHex(movieID)
+		if err != nil {
+			return errorResponse(c, http.StatusBadRequest, "Invalid Movie ID")
+		}
+		filter["movie_id"] = objID
+	}
+	page, limit := reviewPage(c)
+
+	reviews, total, err := findReviews(ctx, filter, reviewSorts["oldest"], page, limit)
+	if err != nil {
+		log.Println("Find reviews error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch reviews")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Reviews fetched successfully", fiber.Map{
+		"reviews": reviews,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+	})
+}
+
+// ModerateMovieReview - Staff approve or reject a review
+func ModerateMovieReview(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("reviewId"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Review ID")
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if body.Status != models.ReviewApproved && body.Status != models.ReviewRejected && body.Status != models.ReviewPending {
+		return errorResponse(c, http.StatusBadRequest, "status must be approved, rejected or pending")
+	}
+
+	_, moderator := revisionAuthor(c)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var review models.MovieReview
+	err = MovieReviewCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{
+			"status":          body.Status,
+			"moderation_note": strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(body.Note)),
+			"moderated_by":    moderator,
+			"moderated_at":    now,
+			"updated_at":      now,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Review not found")
+	}
+	if err != nil {
+		log.Println("Moderate review error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to moderate review")
+	}
+
+	summary, err := recomputeMovieRating(ctx, review.Movie_id)
+	if err != nil {
+		log.Println("Recompute rating error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Review updated but the rating was not recomputed")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Review "+body.Status, fiber.Map{
+		"review":  review,
+		"summary": summary,
+	})
+}
+
+// DeleteMovieReview - Staff delete a review
+func DeleteMovieReview(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("reviewId"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Review ID")
+	}
+
+	var review models.MovieReview
+	if err := MovieReviewCollectionInit().FindOneAndDelete(ctx, bson.M{"_id": objID}).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errorResponse(c, http.StatusNotFound, "Review not found")
+		}
+		log.Println("Delete review error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to delete review")
+	}
+
+	summary, err := recomputeMovieRating(ctx, review.Movie_id)
+	if err != nil {
+		log.Println("Recompute rating error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Review deleted but the rating was not recomputed")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Review deleted successfully", fiber.Map{"summary": summary})
+}
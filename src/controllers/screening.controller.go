@@ -0,0 +1,558 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Advanced Screening Controller
+   -----------------------------------
+   1. Screening availability            GET    /movies/:id/screening
+   2. Register (seat or waitlist)       POST   /movies/:id/screening/registrations
+   3. Cancel with the pass token        POST   /movies/:id/screening/cancel
+   4. QR pass image (public)            GET    /screening-passes/:token.png
+   Staff only:
+   5. Set capacity                      PUT    /movies/:id/screening
+   6. List registrations                GET    /movies/:id/screening/registrations
+   7. Cancel a registration             DELETE /movies/:id/screening/registrations/:registrationId
+   8. Check in at the door              POST   /movies/:id/screening/check-in
+   -----------------------------------
+   Seats are counted on the movie (screening_seats_taken) and only taken
+   with a conditional $inc, so capacity holds under concurrent sign-ups.
+   A cancelled seat goes to the oldest waitlisted registration.
+   -----------------------------------
+   PATH: /api/v1/movies/:id/screening
+*/
+
+var screeningIndexesOnce sync.Once
+
+func ScreeningRegistrationCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "screening_registrations")
+	screeningIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// one active registration per email and per phone for each screening
+		active := options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"active": true})
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "email", Value: 1}}, Options: active},
+			{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "phone", Value: 1}}, Options: active},
+			{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[SCREENING] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+var errScreeningCheckedIn = errors.New("registration already checked in")
+
+// screeningClosedReason explains why a screening does not take registrations ("" when open)
+func screeningClosedReason(movie models.Movies, now time.Time) string {
+	if movie.Screening_capacity <= 0 {
+		return "Registration for this screening is not open"
+	}
+	if movie.Screening_at != 0 && !now.Before(movie.Screening_at.Time()) {
+		return "This screening has already started"
+	}
+	return ""
+}
+
+// reserveScreeningSeat takes one seat if the screening still has room
+func reserveScreeningSeat(ctx context.Context, movieID primitive.ObjectID) (bool, error) {
+	result, err := MoviesCollectionInit().UpdateOne(ctx, bson.M{
+		"_id": movieID,
+		"$expr": bson.M{"$lt": bson.A{
+			bson.M{"$ifNull": bson.A{"$screening_seats_taken", 0}},
+			"$screening_capacity",
+		}},
+	}, bson.M{"$inc": bson.M{"screening_seats_taken": 1}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func releaseScreeningSeat(ctx context.Context, movieID primitive.ObjectID) error {
+	_, err := MoviesCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": movieID, "screening_seats_taken": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"screening_seats_taken": -1}},
+	)
+	return err
+}
+
+// promoteScreeningWaitlist moves the oldest waitlisted registrations into free seats
+func promoteScreeningWaitlist(ctx context.Context, movieID primitive.ObjectID) ([]models.ScreeningRegistration, error) {
+	promoted := []models.ScreeningRegistration{}
+	for {
+		reserved, err := reserveScreeningSeat(ctx, movieID)
+		if err != nil || !reserved {
+			return promoted, err
+		}
+
+		now := primitive.NewDateTimeFromTime(time.Now())
+		opts := options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+			SetReturnDocument(options.After)
+		var registration models.ScreeningRegistration
+		err = ScreeningRegistrationCollectionInit().FindOneAndUpdate(ctx,
+			bson.M{"movie_id": movieID, "status": models.ScreeningWaitlisted, "active": true},
+			bson.M{"$set": bson.M{"status": models.ScreeningConfirmed, "promoted_at": now, "updated_at": now}},
+			opts,
+		).Decode(&registration)
+		if err != nil {
+			// nobody is waiting (or the lookup failed): give the seat back
+			if releaseErr := releaseScreeningSeat(ctx, movieID); releaseErr != nil {
+				log.Println("[SCREENING] seat release failed:", releaseErr)
+			}
+			if err == mongo.ErrNoDocuments {
+				err = nil
+			}
+			return promoted, err
+		}
+		log.Printf("[SCREENING] %s promoted from the waitlist of %s", registration.ID.Hex(), movieID.Hex())
+		promoted = append(promoted, registration)
+	}
+}
+
+// setScreeningCapacity changes the number of seats and gives any new ones to
+// the waitlist. Every capacity change goes through here, movie updates included
+func setScreeningCapacity(ctx context.Context, movieID primitive.ObjectID, capacity int) ([]models.ScreeningRegistration, error) {
+	_, err := MoviesCollectionInit().UpdateOne(ctx, bson.M{"_id": movieID}, bson.M{"$set": bson.M{
+		"screening_capacity": capacity,
+		"updated_at":         primitive.NewDateTimeFromTime(time.Now()),
+	}})
+	if err != nil {
+		return nil, err
+	}
+	promoted, err := promoteScreeningWaitlist(ctx, movieID)
+	if err != nil {
+		log.Println("[SCREENING] waitlist promotion failed:", err)
+	}
+	return promoted, nil
+}
+
+// cancelScreeningRegistration cancels an active registration, freeing its seat for the waitlist
+func cancelScreeningRegistration(ctx context.Context, movieID, registrationID primitive.ObjectID) (models.ScreeningRegistration, []models.ScreeningRegistration, error) {
+	var registration models.ScreeningRegistration
+	now := primitive.NewDateTimeFromTime(time.Now())
+	err := ScreeningRegistrationCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": registrationID, "movie_id": movieID, "active": true, "checked_in_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": models.ScreeningCancelled, "active": false, "cancelled_at": now, "updated_at": now}},
+	).Decode(&registration) // the document before the update
+	if err == mongo.ErrNoDocuments {
+		var existing models.ScreeningRegistration
+		if findErr := ScreeningRegistrationCollectionInit().FindOne(ctx, bson.M{"_id": registrationID, "movie_id": movieID}).Decode(&existing); findErr == nil && existing.Active {
+			return existing, nil, errScreeningCheckedIn
+		}
+		return registration, nil, err
+	}
+	if err != nil {
+		return registration, nil, err
+	}
+
+	var promoted []models.ScreeningRegistration
+	if registration.Status == models.ScreeningConfirmed {
+		if err := releaseScreeningSeat(ctx, movieID); err != nil {
+			return registration, nil, err
+		}
+		if promoted, err = promoteScreeningWaitlist(ctx, movieID); err != nil {
+			log.Println("[SCREENING] waitlist promotion failed:", err)
+		}
+	}
+	registration.Status = models.ScreeningCancelled
+	registration.Active = false
+	registration.Cancelled_at = now
+	return registration, promoted, nil
+}
+
+// waitlistPosition is the 1-based place of a waitlisted registration
+func waitlistPosition(ctx context.Context, registration models.ScreeningRegistration) (int64, error) {
+	ahead, err := ScreeningRegistrationCollectionInit().CountDocuments(ctx, bson.M{
+		"movie_id":   registration.Movie_id,
+		"status":     models.ScreeningWaitlisted,
+		"active":     true,
+		"created_at": bson.M{"$lt": registration.Created_at},
+	})
+	return ahead + 1, err
+}
+
+// screeningRegistrationResponse is what the registrant sees of their registration
+func screeningRegistrationResponse(ctx context.Context, registration models.ScreeningRegistration) fiber.Map {
+	token := helpers.SignScreeningPass(registration.ID, registration.Movie_id)
+	response := fiber.Map{
+		"registration": registration,
+		"pass_token":   token,
+	}
+	switch registration.Status {
+	case models.ScreeningConfirmed:
+		response["pass_url"] = helpers.ScreeningPassURL(token)
+	case models.ScreeningWaitlisted:
+		if position, err := waitlistPosition(ctx, registration); err == nil {
+			response["waitlist_position"] = position
+		}
+	}
+	return response
+}
+
+// GetScreening - Seats left and waitlist size of a movie's advanced screening
+func GetScreening(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movie, err := findMovieParam(ctx, c)
+	if err != nil {
+		return movieParamError(c, err)
+	}
+
+	waitlisted, err := ScreeningRegistrationCollectionInit().CountDocuments(ctx, bson.M{
+		"movie_id": movie.ID, "status": models.ScreeningWaitlisted, "active": true,
+	})
+	if err != nil {
+		log.Println("Count waitlist error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch screening"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Screening fetched successfully",
+		"screening": models.ScreeningAvailability{
+			Movie_id:   movie.ID,
+			Cinema:     movie.Location_cinema,
+			Date:       movie.Advanced_screening_date,
+			Time:       movie.Screening_time,
+			Capacity:   movie.Screening_capacity,
+			Seats_left: max(0, movie.Screening_capacity-movie.Screening_seats_taken),
+			Waitlisted: waitlisted,
+			Open:       screeningClosedReason(movie, time.Now().In(utils.LocationAsiaManila)) == "",
+		},
+	})
+}
+
+// RegisterForScreening - Take a seat, or a waitlist spot when the screening is full
+func RegisterForScreening(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movie, err := findMovieParam(ctx, c)
+	if err != nil {
+		return movieParamError(c, err)
+	}
+	if reason := screeningClosedReason(movie, time.Now().In(utils.LocationAsiaManila)); reason != "" {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": reason})
+	}
+
+	var input models.ScreeningRegistrationInput
+	if err := c.Bind().JSON(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	name := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(input.Name))
+	email := helpers.NormalizeEmail(input.Email)
+	phone := helpers.NormalizePHMobile(input.Phone)
+	switch {
+	case name == "" || len(name) > 100:
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Name is required (max 100 characters)"})
+	case email == "":
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid email address"})
+	case phone == "":
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid mobile number"})
+	}
+
+	collection := ScreeningRegistrationCollectionInit()
+	var existing models.ScreeningRegistration
+	err = collection.FindOne(ctx, bson.M{
+		"movie_id": movie.ID,
+		"active":   true,
+		"$or":      bson.A{bson.M{"email": email}, bson.M{"phone": phone}},
+	}).Decode(&existing)
+	if err == nil {
+		field := "email"
+		if existing.Phone == phone {
+			field = "mobile number"
+		}
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This " + field + " is already registered for this screening"})
+	}
+	if err != mongo.ErrNoDocuments {
+		log.Println("Find registration error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to register"})
+	}
+
+	reserved, err := reserveScreeningSeat(ctx, movie.ID)
+	if err != nil {
+		log.Println("Reserve seat error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to register"})
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	registration := models.ScreeningRegistration{
+		ID:         primitive.NewObjectID(),
+		Movie_id:   movie.ID,
+		Name:       name,
+		Email:      email,
+		Phone:      phone,
+		Status:     models.ScreeningWaitlisted,
+		Active:     true,
+		Created_at: now,
+		Updated_at: now,
+	}
+	if reserved {
+		registration.Status = models.ScreeningConfirmed
+	}
+
+	if _, err := collection.InsertOne(ctx, registration); err != nil {
+		if reserved {
+			if releaseErr := releaseScreeningSeat(ctx, movie.ID); releaseErr != nil {
+				log.Println("[SCREENING] seat release failed:", releaseErr)
+			}
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This email or mobile number is already registered for this screening"})
+		}
+		log.Println("Insert registration error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to register"})
+	}
+
+	response := screeningRegistrationResponse(ctx, registration)
+	response["message"] = "Seat confirmed"
+	if !reserved {
+		response["message"] = "Screening is full, you are on the waitlist"
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+// CancelScreeningRegistration - Registrant cancels with their pass token
+func CancelScreeningRegistration(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	registrationID, movieID, err := helpers.VerifyScreeningPass(body.Token)
+	if err != nil || movieID.Hex() != c.Params("id") {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid pass"})
+	}
+
+	return sendScreeningCancellation(ctx, c, movieID, registrationID)
+}
+
+func sendScreeningCancellation(ctx context.Context, c fiber.Ctx, movieID, registrationID primitive.ObjectID) error {
+	registration, promoted, err := cancelScreeningRegistration(ctx, movieID, registrationID)
+	switch {
+	case err == errScreeningCheckedIn:
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This pass has already been used"})
+	case err == mongo.ErrNoDocuments:
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Registration not found or already cancelled"})
+	case err != nil:
+		log.Println("Cancel registration error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cancel registration"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message":      "Registration cancelled",
+		"registration": registration,
+		"promoted":     len(promoted),
+	})
+}
+
+// GetScreeningPass - QR code PNG of a confirmed registration's pass
+func GetScreeningPass(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token := c.Params("token")
+	registrationID, movieID, err := helpers.VerifyScreeningPass(token)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Pass not found"})
+	}
+
+	var registration models.ScreeningRegistration
+	err = ScreeningRegistrationCollectionInit().FindOne(ctx, bson.M{
+		"_id": registrationID, "movie_id": movieID, "status": models.ScreeningConfirmed, "active": true,
+	}).Decode(&registration)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Pass not found"})
+		}
+		log.Println("Find registration error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch pass"})
+	}
+
+	png, err := helpers.ScreeningPassQR(token, 512)
+	if err != nil {
+		log.Println("QR encode error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to render pass"})
+	}
+	// the pass stops working when cancelled, so never let it be cached
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(png)
+}
+
+// UpdateScreeningCapacity - Staff set the number of seats; new seats go to the waitlist first
+func UpdateScreeningCapacity(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movie, err := findMovieParam(ctx, c)
+	if err != nil {
+		return movieParamError(c, err)
+	}
+
+	var body struct {
+		Capacity *int `json:"capacity"`
+	}
+	if err := c.Bind().JSON(&body); err != nil || body.Capacity == nil || *body.Capacity < 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "capacity must be a number of seats (0 closes registration)"})
+	}
+
+	promoted, err := setScreeningCapacity(ctx, movie.ID, *body.Capacity)
+	if err != nil {
+		log.Println("Update capacity error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update capacity"})
+	}
+
+	response := fiber.Map{
+		"message":  "Screening capacity updated",
+		"capacity": *body.Capacity,
+		"promoted": len(promoted),
+	}
+	if *body.Capacity < movie.Screening_seats_taken {
+		// confirmed seats are never revoked; the screening just stops taking new ones
+		response["over_capacity"] = movie.Screening_seats_taken - *body.Capacity
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// GetScreeningRegistrations - Staff list of registrations, optionally ?status=confirmed|waitlisted|cancelled
+func GetScreeningRegistrations(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Movie ID"})
+	}
+
+	filter := bson.M{"movie_id": objID}
+	switch status := c.Query("status"); status {
+	case "":
+	case models.ScreeningConfirmed, models.ScreeningWaitlisted, models.ScreeningCancelled:
+		filter["status"] = status
+	default:
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status"})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := ScreeningRegistrationCollectionInit().Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Find registrations error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch registrations"})
+	}
+	defer cursor.Close(ctx)
+
+	registrations := []models.ScreeningRegistration{}
+	if err = cursor.All(ctx, &registrations); err != nil {
+		log.Println("Cursor decode error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse registrations"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message":       "Registrations fetched successfully",
+		"registrations": registrations,
+	})
+}
+
+// DeleteScreeningRegistration - Staff cancel a registration
+func DeleteScreeningRegistration(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	movieID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Movie ID"})
+	}
+	registrationID, err := primitive.ObjectIDFromHex(c.Params("registrationId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Registration ID"})
+	}
+
+	return sendScreeningCancellation(ctx, c, movieID, registrationID)
+}
+
+// CheckInScreening - Staff validate a scanned pass at the door and mark it used
+func CheckInScreening(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	registrationID, movieID, err := helpers.VerifyScreeningPass(body.Token)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Invalid pass"})
+	}
+	if movieID.Hex() != c.Params("id") {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This pass is for a different screening"})
+	}
+
+	_, staff := revisionAuthor(c)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var registration models.ScreeningRegistration
+	err = ScreeningRegistrationCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":           registrationID,
+			"movie_id":      movieID,
+			"status":        models.ScreeningConfirmed,
+			"active":        true,
+			"checked_in_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"checked_in_at": now, "checked_in_by": staff, "updated_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&registration)
+	if err == nil {
+		return c.Status(http.StatusOK).JSON(fiber.Map{
+			"message":      "Checked in",
+			"registration": registration,
+		})
+	}
+	if err != mongo.ErrNoDocuments {
+		log.Println("Check-in error:", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check in"})
+	}
+
+	// explain why the pass was refused
+	if err := ScreeningRegistrationCollectionInit().FindOne(ctx, bson.M{"_id": registrationID, "movie_id": movieID}).Decode(&registration); err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Registration not found"})
+	}
+	switch {
+	case registration.Status == models.ScreeningCancelled:
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This registration was cancelled", "registration": registration})
+	case registration.Status == models.ScreeningWaitlisted:
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This registration is still on the waitlist", "registration": registration})
+	}
+	return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "This pass has already been used", "registration": registration})
+}
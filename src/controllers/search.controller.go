@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"magic-server-2026/src/search"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/*
+   Search Controller
+   -----------------------------------
+   GET /api/v1/search?q=&type=news,show&page=1&limit=20
+   Ranked results across news, shows, movies, music and videos
+   with type facets, <mark> highlighting and typo tolerance.
+   -----------------------------------
+*/
+
+const (
+	searchMaxQueryLength = 200
+	searchDefaultLimit   = 20
+	searchMaxLimit       = 50
+)
+
+// SearchContent - Full-text search across all content collections
+func SearchContent(c fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return errorResponse(c, http.StatusBadRequest, "Missing search query")
+	}
+	if len(q) > searchMaxQueryLength {
+		return errorResponse(c, http.StatusBadRequest, "Search query is too long")
+	}
+
+	types := make(map[string]bool)
+	if raw := c.Query("type"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			valid := false
+			for _, known := range search.Types {
+				if t == known {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return errorResponse(c, http.StatusBadRequest, "Unknown content type: "+t)
+			}
+			types[t] = true
+		}
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(searchDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = searchDefaultLimit
+	}
+	limit = min(limit, searchMaxLimit)
+
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	result := search.Search(q, types, limit, (page-1)*limit)
+
+	return jsonResponse(c, http.StatusOK, "Search completed successfully", fiber.Map{
+		"query":   result.Query,
+		"total":   result.Total,
+		"page":    page,
+		"limit":   limit,
+		"facets":  result.Facets,
+		"results": result.Results,
+	})
+}
@@ -0,0 +1,508 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"log"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/imaging"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   SEO Metadata (public, no RSP token)
+   -----------------------------------
+   1. Sitemap index     /sitemap.xml
+   2. Section sitemaps  /sitemaps/:section.xml (pages, news, shows, movies, videos)
+   3. Link previews     /meta?url=/news/music/some-title[&format=html]
+      OpenGraph + Twitter card tags and schema.org JSON-LD
+      (RadioStation, NewsArticle, BroadcastEvent, Movie)
+   -----------------------------------
+   Crawlers cannot run the SPA; the frontend proxy asks /meta for bots.
+*/
+
+const (
+	sitemapNS      = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapImageNS = "http://www.google.com/schemas/sitemap-image/1.1"
+	sitemapMaxURLs = 50000
+	sitemapDate    = "2006-01-02T15:04:05Z07:00"
+)
+
+var sitemapSections = []string{"pages", "news", "shows", "movies", "videos"}
+
+// staticPages are the SPA routes that exist without any content
+var staticPages = []struct {
+	Path       string
+	ChangeFreq string
+	Priority   string
+}{
+	{"/", "daily", "1.0"},
+	{"/news", "hourly", "0.9"},
+	{"/shows", "weekly", "0.8"},
+	{"/movies", "daily", "0.8"},
+	{"/videos", "daily", "0.7"},
+	{"/music", "weekly", "0.7"},
+}
+
+// sitemapCollection maps a content section to its collection and visibility filter
+func sitemapCollection(section string) (*mongo.Collection, bson.M) {
+	switch section {
+	case "news":
+		return NewsCollectionInit(), bson.M{"status": "approved"}
+	case "shows":
+		return ShowsCollectionInit(), bson.M{}
+	case "movies":
+		return MoviesCollectionInit(), bson.M{}
+	case "videos":
+		return MagicVideosCollectionInit(), bson.M{}
+	}
+	return nil, nil
+}
+
+// sectionUpdated is the newest updated_at in a section (zero if empty)
+func sectionUpdated(ctx context.Context, section string) time.Time {
+	collection, filter := sitemapCollection(section)
+	if collection == nil {
+		return time.Time{}
+	}
+
+	var latest struct {
+		Updated_at primitive.DateTime `bson:"updated_at"`
+	}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetProjection(bson.M{"updated_at": 1})
+	if err := collection.FindOne(ctx, filter, opts).Decode(&latest); err != nil {
+		return time.Time{}
+	}
+	return latest.Updated_at.Time().UTC().Truncate(time.Second)
+}
+
+func sitemapLastMod(t time.Time) string {
+	if t.IsZero() || t.Unix() <= 0 {
+		return ""
+	}
+	return t.UTC().Format(sitemapDate)
+}
+
+func sitemapImages(images ...string) []models.SitemapImage {
+	var out []models.SitemapImage
+	for _, image := range images {
+		if image = helpers.PublicImageURL(image); image != "" {
+			out = append(out, models.SitemapImage{Loc: image})
+		}
+	}
+	return out
+}
+
+func sendXML(c fiber.Ctx, v interface{}, lastModified time.Time) error {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Println("Sitemap encode error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build sitemap"})
+	}
+	if lastModified.IsZero() {
+		lastModified = time.Unix(0, 0)
+	}
+	return sendFeed(c, append([]byte(xml.Header), body...), "application/xml; charset=utf-8", lastModified)
+}
+
+// GetSitemapIndex - Sitemap index pointing at one sitemap per section
+func GetSitemapIndex(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	index := models.SitemapIndex{XMLNS: sitemapNS}
+	var newest time.Time
+	for _, section := range sitemapSections {
+		updated := sectionUpdated(ctx, section)
+		if updated.After(newest) {
+			newest = updated
+		}
+		index.Sitemaps = append(index.Sitemaps, models.SitemapEntry{
+			Loc:     utils.ServerOrigin() + "/sitemaps/" + section + ".xml",
+			LastMod: sitemapLastMod(updated),
+		})
+	}
+
+	return sendXML(c, index, newest)
+}
+
+// GetSectionSitemap - URL set for one section
+func GetSectionSitemap(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	section := c.Params("section")
+	set := models.URLSet{XMLNS: sitemapNS, ImageNS: sitemapImageNS, URLs: []models.SitemapURL{}}
+	var newest time.Time
+	track := func(t time.Time) string {
+		if t.After(newest) {
+			newest = t.UTC().Truncate(time.Second)
+		}
+		return sitemapLastMod(t)
+	}
+
+	collection, filter := sitemapCollection(section)
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}).SetLimit(sitemapMaxURLs)
+
+	var err error
+	switch section {
+	case "pages":
+		for _, page := range staticPages {
+			set.URLs = append(set.URLs, models.SitemapURL{
+				Loc:        utils.SiteOrigin() + page.Path,
+				ChangeFreq: page.ChangeFreq,
+				Priority:   page.Priority,
+			})
+		}
+		for _, category := range models.NewsCategories {
+			set.URLs = append(set.URLs, models.SitemapURL{
+				Loc:        utils.SiteOrigin() + "/news/" + url.PathEscape(strings.ToLower(category)),
+				ChangeFreq: "daily",
+				Priority:   "0.6",
+			})
+		}
+
+	case "news":
+		var items []models.News
+		if err = findSitemapItems(ctx, collection, filter, opts, &items); err == nil {
+			for _, news := range items {
+				set.URLs = append(set.URLs, models.SitemapURL{
+					Loc:     helpers.NewsURL(news),
+					LastMod: track(news.Updated_at.Time()),
+					Images:  sitemapImages(news.News_Image),
+				})
+			}
+		}
+
+	case "shows":
+		var items []models.Shows
+		if err = findSitemapItems(ctx, collection, filter, opts, &items); err == nil {
+			for _, show := range items {
+				set.URLs = append(set.URLs, models.SitemapURL{
+					Loc:        helpers.ShowURL(show),
+					LastMod:    track(show.Updated_at.Time()),
+					ChangeFreq: "weekly",
+					Images:     sitemapImages(show.Show_Image),
+				})
+			}
+		}
+
+	case "movies":
+		var items []models.Movies
+		if err = findSitemapItems(ctx, collection, filter, opts, &items); err == nil {
+			for _, movie := range items {
+				set.URLs = append(set.URLs, models.SitemapURL{
+					Loc:     helpers.MovieURL(movie),
+					LastMod: track(movie.Updated_at.Time()),
+					Images:  sitemapImages(movie.Movie_Image),
+				})
+			}
+		}
+
+	case "videos":
+		var items []models.MagicVideos
+		if err = findSitemapItems(ctx, collection, filter, opts, &items); err == nil {
+			for _, video := range items {
+				set.URLs = append(set.URLs, models.SitemapURL{
+					Loc:     helpers.VideoURL(video),
+					LastMod: track(video.Updated_at.Time()),
+					Images:  sitemapImages(video.Thumbnail),
+				})
+			}
+		}
+
+	default:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Sitemap not found"})
+	}
+
+	if err != nil {
+		log.Println("Sitemap query error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build sitemap"})
+	}
+
+	return sendXML(c, set, newest)
+}
+
+func findSitemapItems(ctx context.Context, collection *mongo.Collection, filter bson.M, opts *options.FindOptions, out interface{}) error {
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, out)
+}
+
+// ---------------------------
+// Link previews
+// ---------------------------
+
+// newPageMeta fills the OpenGraph and Twitter tags shared by every page
+func newPageMeta(ogType, title, description, canonical, image string) models.PageMeta {
+	description = helpers.Summarize(description, 200)
+	meta := models.PageMeta{
+		Title:       title,
+		Description: description,
+		Canonical:   canonical,
+		Image:       image,
+		JSONLD:      []interface{}{},
+	}
+
+	card := "summary"
+	if image != "" {
+		card = "summary_large_image"
+	}
+	meta.Tags = []models.MetaTag{
+		{Property: "og:site_name", Content: helpers.StationName},
+		{Property: "og:type", Content: ogType},
+		{Property: "og:title", Content: title},
+		{Property: "og:description", Content: description},
+		{Property: "og:url", Content: canonical},
+		{Property: "og:locale", Content: "en_PH"},
+		{Name: "twitter:card", Content: card},
+		{Name: "twitter:title", Content: title},
+		{Name: "twitter:description", Content: description},
+	}
+	if image != "" {
+		meta.Tags = append(meta.Tags,
+			models.MetaTag{Property: "og:image", Content: image},
+			models.MetaTag{Name: "twitter:image", Content: image},
+		)
+		if strings.HasPrefix(image, utils.ServerOrigin()+"/share/") {
+			meta.Tags = append(meta.Tags,
+				models.MetaTag{Property: "og:image:width", Content: strconv.Itoa(imaging.CardWidth)},
+				models.MetaTag{Property: "og:image:height", Content: strconv.Itoa(imaging.CardHeight)},
+			)
+		}
+	}
+	if site := utils.GetEnv("TWITTER_SITE"); site != "" {
+		meta.Tags = append(meta.Tags, models.MetaTag{Name: "twitter:site", Content: site})
+	}
+	return meta
+}
+
+func stationPageMeta(title, canonical string) models.PageMeta {
+	meta := newPageMeta("website", title, helpers.StationDescription, canonical, helpers.StationLogoURL())
+	meta.JSONLD = append(meta.JSONLD, helpers.RadioStationLD())
+	return meta
+}
+
+// resolvePageMeta maps a public SPA path to its preview data. updated is used for caching.
+func resolvePageMeta(ctx context.Context, path string) (meta models.PageMeta, updated time.Time, err error) {
+	var segments []string
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	canonical := utils.SiteOrigin() + "/" + strings.Join(segments, "/")
+
+	if len(segments) == 0 {
+		return stationPageMeta(helpers.StationName, utils.SiteOrigin()+"/"), time.Time{}, nil
+	}
+
+	section := strings.ToLower(segments[0])
+	if len(segments) == 1 {
+		for _, page := range staticPages {
+			if page.Path == "/"+section {
+				title := strings.ToUpper(section[:1]) + section[1:] + " | " + helpers.StationName
+				return stationPageMeta(title, canonical), time.Time{}, nil
+			}
+		}
+		return meta, updated, mongo.ErrNoDocuments
+	}
+
+	switch {
+	case section == "news" && len(segments) == 2:
+		category, ok := findFeedCategory(segments[1])
+		if !ok {
+			return meta, updated, mongo.ErrNoDocuments
+		}
+		return stationPageMeta(category+" News | "+helpers.StationName, canonical), time.Time{}, nil
+
+	case section == "news" && len(segments) == 3:
+		slug, _ := url.PathUnescape(segments[2])
+		var news models.News
+		err = NewsCollectionInit().FindOne(ctx, bson.M{
+			"status":           "approved",
+			"normalized_title": helpers.NormalizeName(strings.ReplaceAll(slug, "-", " ")),
+			"category":         bson.M{"$regex": "^" + regexp.QuoteMeta(segments[1]) + "$", "$options": "i"},
+		}).Decode(&news)
+		if err != nil {
+			return meta, updated, err
+		}
+		meta = newPageMeta("article", news.Title, helpers.ContentPlainText(news.Content), helpers.NewsURL(news), helpers.ShareCardURL("news", news.ID.Hex()))
+		meta.Tags = append(meta.Tags,
+			models.MetaTag{Property: "article:published_time", Content: news.Created_at.Time().UTC().Format(time.RFC3339)},
+			models.MetaTag{Property: "article:modified_time", Content: news.Updated_at.Time().UTC().Format(time.RFC3339)},
+			models.MetaTag{Property: "article:section", Content: news.Category},
+		)
+		for _, tag := range news.Tags {
+			meta.Tags = append(meta.Tags, models.MetaTag{Property: "article:tag", Content: tag})
+		}
+		meta.JSONLD = append(meta.JSONLD, helpers.NewsArticleLD(news))
+		return meta, news.Updated_at.Time(), nil
+
+	case section == "shows" && len(segments) == 2:
+		name, _ := url.PathUnescape(segments[1])
+		var show models.Shows
+		err = ShowsCollectionInit().FindOne(ctx, bson.M{
+			"$expr": bson.M{
+				"$eq": []interface{}{
+					bson.M{"$replaceAll": bson.M{"input": "$show_name", "find": " ", "replacement": ""}},
+					strings.ReplaceAll(name, " ", ""),
+				},
+			},
+		}).Decode(&show)
+		if err != nil {
+			return meta, updated, err
+		}
+		title := helpers.Summarize(derefShowField(show.Show_name), 100)
+		if host := derefShowField(show.Show_host); host != "" {
+			title += " with " + host
+		}
+		meta = newPageMeta("website", title, helpers.ContentPlainText(show.Show_desc), helpers.ShowURL(show), helpers.ShareCardURL("shows", show.ID.Hex()))
+		meta.JSONLD = append(meta.JSONLD, helpers.BroadcastEventLD(show, time.Now()))
+		// the next airing changes hourly, so do not let the page look unchanged for long
+		return meta, time.Time{}, nil
+
+	case section == "movies" && len(segments) == 2:
+		objID, idErr := primitive.ObjectIDFromHex(segments[1])
+		if idErr != nil {
+			return meta, updated, mongo.ErrNoDocuments
+		}
+		var movie models.Movies
+		if err = MoviesCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&movie); err != nil {
+			return meta, updated, err
+		}
+		meta = newPageMeta("video.movie", movie.Title, helpers.ContentPlainText(movie.Content), helpers.MovieURL(movie), helpers.PublicImageURL(movie.Movie_Image))
+		if movie.Directed_by != "" {
+			meta.Tags = append(meta.Tags, models.MetaTag{Property: "video:director", Content: movie.Directed_by})
+		}
+		if released, ok := helpers.MovieReleaseDay(movie); ok {
+			meta.Tags = append(meta.Tags, models.MetaTag{Property: "video:release_date", Content: released.Format("2006-01-02")})
+		}
+		meta.JSONLD = append(meta.JSONLD, helpers.MovieLD(movie))
+		return meta, movie.Updated_at.Time(), nil
+
+	case section == "videos" && len(segments) == 2:
+		objID, idErr := primitive.ObjectIDFromHex(segments[1])
+		if idErr != nil {
+			return meta, updated, mongo.ErrNoDocuments
+		}
+		var video models.MagicVideos
+		if err = MagicVideosCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&video); err != nil {
+			return meta, updated, err
+		}
+		meta = newPageMeta("video.other", video.Title, strings.Join(video.Desc, " "), helpers.VideoURL(video), helpers.PublicImageURL(video.Thumbnail))
+		return meta, video.Updated_at.Time(), nil
+	}
+
+	return meta, updated, mongo.ErrNoDocuments
+}
+
+func derefShowField(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// renderPageMetaHTML is a minimal document for crawlers: head tags plus a link to the real page
+func renderPageMetaHTML(meta models.PageMeta) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + html.EscapeString(meta.Title) + "</title>\n")
+	b.WriteString("<meta name=\"description\" content=\"" + html.EscapeString(meta.Description) + "\">\n")
+	b.WriteString("<link rel=\"canonical\" href=\"" + html.EscapeString(meta.Canonical) + "\">\n")
+	for _, tag := range meta.Tags {
+		attr, key := "name", tag.Name
+		if tag.Property != "" {
+			attr, key = "property", tag.Property
+		}
+		b.WriteString("<meta " + attr + "=\"" + html.EscapeString(key) + "\" content=\"" + html.EscapeString(tag.Content) + "\">\n")
+	}
+	for _, ld := range meta.JSONLD {
+		// json.Marshal escapes <, > and &, so the script cannot be closed early
+		body, err := json.Marshal(ld)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("<script type=\"application/ld+json\">" + string(body) + "</script>\n")
+	}
+	b.WriteString("</head>\n<body>\n<a href=\"" + html.EscapeString(meta.Canonical) + "\">" + html.EscapeString(meta.Title) + "</a>\n</body>\n</html>\n")
+	return []byte(b.String()), nil
+}
+
+// GetPageMeta - OpenGraph, Twitter card and JSON-LD data for a public page (?url=, ?format=html)
+func GetPageMeta(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	raw := strings.TrimSpace(c.Query("url"))
+	if raw == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "url is required"})
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid url"})
+	}
+	if parsed.Host != "" && !strings.EqualFold(parsed.Scheme+"://"+parsed.Host, utils.SiteOrigin()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "url must be a page of this site"})
+	}
+
+	meta, updated, err := resolvePageMeta(ctx, parsed.EscapedPath())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Page not found"})
+		}
+		log.Println("Page meta error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build page metadata"})
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	updated = updated.UTC().Truncate(time.Second)
+
+	if c.Query("format") == "html" {
+		body, err := renderPageMetaHTML(meta)
+		if err != nil {
+			log.Println("Page meta encode error:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build page metadata"})
+		}
+		return sendFeed(c, body, "text/html; charset=utf-8", updated)
+	}
+
+	body, err := json.Marshal(fiber.Map{
+		"message": "Page metadata fetched successfully",
+		"meta":    meta,
+	})
+	if err != nil {
+		log.Println("Page meta encode error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build page metadata"})
+	}
+	return sendFeed(c, body, "application/json; charset=utf-8", updated)
+}
+
+// GetRobots - robots.txt for the API origin. The sitemaps list frontend URLs, so the
+// frontend's own robots.txt must also point at SERVER_ORIGIN/sitemap.xml.
+func GetRobots(c fiber.Ctx) error {
+	c.Set("Content-Type", "text/plain; charset=utf-8")
+	return c.Status(http.StatusOK).SendString("User-agent: *\nAllow: /feeds/\nAllow: /media/\nAllow: /sitemaps/\nDisallow: /api/\n\nSitemap: " + utils.ServerOrigin() + "/sitemap.xml\n")
+}
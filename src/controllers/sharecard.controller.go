@@ -0,0 +1,350 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"magic-server-2026/src/imaging"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+   Share Cards (public, no RSP token)
+   -----------------------------------
+   GET /share/music/:id.png   chart position card
+   GET /share/news/:id.png    article card
+   GET /share/shows/:id.png   show card
+   (.webp works for all of them)
+   -----------------------------------
+   Rendered once per content hash and cached on disk; editing the
+   source document (or a chart move) produces a new card. When the
+   artwork cannot be fetched the card is rendered without it and kept in
+   memory for shareCardRetryAfter only, so the artwork is tried again
+   later without re-rendering on every hit.
+*/
+
+const (
+	ShareCardDir        = "./src/uploads/share-cards"
+	shareCardRetryAfter = 5 * time.Minute
+)
+
+var (
+	// shareCardLocks keeps concurrent requests for the same card from rendering it twice
+	shareCardLocks sync.Map
+	// shareCardFallbacks holds cards rendered without their artwork, by path
+	shareCardFallbacks sync.Map
+)
+
+// shareCardFallback is a card rendered while its artwork could not be loaded
+type shareCardFallback struct {
+	body    []byte
+	expires time.Time
+}
+
+// fallbackShareCard returns the card rendered without artwork for path, while it is fresh
+func fallbackShareCard(path string) ([]byte, bool) {
+	value, ok := shareCardFallbacks.Load(path)
+	if !ok {
+		return nil, false
+	}
+	fallback := value.(shareCardFallback)
+	if time.Now().After(fallback.expires) {
+		shareCardFallbacks.Delete(path)
+		return nil, false
+	}
+	return fallback.body, true
+}
+
+// keepFallbackShareCard remembers a card rendered without artwork and drops expired ones
+func keepFallbackShareCard(path string, body []byte) {
+	now := time.Now()
+	shareCardFallbacks.Range(func(key, value interface{}) bool {
+		if now.After(value.(shareCardFallback).expires) {
+			shareCardFallbacks.Delete(key)
+		}
+		return true
+	})
+	shareCardFallbacks.Store(path, shareCardFallback{body: body, expires: now.Add(shareCardRetryAfter)})
+}
+
+// shareSource is what a card is rendered from
+type shareSource struct {
+	Card    imaging.Card // without artwork
+	Artwork string       // media store filename or https URL
+}
+
+func shareFooter() string {
+	if u, err := url.Parse(utils.SiteOrigin()); err == nil && u.Host != "" {
+		return strings.TrimPrefix(u.Host, "www.")
+	}
+	return ""
+}
+
+func loadShareSource(ctx context.Context, kind string, objID primitive.ObjectID) (*shareSource, error) {
+	source := &shareSource{Card: imaging.Card{Footer: shareFooter()}}
+
+	switch kind {
+	case "music":
+		var music models.Music
+		if err := MusicCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&music); err != nil {
+			return nil, err
+		}
+
+		// chart position within the same chart (music type)
+		filter := bson.M{"votes": bson.M{"$gt": music.Votes}}
+		if music.Music_type != "" {
+			filter["music_type"] = music.Music_type
+		}
+		ahead, err := MusicCollectionInit().CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if music.Music_type != "" {
+			source.Card.Kicker = fmt.Sprintf("#%d · %s chart", ahead+1, music.Music_type)
+		} else {
+			source.Card.Kicker = fmt.Sprintf("#%d on the chart", ahead+1)
+		}
+		source.Card.Title = music.Title
+		source.Card.Subtitle = strings.Join(music.Artist, ", ")
+		source.Artwork = music.Music_image
+
+	case "news":
+		var news models.News
+		if err := NewsCollectionInit().FindOne(ctx, bson.M{"_id": objID, "status": "approved"}).Decode(&news); err != nil {
+			return nil, err
+		}
+		source.Card.Kicker = "News · " + news.Category
+		source.Card.Title = news.Title
+		byline := []string{}
+		if news.Writer != "" {
+			byline = append(byline, "By "+news.Writer)
+		}
+		byline = append(byline, news.Created_at.Time().In(utils.LocationAsiaManila).Format("Jan 2, 2006"))
+		source.Card.Subtitle = strings.Join(byline, " · ")
+		source.Artwork = news.News_Image
+
+	case "shows":
+		var show models.Shows
+		if err := ShowsCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&show); err != nil {
+			return nil, err
+		}
+		var slot []string
+		for _, part := range []string{derefShowField(show.Show_day), derefShowField(show.Show_time)} {
+			if part = strings.TrimSpace(part); part != "" {
+				slot = append(slot, part)
+			}
+		}
+		source.Card.Kicker = strings.Join(slot, " · ")
+		if source.Card.Kicker == "" {
+			source.Card.Kicker = "On air"
+		}
+		source.Card.Title = derefShowField(show.Show_name)
+		if host := derefShowField(show.Show_host); host != "" {
+			source.Card.Subtitle = "with " + host
+		}
+		source.Artwork = show.Show_Image
+
+	default:
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return source, nil
+}
+
+// artworkKey returns the media store key of a bare filename, or "" for URLs and bad names
+func artworkKey(ref string) string {
+	if strings.Contains(ref, "://") {
+		return ""
+	}
+	key, _ := storage.Key(storage.Images, ref)
+	return key
+}
+
+// artworkStamp identifies the artwork version for the cache key without downloading it
+func artworkStamp(ctx context.Context, ref string) string {
+	if key := artworkKey(ref); key != "" {
+		obj, err := storage.Default().Stat(ctx, key)
+		if err != nil {
+			return "missing:" + ref
+		}
+		return fmt.Sprintf("file:%s:%d:%d:%s", ref, obj.Size, obj.ModTime.UnixNano(), obj.ETag)
+	}
+	return "url:" + ref
+}
+
+// loadArtwork returns the decoded artwork (nil if there is none). ok is false when
+// loading failed for a reason that may go away, so the card should not be cached.
+func loadArtwork(ctx context.Context, ref string) (img image.Image, ok bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, true
+	}
+
+	if key := artworkKey(ref); key != "" {
+		// the cache key follows the file, so a bad or missing file is safe to cache
+		data, _, err := storage.ReadAll(ctx, key, imaging.MaxSourceBytes)
+		if err != nil {
+			log.Println("[SHARE] artwork not loaded:", err)
+			return nil, errors.Is(err, storage.ErrNotFound)
+		}
+		img, err := imaging.DecodeImage(data)
+		if err != nil {
+			log.Println("[SHARE] artwork not decoded:", err)
+		}
+		return img, true
+	}
+
+	if strings.HasPrefix(ref, "https://") {
+		img, err := imaging.FetchImage(ctx, ref)
+		if err != nil {
+			log.Println("[SHARE] artwork not fetched:", err)
+			return nil, false
+		}
+		return img, true
+	}
+	return nil, true
+}
+
+func shareCardKey(ctx context.Context, source *shareSource, format string) string {
+	h := sha256.New()
+	for _, part := range []string{
+		imaging.CardLayoutVersion, format,
+		source.Card.Kicker, source.Card.Title, source.Card.Subtitle, source.Card.Footer,
+		artworkStamp(ctx, source.Artwork),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// renderShareCard renders and (when the artwork loaded cleanly) stores a card at path
+func renderShareCard(ctx context.Context, source *shareSource, format, path string) ([]byte, error) {
+	card := source.Card
+	artwork, cacheable := loadArtwork(ctx, source.Artwork)
+	card.Artwork = artwork
+
+	img, err := imaging.RenderCard(card)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format); err != nil {
+		return nil, err
+	}
+	if !cacheable {
+		keepFallbackShareCard(path, buf.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	if err := os.MkdirAll(ShareCardDir, 0o755); err != nil {
+		return nil, err
+	}
+	// a unique temp file per render: a request that took a fresh lock after
+	// shareCardLocks.Delete cannot write into a file that is being renamed
+	tmp, err := os.CreateTemp(ShareCardDir, ".card-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	// drop cards rendered from older versions of the document
+	prefix := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	prefix = prefix[:strings.LastIndex(prefix, "-")+1]
+	if stale, err := filepath.Glob(filepath.Join(ShareCardDir, prefix+"*."+format)); err == nil {
+		for _, old := range stale {
+			if old != path {
+				os.Remove(old)
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// GetShareCard - 1200x630 PNG/WebP share card for a chart entry, article or show
+func GetShareCard(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	kind := c.Params("type")
+	format := strings.ToLower(c.Params("format"))
+	if format != imaging.FormatPNG && format != imaging.FormatWebP {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unsupported image format"})
+	}
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Share card not found"})
+	}
+
+	source, err := loadShareSource(ctx, kind, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Share card not found"})
+		}
+		log.Println("Share card source error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build share card"})
+	}
+
+	// chart moves change a card without touching the document, so the
+	// card file's own age is what Last-Modified reports
+	path := filepath.Join(ShareCardDir, kind+"-"+objID.Hex()+"-"+shareCardKey(ctx, source, format)+"."+format)
+	rendered := time.Now()
+	body, err := os.ReadFile(path)
+	if info, statErr := os.Stat(path); err == nil && statErr == nil {
+		rendered = info.ModTime()
+	}
+	if err != nil {
+		lock, _ := shareCardLocks.LoadOrStore(path, &sync.Mutex{})
+		mu := lock.(*sync.Mutex)
+		mu.Lock()
+		// another request may have rendered it while we waited
+		if body, err = os.ReadFile(path); err != nil {
+			var ok bool
+			if body, ok = fallbackShareCard(path); ok {
+				err = nil
+			} else {
+				body, err = renderShareCard(ctx, source, format, path)
+			}
+		}
+		mu.Unlock()
+		shareCardLocks.Delete(path)
+		if err != nil {
+			log.Println("Share card render error:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to render share card"})
+		}
+	}
+
+	return sendFeed(c, body, imaging.ContentType(format), rendered.UTC().Truncate(time.Second))
+}
@@ -0,0 +1,425 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Shoutbox Bookings (staff only)
+   -----------------------------------
+   1. List by status / date        GET   /shoutbox-bookings?status=&from=&to=&by=event|air
+   2. Airing calendar              GET   /shoutbox-bookings/calendar?from=&to=
+   3. Get a booking                GET   /shoutbox-bookings/:id
+   4. Move through the lifecycle   PATCH /shoutbox-bookings/:id/status
+   -----------------------------------
+   received -> reviewing -> scheduled -> aired, and declined from any
+   open step. Scheduling assigns a show and the date it airs on; days with
+   more shoutouts than the limits below are reported as overbooked.
+   -----------------------------------
+   PATH: /api/v1/shoutbox-bookings
+*/
+
+const (
+	shoutboxShowLimit    = 3  // shoutouts read in one show airing
+	shoutboxDayLimit     = 8  // shoutouts across all shows in one day
+	shoutboxCalendarDays = 92 // longest calendar range
+	shoutboxDefaultLimit = 20
+	shoutboxMaxLimit     = 100
+)
+
+// shoutboxTransitions lists the statuses each status can move to
+var shoutboxTransitions = map[string][]string{
+	models.ShoutboxReceived:  {models.ShoutboxReviewing, models.ShoutboxDeclined},
+	models.ShoutboxReviewing: {models.ShoutboxScheduled, models.ShoutboxDeclined},
+	models.ShoutboxScheduled: {models.ShoutboxScheduled, models.ShoutboxReviewing, models.ShoutboxAired, models.ShoutboxDeclined},
+	models.ShoutboxDeclined:  {models.ShoutboxReviewing},
+}
+
+func canMoveShoutbox(from, to string) bool {
+	for _, next := range shoutboxTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// shoutboxStatus treats bookings saved before the lifecycle existed as received
+func shoutboxStatus(booking models.RequestShoutbox) string {
+	if booking.Status == "" {
+		return models.ShoutboxReceived
+	}
+	return booking.Status
+}
+
+// parseDayParam reads a YYYY-MM-DD query value as the start of that Manila day
+func parseDayParam(c fiber.Ctx, key string, fallback time.Time) (time.Time, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, true
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, utils.LocationAsiaManila)
+	return day, err == nil
+}
+
+// BackfillShoutboxBookings gives older requests a status and parses their event date
+func BackfillShoutboxBookings(ctx context.Context) (updated int, err error) {
+	collection := ShoutboxMailer()
+	cursor, err := collection.Find(ctx, bson.M{"status": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var booking models.RequestShoutbox
+		if err := cursor.Decode(&booking); err != nil {
+			return updated, err
+		}
+		set := bson.M{"status": models.ShoutboxReceived}
+		if eventAt, _, ok := helpers.ParseEventDate(booking.Event_date); ok {
+			set["event_at"] = primitive.NewDateTimeFromTime(eventAt)
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": booking.ID, "status": bson.M{"$exists": false}}, bson.M{"$set": set}); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, cursor.Err()
+}
+
+// InitShoutboxBookings runs the booking backfill once at startup
+func InitShoutboxBookings() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	updated, err := BackfillShoutboxBookings(ctx)
+	if err != nil {
+		log.Println("[SHOUTBOX] booking backfill failed:", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("[SHOUTBOX] %d older requests moved into the booking lifecycle", updated)
+	}
+}
+
+// GetShoutboxBookings - Bookings filtered by status and a date range on the event or airing date
+func GetShoutboxBookings(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		statuses := bson.A{}
+		for _, s := range strings.Split(status, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				statuses = append(statuses, s)
+			}
+		}
+		filter["status"] = bson.M{"$in": statuses}
+	}
+
+	field := "event_at"
+	switch c.Query("by", "event") {
+	case "event":
+	case "air":
+		field = "air_at"
+	default:
+		return errorResponse(c, http.StatusBadRequest, "by must be event or air")
+	}
+	from, okFrom := parseDayParam(c, "from", time.Time{})
+	to, okTo := parseDayParam(c, "to", time.Time{})
+	if !okFrom || !okTo {
+		return errorResponse(c, http.StatusBadRequest, "from and to must be YYYY-MM-DD")
+	}
+	if !from.IsZero() || !to.IsZero() {
+		dates := bson.M{}
+		if !from.IsZero() {
+			dates["$gte"] = primitive.NewDateTimeFromTime(from)
+		}
+		if !to.IsZero() {
+			dates["$lt"] = primitive.NewDateTimeFromTime(to.AddDate(0, 0, 1)) // to is inclusive
+		}
+		filter[field] = dates
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(shoutboxDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = shoutboxDefaultLimit
+	}
+	limit = min(limit, shoutboxMaxLimit)
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	collection := ShoutboxMailer()
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Println("Count bookings error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch bookings")
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: field, Value: 1}, {Key: "created_at", Value: 1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		log.Println("Find bookings error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch bookings")
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []models.RequestShoutbox{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse bookings")
+	}
+	for i := range bookings {
+		bookings[i].Status = shoutboxStatus(bookings[i])
+	}
+
+	return jsonResponse(c, http.StatusOK, "Bookings fetched successfully", fiber.Map{
+		"bookings": bookings,
+		"page":     page,
+		"limit":    limit,
+		"total":    total,
+	})
+}
+
+// shoutboxCalendar groups scheduled and aired bookings by the Manila day they air on
+func shoutboxCalendar(ctx context.Context, from, to time.Time) ([]models.ShoutboxDay, error) {
+	cursor, err := ShoutboxMailer().Find(ctx,
+		bson.M{
+			"status": bson.M{"$in": bson.A{models.ShoutboxScheduled, models.ShoutboxAired}},
+			"air_at": bson.M{"$gte": primitive.NewDateTimeFromTime(from), "$lt": primitive.NewDateTimeFromTime(to)},
+		},
+		options.Find().SetSort(bson.D{{Key: "air_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var bookings []models.RequestShoutbox
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+
+	days := []models.ShoutboxDay{}
+	for _, booking := range bookings {
+		date := booking.Air_at.Time().In(utils.LocationAsiaManila).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, models.ShoutboxDay{Date: date, Bookings: []models.RequestShoutbox{}, Shows: map[string]int{}})
+		}
+		day := &days[len(days)-1]
+		day.Bookings = append(day.Bookings, booking)
+		day.Shows[booking.Show_name]++
+		if len(day.Bookings) > shoutboxDayLimit || day.Shows[booking.Show_name] > shoutboxShowLimit {
+			day.Overbooked = true
+		}
+	}
+	return days, nil
+}
+
+// GetShoutboxCalendar - Airing calendar with overbooked days flagged
+func GetShoutboxCalendar(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	today := startOfManilaDay(time.Now())
+	from, okFrom := parseDayParam(c, "from", today)
+	to, okTo := parseDayParam(c, "to", today.AddDate(0, 0, 30))
+	if !okFrom || !okTo || to.Before(from) {
+		return errorResponse(c, http.StatusBadRequest, "from and to must be YYYY-MM-DD with from before to")
+	}
+	to = to.AddDate(0, 0, 1) // inclusive
+	if to.Sub(from) > shoutboxCalendarDays*24*time.Hour {
+		return errorResponse(c, http.StatusBadRequest, "The calendar covers at most "+strconv.Itoa(shoutboxCalendarDays)+" days")
+	}
+
+	days, err := shoutboxCalendar(ctx, from, to)
+	if err != nil {
+		log.Println("Booking calendar error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to build calendar")
+	}
+	overbooked := []string{}
+	for _, day := range days {
+		if day.Overbooked {
+			overbooked = append(overbooked, day.Date)
+		}
+	}
+
+	return jsonResponse(c, http.StatusOK, "Calendar fetched successfully", fiber.Map{
+		"days":            days,
+		"overbooked_days": overbooked,
+		"limits":          fiber.Map{"per_show": shoutboxShowLimit, "per_day": shoutboxDayLimit},
+	})
+}
+
+// GetShoutboxBooking - One booking with its history
+func GetShoutboxBooking(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Booking ID")
+	}
+	var booking models.RequestShoutbox
+	if err := ShoutboxMailer().FindOne(ctx, bson.M{"_id": objID}).Decode(&booking); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errorResponse(c, http.StatusNotFound, "Booking not found")
+		}
+		log.Println("Find booking error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch booking")
+	}
+	booking.Status = shoutboxStatus(booking)
+
+	return jsonResponse(c, http.StatusOK, "Booking fetched successfully", fiber.Map{"booking": booking})
+}
+
+// scheduleShoutbox resolves the show and airing date into the slot the shoutout is read in
+func scheduleShoutbox(ctx context.Context, showID, airDate, airTime string) (models.Shows, time.Time, string) {
+	var show models.Shows
+	objID, err := primitive.ObjectIDFromHex(showID)
+	if err != nil {
+		return show, time.Time{}, "show_id is required to schedule a booking"
+	}
+	day, err := time.ParseInLocation("2006-01-02", airDate, utils.LocationAsiaManila)
+	if err != nil {
+		return show, time.Time{}, "air_date is required (YYYY-MM-DD)"
+	}
+	if err := ShowsCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&show); err != nil {
+		return show, time.Time{}, "Show not found"
+	}
+
+	if airTime != "" {
+		clock, ok := helpers.ParseClock(airTime)
+		if !ok {
+			return show, time.Time{}, "air_time is not a time of day"
+		}
+		return show, day.Add(clock), ""
+	}
+
+	schedule, ok := helpers.ParseShowSchedule(derefShowField(show.Show_day), derefShowField(show.Show_time))
+	if !ok {
+		return show, time.Time{}, "The show's schedule could not be read; set air_time"
+	}
+	for _, weekday := range schedule.Days {
+		if weekday == day.Weekday() {
+			return show, day.Add(schedule.Start), ""
+		}
+	}
+	return show, time.Time{}, derefShowField(show.Show_name) + " does not air on " + day.Weekday().String()
+}
+
+// UpdateShoutboxStatus - Move a booking through the lifecycle; scheduling assigns a show airing
+func UpdateShoutboxStatus(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Booking ID")
+	}
+
+	var body struct {
+		Status   string `json:"status"`
+		Note     string `json:"note"`
+		Show_id  string `json:"show_id"`
+		Air_date string `json:"air_date"` // YYYY-MM-DD
+		Air_time string `json:"air_time"` // optional, defaults to the show's start
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	var booking models.RequestShoutbox
+	if err := ShoutboxMailer().FindOne(ctx, bson.M{"_id": objID}).Decode(&booking); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errorResponse(c, http.StatusNotFound, "Booking not found")
+		}
+		log.Println("Find booking error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch booking")
+	}
+	from := shoutboxStatus(booking)
+	if !canMoveShoutbox(from, body.Status) {
+		return errorResponse(c, http.StatusConflict, "A "+from+" booking cannot be moved to "+body.Status)
+	}
+
+	_, staff := revisionAuthor(c)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	note := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(body.Note))
+	set := bson.M{"status": body.Status, "status_note": note, "updated_at": now}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"history": models.ShoutboxStatusChange{Status: body.Status, Note: note, By: staff, At: now}},
+	}
+
+	warnings := []string{}
+	switch body.Status {
+	case models.ShoutboxScheduled:
+		show, airAt, problem := scheduleShoutbox(ctx, body.Show_id, body.Air_date, body.Air_time)
+		if problem != "" {
+			return errorResponse(c, http.StatusBadRequest, problem)
+		}
+		if airAt.Before(time.Now()) {
+			return errorResponse(c, http.StatusBadRequest, "The airing slot has already passed")
+		}
+		set["show_id"], set["show_name"] = show.ID, derefShowField(show.Show_name)
+		set["air_at"] = primitive.NewDateTimeFromTime(airAt)
+		if booking.Event_at != 0 && airAt.After(booking.Event_at.Time()) {
+			warnings = append(warnings, "The shoutout airs after the event date")
+		}
+	case models.ShoutboxReviewing, models.ShoutboxDeclined:
+		// back out of a schedule so the slot frees up on the calendar
+		update["$unset"] = bson.M{"show_id": "", "show_name": "", "air_at": ""}
+	}
+
+	// the status in the filter keeps two staff from moving the same booking at once
+	statusFilter := bson.M{"_id": objID, "status": booking.Status}
+	if booking.Status == "" {
+		statusFilter["status"] = bson.M{"$exists": false}
+	}
+	err = ShoutboxMailer().FindOneAndUpdate(ctx, statusFilter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&booking)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusConflict, "The booking was changed by someone else; reload and try again")
+	}
+	if err != nil {
+		log.Println("Update booking error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update booking")
+	}
+
+	if booking.Status == models.ShoutboxScheduled {
+		day := startOfManilaDay(booking.Air_at.Time())
+		days, err := shoutboxCalendar(ctx, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			log.Println("Booking calendar error:", err)
+		}
+		for _, d := range days {
+			if d.Overbooked {
+				warnings = append(warnings, d.Date+" is overbooked")
+			}
+		}
+	}
+
+	return jsonResponse(c, http.StatusOK, "Booking updated successfully", fiber.Map{
+		"booking":  booking,
+		"warnings": warnings,
+	})
+}
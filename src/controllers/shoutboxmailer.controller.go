@@ -2,10 +2,13 @@ package controllers
 
 import (
 	"fmt"
+	"html"
 	"log"
 	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/mailer"
 	"magic-server-2026/src/models"
-	"net/smtp"
+	"net/mail"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -19,9 +22,6 @@ func ShoutboxMailer() *mongo.Collection {
 }
 
 func SendAutoReplyShoutboxMailer(c fiber.Ctx) error {
-	smtpEmail := "magic899shoutbox@gmail.com"
-	smtpPassword := "qbyq rtay vqml tzkn"
-
 	collection := ShoutboxMailer()
 
 	var req models.RequestShoutbox
@@ -45,88 +45,17 @@ func SendAutoReplyShoutboxMailer(c fiber.Ctx) error {
 	req.Event_date = policy.Sanitize(req.Event_date)
 	req.Radio_spiel = policy.Sanitize(req.Radio_spiel)
 
-	// Load SMTP credentials from environment variables
-	from := smtpEmail
-	password := smtpPassword
-	if from == "" || password == "" {
-		return c.Status(500).SendString("Email server credentials are missing")
-	}
-
-	// SMTP Config
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-
-	// Authenticate SMTP
-	auth := smtp.PlainAuth("", from, password, smtpHost)
-
 	// Prevent email header injection
 	if len(req.Email) > 254 || len(req.Name) > 100 {
 		return c.Status(400).SendString("Invalid email format")
 	}
-
-	// Email Content for the requester
-	subject := "Shoutbox Inquiry - " + req.Title
-	bodyRequester := fmt.Sprintf(`
-		<html>
-		<body>
-			<h3>Hello %s,</h3>
-			<p>Thank you for your shoutbox request regarding "<b>%s</b>". We have received your details and will get back to you soon.</p>
-			<p><b>Event Date:</b> %s</p>
-			<p><b>Radio Spiel:</b> %s</p>
-			<p>Best regards,<br>Magic 899 Team</p>
-		</body>
-		</html>
-	`, req.Name, req.Title, req.Event_date, req.Radio_spiel)
-
-	// Email Content for Admin
-	subjectAdmin := "New Shoutbox Inquiry Received"
-	bodyAdmin := fmt.Sprintf(`
-		<html>
-		<body>
-			<h3>Hello Magic Shoutbox,</h3>
-			<p>A new shoutbox inquiry has been received.</p>
-			<p><b>Name:</b> %s</p>
-			<p><b>Email:</b> %s</p>
-			<p><b>School Name:</b> %s</p>
-			<p><b>Phone Number:</b> %s</p>
-			<p><b>School Phone Number:</b> %s</p>
-			<p><b>Title:</b> %s</p>
-			<p><b>Event Date:</b> %s</p>
-			<p><b>Radio Spiel:</b> %s</p>
-			<p>Best regards,<br>Magic 899 System</p>
-		</body>
-		</html>
-	`,
-		req.Name, req.Email, req.School_name, req.Contact, req.School_contact, req.Title, req.Event_date, req.Radio_spiel)
-
-	// Function to send email
-	sendEmail := func(to []string, subject, body string) error {
-		message := []byte(
-			"MIME-version: 1.0;\n" +
-				"Content-Type: text/html; charset=\"UTF-8\";\n" +
-				"From: \"Magic 899 Shoutbox\" <" + from + ">\n" +
-				"To: " + to[0] + "\n" +
-				"Subject: " + subject + "\n\n" + body)
-		return smtp.SendMail(smtpHost+":"+smtpPort, auth, from, to, message)
-	}
-
-	// Send email to requester
-	err := sendEmail([]string{req.Email}, subject, bodyRequester)
-	if err != nil {
-		fmt.Println("Error sending email to requester:", err)
-		return c.Status(500).SendString("Failed to send confirmation email")
-	}
-
-	// Send email to admin
-	err = sendEmail([]string{smtpEmail}, subjectAdmin, bodyAdmin)
-	if err != nil {
-		fmt.Println("Error sending email to admin:", err)
-		return c.Status(500).SendString("Failed to send inquiry email to admin")
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		return c.Status(400).SendString("Invalid email format")
 	}
 
 	// Save email request to MongoDB
 	now := primitive.NewDateTimeFromTime(time.Now())
-	mailer := models.RequestShoutbox{
+	booking := models.RequestShoutbox{
 		ID:             primitive.NewObjectID(),
 		Name:           req.Name,
 		School_name:    req.School_name,
@@ -140,13 +69,48 @@ func SendAutoReplyShoutboxMailer(c fiber.Ctx) error {
 		Radio_spiel:    req.Radio_spiel,
 		Created_at:     now,
 		Updated_at:     now,
+		Status:         models.ShoutboxReceived,
+		History:        []models.ShoutboxStatusChange{{Status: models.ShoutboxReceived, By: "system", At: now}},
+	}
+	if eventAt, _, ok := helpers.ParseEventDate(req.Event_date); ok {
+		booking.Event_at = primitive.NewDateTimeFromTime(eventAt)
 	}
 
-	_, err = collection.InsertOne(c.Context(), mailer)
+	_, err := collection.InsertOne(c.Context(), booking)
 	if err != nil {
 		fmt.Println("Error saving to MongoDB:", err)
 		return c.Status(500).SendString("Failed to save email request")
 	}
 
+	// Emails go through the outbox; a slow or failing SMTP server no longer fails the request.
+	// The stored values are sanitized HTML, so they are unescaped before the template escapes them again.
+	data := map[string]interface{}{
+		"Name":           html.UnescapeString(booking.Name),
+		"Email":          booking.Email,
+		"School_name":    html.UnescapeString(booking.School_name),
+		"Contact":        html.UnescapeString(booking.Contact),
+		"School_contact": html.UnescapeString(booking.School_contact),
+		"Title":          html.UnescapeString(booking.Title),
+		"Event_date":     html.UnescapeString(booking.Event_date),
+		"Radio_spiel":    html.UnescapeString(booking.Radio_spiel),
+	}
+	for _, email := range []struct {
+		template, tag, to, replyTo string
+	}{
+		{"shoutbox-auto-reply", "shoutbox.auto-reply", req.Email, ""},
+		{"shoutbox-staff", "shoutbox.staff", mailer.StaffInbox(), req.Email},
+	} {
+		rendered, err := mailer.Render(c.Context(), email.template, data)
+		if err != nil {
+			log.Println("Error rendering shoutbox email:", err)
+			continue
+		}
+		msg := rendered.Message()
+		msg.To, msg.Reply_to, msg.From_name, msg.Tag = []string{email.to}, email.replyTo, "Magic 899 Shoutbox", email.tag
+		if _, err := mailer.Enqueue(c.Context(), msg); err != nil {
+			log.Println("Error queueing shoutbox email:", err)
+		}
+	}
+
 	return c.SendString("Auto-reply email sent successfully!")
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"log"
 	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
 	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
 	"strings"
 	"time"
 
@@ -222,6 +224,11 @@ func CreateShow(c fiber.Ctx) error {
 	if len(show.Show_desc) == 0 {
 		return c.Status(400).JSON(fiber.Map{"error": "Show_desc cannot be empty"})
 	}
+	showDesc, err := helpers.SanitizeContentBlocks(show.Show_desc)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Show_desc: " + err.Error()})
+	}
+	show.Show_desc = showDesc
 
 	show.ID = primitive.NewObjectID()
 	show.Created_at = primitive.NewDateTimeFromTime(time.Now())
@@ -230,10 +237,11 @@ func CreateShow(c fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := showCollection.InsertOne(ctx, show)
+	_, err = showCollection.InsertOne(ctx, show)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Error creating show"})
 	}
+	search.Refresh()
 
 	return c.Status(201).JSON(show)
 }
@@ -272,17 +280,9 @@ func UpdateShow(c fiber.Ctx) error {
 		}
 	}
 
-	// Validate and process Show_desc if present
-	if desc, ok := updateData["show_desc"].([]interface{}); ok {
-		var showDesc []string
-		for _, d := range desc {
-			if str, valid := d.(string); valid {
-				showDesc = append(showDesc, str)
-			} else {
-				return c.Status(400).JSON(fiber.Map{"error": "Invalid Show_desc format"})
-			}
-		}
-		updateData["show_desc"] = showDesc
+	// Validate and sanitize Show_desc blocks if present
+	if err := helpers.SanitizeContentField(updateData, "show_desc"); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Show_desc format: " + err.Error()})
 	}
 
 	// Add the updated_at field if not passed as part of the request
@@ -302,6 +302,7 @@ func UpdateShow(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Error updating show"})
 	}
+	search.Refresh()
 
 	return c.Status(200).JSON(fiber.Map{"message": "Show updated successfully"})
 }
@@ -323,6 +324,7 @@ func DeleteShow(c fiber.Ctx) error {
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Error deleting show"})
 	}
+	search.Refresh()
 
 	return c.Status(200).JSON(fiber.Map{"message": "Show deleted successfully"})
 }
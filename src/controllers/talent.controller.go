@@ -0,0 +1,928 @@
+package controllers
+
+import (
+	"context"
+	"html"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/mailer"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Fresh Groove Talent Search
+   -----------------------------------
+   Applicants:
+   1. Register                    POST /talent/applications
+   2. Application status          GET  /talent/applications/:token
+   3. Upload media                PUT  /talent/applications/:token/media/:slot (photo, video, cover-1..3)
+   4. Submit for judging          POST /talent/applications/:token/submit
+   Public:
+   5. Contestants                 GET  /talent/contestants?season=
+   6. Vote                        POST /talent/contestants/:id/vote
+   Judges (admin, judge):
+   7. Judging queue / detail      GET  /talent/judging, GET /talent/judging/:id
+   8. Score an applicant          PUT  /talent/judging/:id/score
+   9. Applicant media             GET  /talent/media/:file
+   10. Leaderboard                GET  /talent/leaderboard?season= (also editors)
+   Staff (admin, editor):
+   11. All applications           GET  /talent/applicants?season=&status=
+   12. Disqualify / reinstate     PATCH /talent/applicants/:id
+   -----------------------------------
+   Final score = weighted average of the judges' marks per criterion plus
+   online votes scaled against the most-voted contestant (models.TalentCriteria).
+   -----------------------------------
+   PATH: /api/v1/talent
+*/
+
+const (
+	talentMinAge        = 16
+	talentMaxAge        = 22
+	talentPhotoMaxBytes = 8 << 20
+	talentVideoMaxBytes = 60 << 20
+	talentCoverMaxBytes = 15 << 20
+	talentVideoMaxLen   = 61 * time.Second // 60 seconds plus encoder rounding
+)
+
+var talentSeasonPattern = regexp.MustCompile(`^\d{4}$`)
+
+var talentIndexesOnce sync.Once
+
+func TalentApplicantCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "talent_applicants")
+	talentIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "season", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "season", Value: 1}, {Key: "status", Value: 1}}},
+		})
+		if err != nil {
+			log.Println("[TALENT] applicant index creation failed:", err)
+		}
+
+		_, err = TalentScoreCollectionInit().Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "applicant_id", Value: 1}, {Key: "judge_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Println("[TALENT] score index creation failed:", err)
+		}
+
+		_, err = TalentVoteCollectionInit().Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "applicant_id", Value: 1}, {Key: "ip_address", Value: 1}, {Key: "date", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Println("[TALENT] vote index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+func TalentScoreCollectionInit() *mongo.Collection {
+	return db.GetCollection("magic899_db", "talent_scores")
+}
+
+func TalentVoteCollectionInit() *mongo.Collection {
+	return db.GetCollection("magic899_db", "talent_votes")
+}
+
+// currentTalentSeason is the year in Manila
+func currentTalentSeason() string {
+	return time.Now().In(utils.LocationAsiaManila).Format("2006")
+}
+
+// talentSeason reads ?season=, defaulting to the current one
+func talentSeason(c fiber.Ctx) (string, bool) {
+	season := c.Query("season", currentTalentSeason())
+	return season, talentSeasonPattern.MatchString(season)
+}
+
+// talentCoverSlot returns the cover index (0-based) of a cover-N slot
+func talentCoverSlot(slot string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(slot, "cover-"))
+	if !strings.HasPrefix(slot, "cover-") || err != nil || n < 1 || n > models.TalentCoverCount {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// talentMissing lists the media an application still needs
+func talentMissing(applicant models.TalentApplicant) []string {
+	missing := []string{}
+	if applicant.Photo == "" {
+		missing = append(missing, models.TalentPhoto)
+	}
+	if applicant.Video == "" {
+		missing = append(missing, models.TalentVideo)
+	}
+	for i := 0; i < models.TalentCoverCount; i++ {
+		if i >= len(applicant.Covers) || applicant.Covers[i] == "" {
+			missing = append(missing, "cover-"+strconv.Itoa(i+1))
+		}
+	}
+	return missing
+}
+
+// talentMediaURL is where judges fetch a private media file
+func talentMediaURL(file string) string {
+	if file == "" {
+		return ""
+	}
+	return "/api/v1/talent/media/" + file
+}
+
+func findApplication(ctx context.Context, token string) (models.TalentApplicant, int, string) {
+	var applicant models.TalentApplicant
+	applicantID, err := helpers.VerifyTalentApplication(token)
+	if err != nil {
+		return applicant, http.StatusNotFound, "Application not found"
+	}
+	err = TalentApplicantCollectionInit().FindOne(ctx, bson.M{"_id": applicantID}).Decode(&applicant)
+	if err == mongo.ErrNoDocuments {
+		return applicant, http.StatusNotFound, "Application not found"
+	}
+	if err != nil {
+		log.Println("Find applicant error:", err)
+		return applicant, http.StatusInternalServerError, "Failed to fetch application"
+	}
+	return applicant, 0, ""
+}
+
+func applicationResponse(applicant models.TalentApplicant) fiber.Map {
+	return fiber.Map{
+		"application": applicant,
+		"missing":     talentMissing(applicant),
+		"criteria":    models.TalentCriteria,
+	}
+}
+
+// RegisterTalentApplicant - Applicant details; returns the token for media uploads
+func RegisterTalentApplicant(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input models.TalentApplicantInput
+	if err := c.Bind().JSON(&input); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	policy := bluemonday.StrictPolicy()
+	now := time.Now()
+	applicant := models.TalentApplicant{
+		ID:      primitive.NewObjectID(),
+		Season:  currentTalentSeason(),
+		Name:    strings.TrimSpace(policy.Sanitize(input.Name)),
+		Address: strings.TrimSpace(policy.Sanitize(input.Address)),
+		Contact: helpers.NormalizePHMobile(input.Contact),
+		Email:   helpers.NormalizeEmail(input.Email),
+		School:  strings.TrimSpace(policy.Sanitize(input.School)),
+		Work:    strings.TrimSpace(policy.Sanitize(input.Work)),
+		Covers:  make([]string, models.TalentCoverCount),
+		Status:  models.TalentDraft,
+	}
+	switch {
+	case applicant.Name == "" || len(applicant.Name) > 100:
+		return errorResponse(c, http.StatusBadRequest, "Name is required (max 100 characters)")
+	case applicant.Address == "" || len(applicant.Address) > 300:
+		return errorResponse(c, http.StatusBadRequest, "Address is required (max 300 characters)")
+	case applicant.Contact == "":
+		return errorResponse(c, http.StatusBadRequest, "Invalid contact number")
+	case applicant.Email == "":
+		return errorResponse(c, http.StatusBadRequest, "Invalid email address")
+	case len(applicant.School) > 200 || len(applicant.Work) > 200:
+		return errorResponse(c, http.StatusBadRequest, "School and work must be at most 200 characters")
+	}
+
+	birth, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(input.Birthdate), utils.LocationAsiaManila)
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Date of birth is required (YYYY-MM-DD)")
+	}
+	if age := ageOn(birth, now.In(utils.LocationAsiaManila)); age < talentMinAge || age > talentMaxAge {
+		return errorResponse(c, http.StatusBadRequest, "The talent search is open to ages 16 to 22")
+	}
+	applicant.Birthdate = birth.Format("2006-01-02")
+
+	applicant.Created_at = primitive.NewDateTimeFromTime(now)
+	applicant.Updated_at = applicant.Created_at
+	if _, err := TalentApplicantCollectionInit().InsertOne(ctx, applicant); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusConflict, "This email has already registered for this season")
+		}
+		log.Println("Insert applicant error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to register")
+	}
+
+	rendered, err := mailer.Render(ctx, "fresh-groove-auto-reply", map[string]interface{}{
+		"Name": html.UnescapeString(applicant.Name), "Email": applicant.Email,
+	})
+	if err == nil {
+		msg := rendered.Message()
+		msg.To, msg.From_name, msg.Tag = []string{applicant.Email}, "Magic 89.9 Fresh Groove", "talent.auto-reply"
+		_, err = mailer.Enqueue(ctx, msg)
+	}
+	if err != nil {
+		log.Println("Fresh Groove auto-reply not queued:", err)
+	}
+
+	data := applicationResponse(applicant)
+	data["token"] = helpers.SignTalentApplication(applicant.ID)
+	return jsonResponse(c, http.StatusCreated, "Registration received; upload your media to complete it", data)
+}
+
+// GetTalentApplication - Applicant view of their application and what is still missing
+func GetTalentApplication(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	applicant, status, problem := findApplication(ctx, c.Params("token"))
+	if problem != "" {
+		return errorResponse(c, status, problem)
+	}
+	return jsonResponse(c, http.StatusOK, "Application fetched successfully", applicationResponse(applicant))
+}
+
+// storeTalentMedia validates an upload for a slot and writes it; returns the filename and video length
+func storeTalentMedia(applicant models.TalentApplicant, slot string, header *multipart.FileHeader) (string, float64, string) {
+	prefix := "talent-" + applicant.ID.Hex()
+	if slot == models.TalentPhoto {
+		name, err := saveUploadedImage(header, prefix, talentPhotoMaxBytes)
+		if err != nil {
+			return "", 0, "Invalid photo: " + err.Error()
+		}
+		return name, 0, ""
+	}
+
+	var data []byte
+	var err error
+	var seconds float64
+	ext := ".mp3"
+	if slot == models.TalentVideo {
+		if data, err = readUpload(header, talentVideoMaxBytes); err != nil {
+			return "", 0, "Invalid video: " + err.Error()
+		}
+		length, err := helpers.MP4Duration(data)
+		if err != nil {
+			return "", 0, "Video must be an MP4 or MOV file"
+		}
+		if length > talentVideoMaxLen {
+			return "", 0, "Video must be 60 seconds or shorter"
+		}
+		seconds = math.Round(length.Seconds()*10) / 10
+		ext = ".mp4"
+		if strings.EqualFold(filepath.Ext(header.Filename), ".mov") {
+			ext = ".mov"
+		}
+	} else {
+		if data, err = readUpload(header, talentCoverMaxBytes); err != nil {
+			return "", 0, "Invalid cover: " + err.Error()
+		}
+		if !helpers.IsMP3(data) {
+			return "", 0, "Covers must be MP3 files"
+		}
+	}
+
+	// videos and covers are kept in the talent area, only served to judges
+	name, err := randomFileName(prefix+"-"+slot, ext)
+	if err == nil {
+		err = storeUploadedFile(storage.Talent, name, data)
+	}
+	if err != nil {
+		log.Println("Store talent media error:", err)
+		return "", 0, "Failed to store file"
+	}
+	return name, seconds, ""
+}
+
+// removeTalentMedia deletes a replaced media file
+func removeTalentMedia(slot, name string) {
+	if name == "" {
+		return
+	}
+	area := storage.Talent
+	if slot == models.TalentPhoto {
+		area = storage.Images
+	}
+	removeStoredFile(area, name)
+}
+
+// UploadTalentMedia - Upload or replace one media slot ("file" field) until the application is submitted
+func UploadTalentMedia(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	applicant, status, problem := findApplication(ctx, c.Params("token"))
+	if problem != "" {
+		return errorResponse(c, status, problem)
+	}
+	if applicant.Status != models.TalentDraft {
+		return errorResponse(c, http.StatusConflict, "This application has already been submitted")
+	}
+
+	slot := c.Params("slot")
+	field, previous := slot, ""
+	switch slot {
+	case models.TalentPhoto:
+		previous = applicant.Photo
+	case models.TalentVideo:
+		previous = applicant.Video
+	default:
+		i, ok := talentCoverSlot(slot)
+		if !ok {
+			return errorResponse(c, http.StatusNotFound, "Unknown media slot")
+		}
+		field = "covers." + strconv.Itoa(i)
+		if i < len(applicant.Covers) {
+			previous = applicant.Covers[i]
+		}
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "A file is required")
+	}
+	name, seconds, problem := storeTalentMedia(applicant, slot, header)
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	set := bson.M{field: name, "updated_at": primitive.NewDateTimeFromTime(time.Now())}
+	if slot == models.TalentVideo {
+		set["video_seconds"] = seconds
+	}
+	result, err := TalentApplicantCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": applicant.ID, "status": models.TalentDraft},
+		bson.M{"$set": set},
+	)
+	if err != nil || result.MatchedCount == 0 {
+		if name != previous {
+			removeTalentMedia(slot, name)
+		}
+		if err != nil {
+			log.Println("Update applicant media error:", err)
+			return errorResponse(c, http.StatusInternalServerError, "Failed to save upload")
+		}
+		return errorResponse(c, http.StatusConflict, "This application has already been submitted")
+	}
+	if previous != name {
+		removeTalentMedia(slot, previous)
+	}
+
+	applicant, _, _ = findApplication(ctx, c.Params("token"))
+	return jsonResponse(c, http.StatusOK, "File uploaded successfully", applicationResponse(applicant))
+}
+
+// SubmitTalentApplication - Lock a complete application and send it to the judges
+func SubmitTalentApplication(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	applicant, status, problem := findApplication(ctx, c.Params("token"))
+	if problem != "" {
+		return errorResponse(c, status, problem)
+	}
+	if applicant.Status != models.TalentDraft {
+		return errorResponse(c, http.StatusConflict, "This application has already been submitted")
+	}
+	if missing := talentMissing(applicant); len(missing) > 0 {
+		return errorResponse(c, http.StatusBadRequest, "Still missing: "+strings.Join(missing, ", "))
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	_, err := TalentApplicantCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": applicant.ID, "status": models.TalentDraft},
+		bson.M{"$set": bson.M{"status": models.TalentSubmitted, "submitted_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		log.Println("Submit applicant error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to submit application")
+	}
+	applicant.Status, applicant.Submitted_at = models.TalentSubmitted, now
+
+	return jsonResponse(c, http.StatusOK, "Application submitted. Good luck!", applicationResponse(applicant))
+}
+
+// GetTalentContestants - Public list of submitted contestants for voting
+func GetTalentContestants(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	season, ok := talentSeason(c)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "Invalid season")
+	}
+
+	cursor, err := TalentApplicantCollectionInit().Find(ctx,
+		bson.M{"season": season, "status": models.TalentSubmitted},
+		options.Find().
+			SetSort(bson.D{{Key: "name", Value: 1}}).
+			SetProjection(bson.M{"name": 1, "photo": 1, "votes": 1, "season": 1, "status": 1}),
+	)
+	if err != nil {
+		log.Println("Find contestants error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch contestants")
+	}
+	defer cursor.Close(ctx)
+
+	contestants := []models.TalentApplicant{}
+	if err := cursor.All(ctx, &contestants); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse contestants")
+	}
+	for i := range contestants {
+		contestants[i].Covers = nil
+	}
+
+	return jsonResponse(c, http.StatusOK, "Contestants fetched successfully", fiber.Map{
+		"season":      season,
+		"contestants": contestants,
+	})
+}
+
+// VoteTalentContestant - One vote per IP per contestant per day (Manila)
+func VoteTalentContestant(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	applicantID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Contestant ID")
+	}
+
+	count, err := TalentApplicantCollectionInit().CountDocuments(ctx, bson.M{
+		"_id": applicantID, "season": currentTalentSeason(), "status": models.TalentSubmitted,
+	})
+	if err != nil {
+		log.Println("Find contestant error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to record vote")
+	}
+	if count == 0 {
+		return errorResponse(c, http.StatusNotFound, "Contestant not found")
+	}
+
+	now := time.Now()
+	vote := models.TalentVote{
+		Applicant_id: applicantID,
+		IP_address:   c.IP(),
+		Date:         now.In(utils.LocationAsiaManila).Format("2006-01-02"),
+		Created_at:   primitive.NewDateTimeFromTime(now),
+	}
+	if _, err := TalentVoteCollectionInit().InsertOne(ctx, vote); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errorResponse(c, http.StatusTooManyRequests, "You already voted for this contestant today")
+		}
+		log.Println("Insert vote error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to record vote")
+	}
+
+	var applicant models.TalentApplicant
+	err = TalentApplicantCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": applicantID},
+		bson.M{"$inc": bson.M{"votes": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"votes": 1}),
+	).Decode(&applicant)
+	if err != nil {
+		log.Println("Increment vote error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to record vote")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Vote recorded successfully", fiber.Map{"votes": applicant.Votes})
+}
+
+// judgeView is an applicant as a judge sees it, with media links and the judge's own marks
+func judgeView(applicant models.TalentApplicant, score *models.TalentScore) fiber.Map {
+	covers := make([]string, 0, len(applicant.Covers))
+	for _, cover := range applicant.Covers {
+		covers = append(covers, talentMediaURL(cover))
+	}
+	return fiber.Map{
+		"applicant": applicant,
+		"media": fiber.Map{
+			"photo":  applicant.Photo,
+			"video":  talentMediaURL(applicant.Video),
+			"covers": covers,
+		},
+		"my_score": score,
+	}
+}
+
+// GetJudgingQueue - Submitted applicants for the season with the judge's own marks
+func GetJudgingQueue(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	season, ok := talentSeason(c)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "Invalid season")
+	}
+	judgeID, _ := revisionAuthor(c)
+
+	cursor, err := TalentApplicantCollectionInit().Find(ctx,
+		bson.M{"season": season, "status": models.TalentSubmitted},
+		options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}),
+	)
+	if err != nil {
+		log.Println("Find applicants error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch applicants")
+	}
+	var applicants []models.TalentApplicant
+	if err := cursor.All(ctx, &applicants); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse applicants")
+	}
+
+	scores := map[primitive.ObjectID]*models.TalentScore{}
+	scoreCursor, err := TalentScoreCollectionInit().Find(ctx, bson.M{"season": season, "judge_id": judgeID})
+	if err == nil {
+		var mine []models.TalentScore
+		err = scoreCursor.All(ctx, &mine)
+		for i := range mine {
+			scores[mine[i].Applicant_id] = &mine[i]
+		}
+	}
+	if err != nil {
+		log.Println("Find scores error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch scores")
+	}
+
+	queue := make([]fiber.Map, 0, len(applicants))
+	pending := 0
+	for _, applicant := range applicants {
+		if scores[applicant.ID] == nil {
+			pending++
+		}
+		queue = append(queue, judgeView(applicant, scores[applicant.ID]))
+	}
+
+	return jsonResponse(c, http.StatusOK, "Judging queue fetched successfully", fiber.Map{
+		"season":     season,
+		"criteria":   models.TalentCriteria,
+		"applicants": queue,
+		"pending":    pending,
+	})
+}
+
+func findSubmittedApplicant(ctx context.Context, c fiber.Ctx) (models.TalentApplicant, error) {
+	var applicant models.TalentApplicant
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return applicant, mongo.ErrNoDocuments
+	}
+	err = TalentApplicantCollectionInit().FindOne(ctx, bson.M{"_id": objID, "status": models.TalentSubmitted}).Decode(&applicant)
+	return applicant, err
+}
+
+// GetJudgingApplicant - One applicant with media links and the judge's marks
+func GetJudgingApplicant(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	applicant, err := findSubmittedApplicant(ctx, c)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Applicant not found")
+	}
+	if err != nil {
+		log.Println("Find applicant error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch applicant")
+	}
+
+	judgeID, _ := revisionAuthor(c)
+	var score *models.TalentScore
+	var mine models.TalentScore
+	err = TalentScoreCollectionInit().FindOne(ctx, bson.M{"applicant_id": applicant.ID, "judge_id": judgeID}).Decode(&mine)
+	if err == nil {
+		score = &mine
+	} else if err != mongo.ErrNoDocuments {
+		log.Println("Find score error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch score")
+	}
+
+	data := judgeView(applicant, score)
+	data["criteria"] = models.TalentCriteria
+	return jsonResponse(c, http.StatusOK, "Applicant fetched successfully", data)
+}
+
+// ScoreTalentApplicant - A judge's marks (0-100 per criterion); re-scoring replaces them
+func ScoreTalentApplicant(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	applicant, err := findSubmittedApplicant(ctx, c)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Applicant not found")
+	}
+	if err != nil {
+		log.Println("Find applicant error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch applicant")
+	}
+
+	var body struct {
+		Vocals         *float64 `json:"vocals"`
+		Performance    *float64 `json:"performance"`
+		Interpretation *float64 `json:"interpretation"`
+		Originality    *float64 `json:"originality"`
+		Comment        string   `json:"comment"`
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+	for _, mark := range []*float64{body.Vocals, body.Performance, body.Interpretation, body.Originality} {
+		if mark == nil || *mark < 0 || *mark > 100 || math.IsNaN(*mark) {
+			return errorResponse(c, http.StatusBadRequest, "vocals, performance, interpretation and originality must each be 0-100")
+		}
+	}
+	comment := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(body.Comment))
+	if len(comment) > 2000 {
+		return errorResponse(c, http.StatusBadRequest, "Comment must be at most 2000 characters")
+	}
+
+	judgeID, judge := revisionAuthor(c)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var score models.TalentScore
+	err = TalentScoreCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"applicant_id": applicant.ID, "judge_id": judgeID},
+		bson.M{
+			"$set": bson.M{
+				"season":         applicant.Season,
+				"judge":          judge,
+				"vocals":         *body.Vocals,
+				"performance":    *body.Performance,
+				"interpretation": *body.Interpretation,
+				"originality":    *body.Originality,
+				"comment":        comment,
+				"updated_at":     now,
+			},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "created_at": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&score)
+	if err != nil {
+		log.Println("Save score error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to save score")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Score saved successfully", fiber.Map{"score": score})
+}
+
+// GetTalentMedia - Serve an applicant's video or cover to judges and staff
+func GetTalentMedia(c fiber.Ctx) error {
+	key, err := storage.Key(storage.Talent, c.Params("file"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid file name")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	obj, err := storage.Default().Stat(ctx, key)
+	if err != nil {
+		return errorResponse(c, http.StatusNotFound, "File not found")
+	}
+	return sendStoredObject(c, obj, "private, max-age=3600")
+}
+
+// talentLeaderboard ranks the season's submitted applicants by weighted score.
+// Applicants no judge has scored yet are not ranked: they come back separately,
+// in submission order, so a missing score never counts as zeros.
+func talentLeaderboard(ctx context.Context, season string) (ranked, unjudged []models.TalentStanding, err error) {
+	cursor, err := TalentApplicantCollectionInit().Find(ctx, bson.M{"season": season, "status": models.TalentSubmitted},
+		options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return nil, nil, err
+	}
+	var applicants []models.TalentApplicant
+	if err := cursor.All(ctx, &applicants); err != nil {
+		return nil, nil, err
+	}
+
+	type judged struct {
+		ID             primitive.ObjectID `bson:"_id"`
+		Judges         int                `bson:"judges"`
+		Vocals         float64            `bson:"vocals"`
+		Performance    float64            `bson:"performance"`
+		Interpretation float64            `bson:"interpretation"`
+		Originality    float64            `bson:"originality"`
+	}
+	scoreCursor, err := TalentScoreCollectionInit().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"season": season}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":            "$applicant_id",
+			"judges":         bson.M{"$sum": 1},
+			"vocals":         bson.M{"$avg": "$vocals"},
+			"performance":    bson.M{"$avg": "$performance"},
+			"interpretation": bson.M{"$avg": "$interpretation"},
+			"originality":    bson.M{"$avg": "$originality"},
+		}}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var averages []judged
+	if err := scoreCursor.All(ctx, &averages); err != nil {
+		return nil, nil, err
+	}
+	byApplicant := make(map[primitive.ObjectID]judged, len(averages))
+	for _, average := range averages {
+		byApplicant[average.ID] = average
+	}
+
+	maxVotes := 0
+	for _, applicant := range applicants {
+		maxVotes = max(maxVotes, applicant.Votes)
+	}
+
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	standings := make([]models.TalentStanding, 0, len(applicants))
+	unjudged = []models.TalentStanding{}
+	submitted := make(map[primitive.ObjectID]primitive.DateTime, len(applicants))
+	for _, applicant := range applicants {
+		average, scored := byApplicant[applicant.ID]
+		if !scored || average.Judges == 0 {
+			unjudged = append(unjudged, models.TalentStanding{
+				Applicant_id: applicant.ID,
+				Name:         applicant.Name,
+				Photo:        applicant.Photo,
+				Votes:        applicant.Votes,
+				Unjudged:     true,
+			})
+			continue
+		}
+		votes := 0.0
+		if maxVotes > 0 {
+			votes = float64(applicant.Votes) / float64(maxVotes) * 100
+		}
+		criteria := map[string]float64{
+			"vocals":         average.Vocals,
+			"performance":    average.Performance,
+			"interpretation": average.Interpretation,
+			"originality":    average.Originality,
+			"online_votes":   votes,
+		}
+		total := 0.0
+		for _, criterion := range models.TalentCriteria {
+			total += criteria[criterion.Key] * criterion.Weight / 100
+		}
+		for key, value := range criteria {
+			criteria[key] = round(value)
+		}
+
+		submitted[applicant.ID] = applicant.Submitted_at
+		standings = append(standings, models.TalentStanding{
+			Applicant_id: applicant.ID,
+			Name:         applicant.Name,
+			Photo:        applicant.Photo,
+			Judges:       average.Judges,
+			Votes:        applicant.Votes,
+			Criteria:     criteria,
+			Total:        round(total),
+		})
+	}
+
+	// ties go to the stronger vocals, then to whoever submitted first
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.Criteria["vocals"] != b.Criteria["vocals"] {
+			return a.Criteria["vocals"] > b.Criteria["vocals"]
+		}
+		return submitted[a.Applicant_id] < submitted[b.Applicant_id]
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i].Total == standings[i-1].Total && standings[i].Criteria["vocals"] == standings[i-1].Criteria["vocals"] {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings, unjudged, nil
+}
+
+// GetTalentLeaderboard - Final ranked leaderboard for a season
+func GetTalentLeaderboard(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	season, ok := talentSeason(c)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "Invalid season")
+	}
+
+	standings, unjudged, err := talentLeaderboard(ctx, season)
+	if err != nil {
+		log.Println("Leaderboard error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to compute leaderboard")
+	}
+
+	judges, err := TalentScoreCollectionInit().Distinct(ctx, "judge_id", bson.M{"season": season})
+	if err != nil {
+		log.Println("Count judges error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to compute leaderboard")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Leaderboard computed successfully", fiber.Map{
+		"season":      season,
+		"criteria":    models.TalentCriteria,
+		"judge_count": len(judges),
+		"standings":   standings,
+		"unjudged":    unjudged,
+	})
+}
+
+// GetTalentApplicants - Staff list of every application with contact details
+func GetTalentApplicants(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	season, ok := talentSeason(c)
+	if !ok {
+		return errorResponse(c, http.StatusBadRequest, "Invalid season")
+	}
+	filter := bson.M{"season": season}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := TalentApplicantCollectionInit().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		log.Println("Find applicants error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch applicants")
+	}
+	defer cursor.Close(ctx)
+
+	applicants := []models.TalentApplicant{}
+	if err := cursor.All(ctx, &applicants); err != nil {
+		log.Println("Cursor decode error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to parse applicants")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Applicants fetched successfully", fiber.Map{"applicants": applicants})
+}
+
+// UpdateTalentApplicant - Staff disqualify a submitted applicant or reinstate them
+func UpdateTalentApplicant(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid Applicant ID")
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := c.Bind().JSON(&body); err != nil {
+		return errorResponse(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	from := models.TalentSubmitted
+	switch body.Status {
+	case models.TalentDisqualified:
+	case models.TalentSubmitted:
+		from = models.TalentDisqualified
+	default:
+		return errorResponse(c, http.StatusBadRequest, "status must be submitted or disqualified")
+	}
+
+	var applicant models.TalentApplicant
+	err = TalentApplicantCollectionInit().FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "status": from},
+		bson.M{"$set": bson.M{
+			"status":      body.Status,
+			"status_note": strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(body.Note)),
+			"updated_at":  primitive.NewDateTimeFromTime(time.Now()),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&applicant)
+	if err == mongo.ErrNoDocuments {
+		return errorResponse(c, http.StatusNotFound, "Applicant not found or not "+from)
+	}
+	if err != nil {
+		log.Println("Update applicant error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to update applicant")
+	}
+
+	return jsonResponse(c, http.StatusOK, "Applicant updated successfully", fiber.Map{"applicant": applicant})
+}
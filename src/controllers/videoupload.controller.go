@@ -0,0 +1,831 @@
+package controllers
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/search"
+	"magic-server-2026/src/storage"
+	"magic-server-2026/src/utils"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/microcosm-cc/bluemonday"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Video Upload Controller (tus 1.0 resumable uploads)
+   -----------------------------------
+   1. Capabilities                OPTIONS /uploads/videos
+   2. Create an upload            POST    /uploads/videos (Upload-Length, Upload-Metadata)
+   3. Current offset              HEAD    /uploads/videos/:id
+   4. Append a chunk              PATCH   /uploads/videos/:id (Upload-Offset, Upload-Checksum)
+   5. Terminate                   DELETE  /uploads/videos/:id
+   6. Status / recent uploads     GET     /uploads/videos/:id, GET /uploads/videos
+   -----------------------------------
+   Implements the core protocol with the creation, termination, checksum
+   and expiration extensions (https://tus.io/protocols/resumable-upload).
+   Clients that cannot send PATCH or DELETE may POST with
+   X-HTTP-Method-Override. All requests are staff only and carry the
+   X-CSRF-Token header like any other mutation.
+
+   Videos can be up to 20 GB, or 5 GB on the s3 storage driver, which
+   stores each video with a single PUT (Tus-Max-Size says which).
+
+   Upload-Metadata keys: filename (or name, required, .mp4 or .mov), title,
+   show_name, description, and sha256 (hex of the whole file, optional).
+
+   Request bodies are buffered by the server (64 MB on the upload routes,
+   see bodylimit_middleware.go), so clients send chunks of at most 32 MB (tus-js-client: chunkSize). Each chunk may carry
+   Upload-Checksum ("sha1 <base64>", also sha256 and md5); a chunk that
+   does not match is rejected with 460 and not written.
+
+   Chunks are staged on the local disk (VIDEO_UPLOAD_DIR, default
+   ./src/uploads/.tus), so a running upload has to stay on one instance:
+   the upload records its host (staged_on), other instances refuse its
+   chunks, and only that host resumes it after a restart.
+   Once the last byte is in, the file is hashed, checked against the
+   sha256 metadata, probed as MP4/MOV, moved into the videos storage area
+   and registered as a MagicVideos entry (provider "upload", played
+   through signed player URLs). GET /uploads/videos/:id shows the result.
+   Unfinished uploads expire 24 hours after their last chunk.
+   -----------------------------------
+   PATH: /api/v1/uploads/videos
+*/
+
+const (
+	tusVersion                = "1.0.0"
+	tusExtensions             = "creation,termination,checksum,expiration"
+	tusChecksumAlgorithms     = "sha1,sha256,md5"
+	tusStatusChecksumMismatch = 460 // tus checksum extension
+
+	videoUploadMaxSize       = 20 << 30
+	videoUploadMaxChunk      = 32 << 20 // below middlewares.UploadBodyLimit
+	videoUploadMaxMetadata   = 4 << 10
+	videoUploadExpiry        = 24 * time.Hour
+	videoUploadKeep          = 7 * 24 * time.Hour // finished uploads stay visible this long
+	videoUploadSweepInterval = time.Hour
+	videoUploadFinishTimeout = 2 * time.Hour
+	videoUploadMaxTitle      = 150
+	videoUploadMaxDesc       = 5000
+)
+
+// videoUploadTypes are the containers the player serves
+var videoUploadTypes = map[string]string{".mp4": "video/mp4", ".mov": "video/quicktime"}
+
+var (
+	videoUploadIndexesOnce sync.Once
+	videoUploadDirOnce     sync.Once
+	videoUploadDirPath     string
+	// videoUploadLocks holds a *sync.Mutex per upload, so chunks of one upload are written one at a time
+	videoUploadLocks sync.Map
+	// videoUploadHost names this instance on the uploads it stages
+	videoUploadHost, _ = os.Hostname()
+)
+
+// videoUploadLimit is videoUploadMaxSize, or less when the storage driver cannot
+// store that much in one Put (a single S3 PUT stops at 5 GB)
+func videoUploadLimit() int64 {
+	if limit := storage.MaxPutSize(); limit > 0 && limit < videoUploadMaxSize {
+		return limit
+	}
+	return videoUploadMaxSize
+}
+
+func VideoUploadCollectionInit() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "video_uploads")
+	videoUploadIndexesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		})
+		if err != nil {
+			log.Println("[UPLOADS] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+// videoUploadDir is where chunks are staged, created on first use
+func videoUploadDir() string {
+	videoUploadDirOnce.Do(func() {
+		videoUploadDirPath = utils.GetEnv("VIDEO_UPLOAD_DIR")
+		if videoUploadDirPath == "" {
+			videoUploadDirPath = "./src/uploads/.tus"
+		}
+		if err := os.MkdirAll(videoUploadDirPath, 0o755); err != nil {
+			log.Println("[UPLOADS] cannot create the staging directory:", err)
+		}
+	})
+	return videoUploadDirPath
+}
+
+func videoUploadPath(id primitive.ObjectID) string {
+	return filepath.Join(videoUploadDir(), id.Hex())
+}
+
+// lockVideoUpload takes the upload's lock without waiting; false while another request holds it
+func lockVideoUpload(id primitive.ObjectID) (func(), bool) {
+	value, _ := videoUploadLocks.LoadOrStore(id, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, false
+	}
+	return mu.Unlock, true
+}
+
+var errVideoUploadNotFound = errors.New("upload not found")
+
+// videoUploadID reads the :id route param
+func videoUploadID(c fiber.Ctx) (primitive.ObjectID, error) {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return objID, errVideoUploadNotFound
+	}
+	return objID, nil
+}
+
+// findVideoUpload loads the upload named by the :id route param
+func findVideoUpload(ctx context.Context, c fiber.Ctx) (models.VideoUpload, error) {
+	var upload models.VideoUpload
+	objID, err := videoUploadID(c)
+	if err != nil {
+		return upload, err
+	}
+	err = VideoUploadCollectionInit().FindOne(ctx, bson.M{"_id": objID}).Decode(&upload)
+	if err == mongo.ErrNoDocuments {
+		return upload, errVideoUploadNotFound
+	}
+	return upload, err
+}
+
+func videoUploadError(c fiber.Ctx, err error) error {
+	if err == errVideoUploadNotFound {
+		return errorResponse(c, http.StatusNotFound, "Upload not found")
+	}
+	log.Println("Find video upload error:", err)
+	return errorResponse(c, http.StatusInternalServerError, "Failed to fetch upload")
+}
+
+// tusResumable sets Tus-Resumable on the response and checks the client speaks the same version
+func tusResumable(c fiber.Ctx) bool {
+	c.Set("Tus-Resumable", tusVersion)
+	if c.Get("Tus-Resumable") != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return false
+	}
+	return true
+}
+
+func tusVersionMismatch(c fiber.Ctx) error {
+	return errorResponse(c, http.StatusPreconditionFailed, "Unsupported Tus-Resumable version, use "+tusVersion)
+}
+
+// tusExpires sets Upload-Expires for uploads that are still receiving
+func tusExpires(c fiber.Ctx, upload models.VideoUpload) {
+	if upload.Status == models.VideoUploadReceiving {
+		c.Set("Upload-Expires", upload.Expires_at.Time().UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseUploadMetadata reads Upload-Metadata: comma-separated "key base64value" pairs
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	if len(header) > videoUploadMaxMetadata {
+		return nil, errors.New("Upload-Metadata is too long")
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || strings.ContainsAny(encoded, " ") {
+			return nil, errors.New("Upload-Metadata is malformed")
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, fmt.Errorf("Upload-Metadata repeats %q", key)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("Upload-Metadata value of %q is not base64", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// normalizeVideoUploadMetadata keeps the keys the upload uses, sanitized
+func normalizeVideoUploadMetadata(raw map[string]string) (map[string]string, string) {
+	metadata := map[string]string{}
+	filename := raw["filename"]
+	if filename == "" {
+		filename = raw["name"] // Uppy
+	}
+	filename = filepath.Base(strings.TrimSpace(strings.ReplaceAll(filename, "\\", "/")))
+	if filename == "" || filename == "." || filename == "/" {
+		return nil, "filename metadata is required"
+	}
+	if _, ok := videoUploadTypes[strings.ToLower(filepath.Ext(filename))]; !ok {
+		return nil, "Only MP4 and MOV videos can be uploaded"
+	}
+	metadata["filename"] = filename
+
+	for _, field := range []struct {
+		key       string
+		maxLength int
+	}{{"title", videoUploadMaxTitle}, {"show_name", videoUploadMaxTitle}, {"description", videoUploadMaxDesc}} {
+		text := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(raw[field.key]))
+		if len(text) > field.maxLength {
+			return nil, fmt.Sprintf("%s cannot be longer than %d characters", field.key, field.maxLength)
+		}
+		if text != "" {
+			metadata[field.key] = text
+		}
+	}
+
+	if sum := strings.ToLower(strings.TrimSpace(raw["sha256"])); sum != "" {
+		if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+			return nil, "sha256 metadata must be the hex SHA-256 of the file"
+		}
+		metadata["sha256"] = sum
+	}
+	return metadata, ""
+}
+
+// chunkChecksum checks an Upload-Checksum header ("<algorithm> <base64 digest>") against data
+func chunkChecksum(header string, data []byte) (ok bool, problem string) {
+	algorithm, encoded, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return false, "Upload-Checksum must be \"<algorithm> <base64 digest>\""
+	}
+	var h hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return false, "Unsupported checksum algorithm, use one of " + tusChecksumAlgorithms
+	}
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false, "Upload-Checksum digest is not base64"
+	}
+	h.Write(data)
+	return string(h.Sum(nil)) == string(expected), ""
+}
+
+// VideoUploadOptions - tus discovery: version, extensions and limits
+func VideoUploadOptions(c fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(videoUploadLimit(), 10))
+	c.Set("Tus-Checksum-Algorithm", tusChecksumAlgorithms)
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// CreateVideoUpload - Start an upload of Upload-Length bytes; Location is where chunks go
+func CreateVideoUpload(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return tusVersionMismatch(c)
+	}
+	if c.Get("Upload-Defer-Length") != "" {
+		return errorResponse(c, http.StatusBadRequest, "Upload-Defer-Length is not supported, send Upload-Length")
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 1 {
+		return errorResponse(c, http.StatusBadRequest, "Upload-Length must be a positive number of bytes")
+	}
+	if limit := videoUploadLimit(); length > limit {
+		return errorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Videos cannot be larger than %d GB", limit>>30))
+	}
+	if len(c.BodyRaw()) > 0 {
+		return errorResponse(c, http.StatusBadRequest, "Send the video with PATCH requests to the upload's Location")
+	}
+	raw, err := parseUploadMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return errorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	metadata, problem := normalizeVideoUploadMetadata(raw)
+	if problem != "" {
+		return errorResponse(c, http.StatusBadRequest, problem)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, author := revisionAuthor(c)
+	upload := models.VideoUpload{
+		ID:         primitive.NewObjectID(),
+		Length:     length,
+		Metadata:   metadata,
+		Status:     models.VideoUploadReceiving,
+		Staged_on:  videoUploadHost,
+		Created_by: author,
+		Created_at: primitive.NewDateTimeFromTime(now),
+		Updated_at: primitive.NewDateTimeFromTime(now),
+		Expires_at: primitive.NewDateTimeFromTime(now.Add(videoUploadExpiry)),
+	}
+
+	file, err := os.OpenFile(videoUploadPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Println("Create video upload file error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create upload")
+	}
+	file.Close()
+
+	if _, err := VideoUploadCollectionInit().InsertOne(ctx, upload); err != nil {
+		os.Remove(videoUploadPath(upload.ID))
+		log.Println("Insert video upload error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to create upload")
+	}
+
+	c.Set("Location", utils.ServerOrigin()+strings.TrimSuffix(c.Path(), "/")+"/"+upload.ID.Hex())
+	tusExpires(c, upload)
+	return c.SendStatus(http.StatusCreated)
+}
+
+// HeadVideoUpload - How many bytes the server has, so a client knows where to resume
+func HeadVideoUpload(c fiber.Ctx) error {
+	c.Set("Cache-Control", "no-store")
+	if !tusResumable(c) {
+		return c.SendStatus(http.StatusPreconditionFailed)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	upload, err := findVideoUpload(ctx, c)
+	if err == errVideoUploadNotFound {
+		return c.SendStatus(http.StatusNotFound)
+	}
+	if err != nil {
+		log.Println("Find video upload error:", err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+	if upload.Status == models.VideoUploadFailed {
+		return c.SendStatus(http.StatusGone)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	tusExpires(c, upload)
+	return c.SendStatus(http.StatusOK)
+}
+
+// PatchVideoUpload - Write a chunk at Upload-Offset; the last chunk starts processing
+func PatchVideoUpload(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return tusVersionMismatch(c)
+	}
+	if c.Get("Content-Type") != "application/offset+octet-stream" {
+		return errorResponse(c, http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return errorResponse(c, http.StatusBadRequest, "Upload-Offset must be a number of bytes")
+	}
+	chunk := c.BodyRaw()
+	if len(chunk) > videoUploadMaxChunk {
+		return errorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Chunks cannot be larger than %d MB", videoUploadMaxChunk>>20))
+	}
+	if header := c.Get("Upload-Checksum"); header != "" {
+		ok, problem := chunkChecksum(header, chunk)
+		if problem != "" {
+			return errorResponse(c, http.StatusBadRequest, problem)
+		}
+		if !ok {
+			return errorResponse(c, tusStatusChecksumMismatch, "Checksum mismatch")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	id, err := videoUploadID(c)
+	if err != nil {
+		return videoUploadError(c, err)
+	}
+	unlock, ok := lockVideoUpload(id)
+	if !ok {
+		return errorResponse(c, http.StatusLocked, "Another chunk of this upload is being written")
+	}
+	defer unlock()
+	upload, err := findVideoUpload(ctx, c)
+	if err != nil {
+		return videoUploadError(c, err)
+	}
+
+	switch {
+	case upload.Status == models.VideoUploadFailed:
+		return errorResponse(c, http.StatusGone, "Upload failed: "+upload.Error)
+	case upload.Status != models.VideoUploadReceiving:
+		return errorResponse(c, http.StatusConflict, "Upload is already complete")
+	case time.Now().After(upload.Expires_at.Time()):
+		return errorResponse(c, http.StatusGone, "Upload has expired")
+	case upload.Staged_on != "" && upload.Staged_on != videoUploadHost:
+		return errorResponse(c, http.StatusConflict, "Upload is staged on another server")
+	case offset != upload.Offset:
+		c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		return errorResponse(c, http.StatusConflict, "Upload-Offset does not match the upload")
+	case upload.Offset+int64(len(chunk)) > upload.Length:
+		return errorResponse(c, http.StatusRequestEntityTooLarge, "Chunk runs past Upload-Length")
+	}
+
+	file, err := os.OpenFile(videoUploadPath(upload.ID), os.O_WRONLY, 0o644)
+	if err == nil {
+		_, err = file.WriteAt(chunk, upload.Offset)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Println("Write video upload chunk error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to write chunk")
+	}
+
+	now := time.Now()
+	upload.Offset += int64(len(chunk))
+	upload.Updated_at = primitive.NewDateTimeFromTime(now)
+	upload.Expires_at = primitive.NewDateTimeFromTime(now.Add(videoUploadExpiry))
+	if upload.Offset == upload.Length {
+		upload.Status = models.VideoUploadProcessing
+	}
+	// guarded by the offset, so a chunk written elsewhere in the meantime is not overwritten
+	result, err := VideoUploadCollectionInit().UpdateOne(ctx, bson.M{
+		"_id":    upload.ID,
+		"offset": offset,
+		"status": models.VideoUploadReceiving,
+	}, bson.M{"$set": bson.M{
+		"offset":     upload.Offset,
+		"status":     upload.Status,
+		"updated_at": upload.Updated_at,
+		"expires_at": upload.Expires_at,
+	}})
+	if err != nil {
+		log.Println("Update video upload error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to save upload offset")
+	}
+	if result.MatchedCount != 1 {
+		return errorResponse(c, http.StatusConflict, "Upload changed while the chunk was written; check its offset and resume")
+	}
+	if upload.Status == models.VideoUploadProcessing {
+		go finishVideoUpload(upload)
+	}
+
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	tusExpires(c, upload)
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// DeleteVideoUpload - Terminate an upload and drop its staged bytes; a registered video stays
+func DeleteVideoUpload(c fiber.Ctx) error {
+	if !tusResumable(c) {
+		return tusVersionMismatch(c)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, err := videoUploadID(c)
+	if err != nil {
+		return videoUploadError(c, err)
+	}
+	unlock, ok := lockVideoUpload(id)
+	if !ok {
+		return errorResponse(c, http.StatusLocked, "A chunk of this upload is being written")
+	}
+	defer unlock()
+	upload, err := findVideoUpload(ctx, c)
+	if err != nil {
+		return videoUploadError(c, err)
+	}
+	if upload.Status == models.VideoUploadProcessing {
+		return errorResponse(c, http.StatusConflict, "Upload is being processed")
+	}
+
+	if _, err := VideoUploadCollectionInit().DeleteOne(ctx, bson.M{"_id": upload.ID, "status": bson.M{"$ne": models.VideoUploadProcessing}}); err != nil {
+		log.Println("Delete video upload error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to delete upload")
+	}
+	os.Remove(videoUploadPath(upload.ID))
+	videoUploadLocks.Delete(upload.ID)
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// VideoUploadMethodOverride - POST with X-HTTP-Method-Override for clients limited to GET and POST
+func VideoUploadMethodOverride(c fiber.Ctx) error {
+	switch strings.ToUpper(c.Get("X-HTTP-Method-Override")) {
+	case fiber.MethodPatch:
+		return PatchVideoUpload(c)
+	case fiber.MethodDelete:
+		return DeleteVideoUpload(c)
+	case fiber.MethodHead:
+		return HeadVideoUpload(c)
+	}
+	return errorResponse(c, http.StatusMethodNotAllowed, "X-HTTP-Method-Override must be PATCH, DELETE or HEAD")
+}
+
+// GetVideoUpload - Status of an upload, with the registered video once complete
+func GetVideoUpload(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	upload, err := findVideoUpload(ctx, c)
+	if err != nil {
+		return videoUploadError(c, err)
+	}
+	data := fiber.Map{"upload": upload}
+	if upload.Video_id != nil {
+		var video models.MagicVideos
+		if err := MagicVideosCollectionInit().FindOne(ctx, bson.M{"_id": *upload.Video_id}).Decode(&video); err == nil {
+			data["video"] = video
+		}
+	}
+	return jsonResponse(c, http.StatusOK, "Upload fetched successfully", data)
+}
+
+// GetVideoUploads - Recent uploads, newest first (?status= filters)
+func GetVideoUploads(c fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(100)
+	cursor, err := VideoUploadCollectionInit().Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Find video uploads error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch uploads")
+	}
+	uploads := []models.VideoUpload{}
+	if err := cursor.All(ctx, &uploads); err != nil {
+		log.Println("Decode video uploads error:", err)
+		return errorResponse(c, http.StatusInternalServerError, "Failed to fetch uploads")
+	}
+	return jsonResponse(c, http.StatusOK, "Uploads fetched successfully", fiber.Map{"uploads": uploads})
+}
+
+// videoUploadFileName names the stored video after the upload's file name, unique by upload ID
+func videoUploadFileName(upload models.VideoUpload) string {
+	filename := upload.Metadata["filename"]
+	ext := strings.ToLower(filepath.Ext(filename))
+	base := utils.Slugify(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	if len(base) > 60 {
+		base = base[:60]
+	}
+	if base == "" {
+		base = "video"
+	}
+	return base + "-" + upload.ID.Hex() + ext
+}
+
+// checkVideoUpload hashes and probes the staged file
+func checkVideoUpload(file *os.File, upload models.VideoUpload) (sum string, duration time.Duration, problem string, err error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(file, 0, upload.Length)); err != nil {
+		return "", 0, "", err
+	}
+	sum = hex.EncodeToString(h.Sum(nil))
+	if expected := upload.Metadata["sha256"]; expected != "" && expected != sum {
+		return sum, 0, "SHA-256 of the uploaded file does not match the sha256 metadata", nil
+	}
+	duration, err = helpers.MP4DurationAt(file, upload.Length)
+	if err != nil {
+		return sum, 0, "Not a playable MP4 or MOV video", nil
+	}
+	return sum, duration, "", nil
+}
+
+// finishVideoUpload verifies a complete upload, stores it and registers the video.
+// It can be run again for the same upload (after a restart): the video ID is
+// saved on the upload before the video is registered, registering upserts by
+// that ID, and the staged file is only removed once the upload is complete.
+func finishVideoUpload(upload models.VideoUpload) {
+	ctx, cancel := context.WithTimeout(context.Background(), videoUploadFinishTimeout)
+	defer cancel()
+	collection := VideoUploadCollectionInit()
+
+	fail := func(message string, err error) {
+		if err != nil {
+			log.Printf("[UPLOADS] %s: %s: %v", upload.ID.Hex(), message, err)
+		}
+		os.Remove(videoUploadPath(upload.ID))
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": upload.ID}, bson.M{"$set": bson.M{
+			"status":     models.VideoUploadFailed,
+			"error":      message,
+			"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+			"expires_at": primitive.NewDateTimeFromTime(time.Now().Add(videoUploadKeep)),
+		}})
+		if err != nil {
+			log.Println("[UPLOADS] cannot mark upload failed:", err)
+		}
+	}
+
+	file, err := os.Open(videoUploadPath(upload.ID))
+	if err != nil {
+		fail("Uploaded file is missing", err)
+		return
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil || info.Size() != upload.Length {
+		fail("Uploaded file is incomplete", err)
+		return
+	}
+
+	sum, duration, problem, err := checkVideoUpload(file, upload)
+	if err != nil {
+		fail("Failed to read the uploaded file", err)
+		return
+	}
+	if problem != "" {
+		fail(problem, nil)
+		return
+	}
+
+	fileName := videoUploadFileName(upload)
+	retry := upload.Video_id != nil
+	videoID := primitive.NewObjectID()
+	if retry {
+		videoID = *upload.Video_id
+	} else {
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": upload.ID, "status": models.VideoUploadProcessing}, bson.M{"$set": bson.M{
+			"video_id":  videoID,
+			"file_name": fileName,
+		}})
+		if err != nil {
+			fail("Failed to register the video", err)
+			return
+		}
+	}
+
+	key, err := storage.Key(storage.Videos, fileName)
+	if err == nil {
+		err = storage.Default().Put(ctx, key, io.NewSectionReader(file, 0, upload.Length), upload.Length, videoUploadTypes[filepath.Ext(fileName)])
+	}
+	if err != nil {
+		fail("Failed to store the video", err)
+		return
+	}
+
+	now := time.Now()
+	title := upload.Metadata["title"]
+	if title == "" {
+		title = strings.TrimSuffix(upload.Metadata["filename"], filepath.Ext(upload.Metadata["filename"]))
+	}
+	desc := []string{}
+	if upload.Metadata["description"] != "" {
+		desc = append(desc, upload.Metadata["description"])
+	}
+	video := models.MagicVideos{
+		ID:         videoID,
+		Title:      title,
+		Video_url:  fileName,
+		Desc:       desc,
+		Show_name:  upload.Metadata["show_name"],
+		Provider:   "upload",
+		Socials:    []*string{},
+		Date:       now.In(utils.LocationAsiaManila).Format("2006-01-02"),
+		Duration:   int(duration.Round(time.Second) / time.Second),
+		Created_at: primitive.NewDateTimeFromTime(now),
+		Updated_at: primitive.NewDateTimeFromTime(now),
+	}
+	_, err = MagicVideosCollectionInit().UpdateOne(ctx,
+		bson.M{"_id": video.ID},
+		bson.M{"$setOnInsert": video},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if !retry {
+			// an earlier run may have registered the video with this file
+			storage.Default().Delete(ctx, key)
+		}
+		fail("Failed to register the video", err)
+		return
+	}
+	search.Refresh()
+
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": upload.ID}, bson.M{"$set": bson.M{
+		"status":     models.VideoUploadComplete,
+		"file_name":  fileName,
+		"sha256":     sum,
+		"duration":   video.Duration,
+		"video_id":   video.ID,
+		"updated_at": primitive.NewDateTimeFromTime(now),
+		"expires_at": primitive.NewDateTimeFromTime(now.Add(videoUploadKeep)),
+	}})
+	if err != nil {
+		// still processing: the next start finishes it again with the same video
+		log.Println("[UPLOADS] cannot mark upload complete:", err)
+		return
+	}
+	os.Remove(videoUploadPath(upload.ID))
+	log.Printf("[UPLOADS] %s stored as %s (%s)", upload.ID.Hex(), fileName, duration.Round(time.Second))
+}
+
+// InitVideoUploads resumes the uploads this instance was processing when it stopped,
+// then removes expired uploads and stray staged files every hour
+func InitVideoUploads() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	var processing []models.VideoUpload
+	cursor, err := VideoUploadCollectionInit().Find(ctx, bson.M{
+		"status":    models.VideoUploadProcessing,
+		"staged_on": bson.M{"$in": bson.A{videoUploadHost, nil}},
+	})
+	if err == nil {
+		err = cursor.All(ctx, &processing)
+	}
+	cancel()
+	if err != nil {
+		log.Println("[UPLOADS] cannot load processing uploads:", err)
+	}
+	for _, upload := range processing {
+		// the bytes are on the disk of the instance that staged them
+		if _, err := os.Stat(videoUploadPath(upload.ID)); err != nil {
+			log.Printf("[UPLOADS] %s is not staged here, leaving it", upload.ID.Hex())
+			continue
+		}
+		finishVideoUpload(upload)
+	}
+
+	for {
+		if err := sweepVideoUploads(); err != nil {
+			log.Println("[UPLOADS] sweep failed:", err)
+		}
+		time.Sleep(videoUploadSweepInterval)
+	}
+}
+
+func sweepVideoUploads() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	collection := VideoUploadCollectionInit()
+
+	filter := bson.M{
+		"status":     bson.M{"$ne": models.VideoUploadProcessing},
+		"expires_at": bson.M{"$lt": primitive.NewDateTimeFromTime(time.Now())},
+	}
+	var expired []models.VideoUpload
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &expired); err != nil {
+		return err
+	}
+	for _, upload := range expired {
+		if unlock, ok := lockVideoUpload(upload.ID); ok {
+			os.Remove(videoUploadPath(upload.ID))
+			collection.DeleteOne(ctx, bson.M{"_id": upload.ID, "status": filter["status"], "expires_at": filter["expires_at"]})
+			unlock()
+			videoUploadLocks.Delete(upload.ID)
+		}
+	}
+
+	// staged files nothing refers to any more (e.g. the record was deleted by hand)
+	entries, err := os.ReadDir(videoUploadDir())
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		id, err := primitive.ObjectIDFromHex(entry.Name())
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < videoUploadExpiry {
+			continue
+		}
+		if count, err := collection.CountDocuments(ctx, bson.M{"_id": id}); err == nil && count == 0 {
+			os.Remove(filepath.Join(videoUploadDir(), entry.Name()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail lowercases and validates an email address ("" if invalid)
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > 254 {
+		return ""
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ""
+	}
+	return email
+}
+
+// NormalizePHMobile returns a Philippine mobile number as 09XXXXXXXXX ("" if invalid).
+// Accepts spaces, dashes and the +63 / 63 prefixes.
+func NormalizePHMobile(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(number, "63") && len(number) == 12:
+		number = "0" + number[2:]
+	case strings.HasPrefix(number, "9") && len(number) == 10:
+		number = "0" + number
+	}
+	if len(number) != 11 || !strings.HasPrefix(number, "09") {
+		return ""
+	}
+	return number
+}
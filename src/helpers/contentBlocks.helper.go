@@ -0,0 +1,258 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/storage"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	maxContentBlocks   = 200
+	maxBlockTextLength = 5000
+	maxHeadingLength   = 200
+	maxCaptionLength   = 500
+	maxListItems       = 50
+)
+
+var (
+	// inline formatting only: bold, italics, underline, links and line breaks
+	inlinePolicy = func() *bluemonday.Policy {
+		p := bluemonday.NewPolicy()
+		p.AllowElements("b", "strong", "i", "em", "u", "br")
+		p.AllowStandardURLs()
+		p.AllowAttrs("href").OnElements("a")
+		p.RequireNoFollowOnLinks(true)
+		p.AddTargetBlankToFullyQualifiedLinks(true)
+		return p
+	}()
+
+	// plain text only
+	strictPolicy = bluemonday.StrictPolicy()
+
+	youtubeIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+	embedHosts = map[string]string{
+		"youtube.com":              "youtube",
+		"www.youtube.com":          "youtube",
+		"m.youtube.com":            "youtube",
+		"youtu.be":                 "youtube",
+		"www.youtube-nocookie.com": "youtube",
+		"open.spotify.com":         "spotify",
+		"www.facebook.com":         "facebook",
+		"www.instagram.com":        "instagram",
+		"www.tiktok.com":           "tiktok",
+	}
+
+	allowedImageExt = map[string]bool{
+		".webp": true, ".png": true, ".jpg": true, ".jpeg": true, ".avif": true,
+	}
+)
+
+// SanitizeContentBlocks validates every block and returns a sanitized copy.
+// The error names the offending block so editors can fix it.
+func SanitizeContentBlocks(blocks models.ContentBlocks) (models.ContentBlocks, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("content cannot be empty")
+	}
+	if len(blocks) > maxContentBlocks {
+		return nil, fmt.Errorf("content cannot have more than %d blocks", maxContentBlocks)
+	}
+
+	clean := make(models.ContentBlocks, 0, len(blocks))
+	for i, block := range blocks {
+		sanitized, err := sanitizeBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("block %d (%s): %w", i, block.Type, err)
+		}
+		clean = append(clean, sanitized)
+	}
+	return clean, nil
+}
+
+// PlainText strips any markup from s and returns the text itself, trimmed and
+// unescaped ("O'Brien", not "O&#39;Brien"); it is escaped again when rendered
+func PlainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(strictPolicy.Sanitize(s)))
+}
+
+// sanitizeBlock cleans one block: inline HTML fields are sanitized, plain text
+// fields (heading, cite, alt, credit) are stored as text
+func sanitizeBlock(block models.ContentBlock) (models.ContentBlock, error) {
+	switch block.Type {
+	case models.BlockParagraph:
+		text := strings.TrimSpace(inlinePolicy.Sanitize(block.Text))
+		if text == "" {
+			return block, errors.New("text is required")
+		}
+		if len(text) > maxBlockTextLength {
+			return block, errors.New("text is too long")
+		}
+		return models.ContentBlock{Type: block.Type, Text: text}, nil
+
+	case models.BlockHeading:
+		text := PlainText(block.Text)
+		if text == "" {
+			return block, errors.New("text is required")
+		}
+		if len(text) > maxHeadingLength {
+			return block, errors.New("text is too long")
+		}
+		level := block.Level
+		if level == 0 {
+			level = 2
+		}
+		if level < 2 || level > 4 {
+			return block, errors.New("level must be between 2 and 4")
+		}
+		return models.ContentBlock{Type: block.Type, Text: text, Level: level}, nil
+
+	case models.BlockQuote:
+		text := strings.TrimSpace(inlinePolicy.Sanitize(block.Text))
+		if text == "" {
+			return block, errors.New("text is required")
+		}
+		if len(text) > maxBlockTextLength {
+			return block, errors.New("text is too long")
+		}
+		cite := PlainText(block.Cite)
+		return models.ContentBlock{Type: block.Type, Text: text, Cite: cite}, nil
+
+	case models.BlockImage:
+		name := strings.TrimSpace(block.Image)
+		key, err := storage.Key(storage.Images, name)
+		if err != nil {
+			return block, errors.New("image must be a media store filename")
+		}
+		if !allowedImageExt[strings.ToLower(filepath.Ext(name))] {
+			return block, errors.New("unsupported image type")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err = storage.Default().Stat(ctx, key)
+		cancel()
+		if err != nil {
+			return block, errors.New("image not found in media store")
+		}
+		alt := PlainText(block.Alt)
+		if alt == "" {
+			return block, errors.New("alt text is required")
+		}
+		caption := strings.TrimSpace(inlinePolicy.Sanitize(block.Caption))
+		credit := PlainText(block.Credit)
+		if len(alt) > maxCaptionLength || len(caption) > maxCaptionLength || len(credit) > maxCaptionLength {
+			return block, errors.New("alt, caption or credit is too long")
+		}
+		return models.ContentBlock{Type: block.Type, Image: name, Alt: alt, Caption: caption, Credit: credit}, nil
+
+	case models.BlockEmbed:
+		provider, embedURL, err := normalizeEmbedURL(block.URL)
+		if err != nil {
+			return block, err
+		}
+		caption := strings.TrimSpace(inlinePolicy.Sanitize(block.Caption))
+		if len(caption) > maxCaptionLength {
+			return block, errors.New("caption is too long")
+		}
+		return models.ContentBlock{Type: block.Type, Provider: provider, URL: embedURL, Caption: caption}, nil
+
+	case models.BlockList:
+		if len(block.Items) == 0 {
+			return block, errors.New("list needs at least one item")
+		}
+		if len(block.Items) > maxListItems {
+			return block, fmt.Errorf("list cannot have more than %d items", maxListItems)
+		}
+		items := make([]string, 0, len(block.Items))
+		for _, item := range block.Items {
+			text := strings.TrimSpace(inlinePolicy.Sanitize(item))
+			if text == "" {
+				continue
+			}
+			if len(text) > maxBlockTextLength {
+				return block, errors.New("list item is too long")
+			}
+			items = append(items, text)
+		}
+		if len(items) == 0 {
+			return block, errors.New("list needs at least one item")
+		}
+		return models.ContentBlock{Type: block.Type, Ordered: block.Ordered, Items: items}, nil
+	}
+
+	return block, errors.New("unknown block type")
+}
+
+// normalizeEmbedURL checks the embed host against the allowlist.
+// YouTube links are rewritten to the privacy-enhanced embed player allowed by our CSP.
+func normalizeEmbedURL(raw string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" {
+		return "", "", errors.New("embed url must be https")
+	}
+
+	provider, ok := embedHosts[strings.ToLower(u.Host)]
+	if !ok {
+		return "", "", errors.New("embed provider is not allowed")
+	}
+
+	if provider != "youtube" {
+		u.RawQuery = ""
+		u.Fragment = ""
+		return provider, u.String(), nil
+	}
+
+	var videoID string
+	switch {
+	case u.Host == "youtu.be":
+		videoID = strings.Trim(u.Path, "/")
+	case strings.HasPrefix(u.Path, "/embed/"), strings.HasPrefix(u.Path, "/shorts/"):
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) == 2 {
+			videoID = parts[1]
+		}
+	default:
+		videoID = u.Query().Get("v")
+	}
+
+	if !youtubeIDRegex.MatchString(videoID) {
+		return "", "", errors.New("invalid YouTube video")
+	}
+	return provider, "https://www.youtube-nocookie.com/embed/" + videoID, nil
+}
+
+// SanitizeContentField validates a block document inside a partial update body
+// (e.g. updateData["content"]) and replaces it with the sanitized blocks.
+func SanitizeContentField(updateData map[string]interface{}, key string) error {
+	value, ok := updateData[key]
+	if !ok {
+		return nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var blocks models.ContentBlocks
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	clean, err := SanitizeContentBlocks(blocks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	updateData[key] = clean
+	return nil
+}
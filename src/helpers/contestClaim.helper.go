@@ -0,0 +1,19 @@
+package helpers
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+var contestClaimSigner = newIDSigner("CONTEST_CLAIM_SECRET", "super_secret_contest_claim_key", "contest-claim")
+
+// SignContestClaim returns the token a winner uses to claim their prize
+func SignContestClaim(winnerID, contestID primitive.ObjectID) string {
+	return contestClaimSigner.Sign(winnerID, contestID)
+}
+
+// VerifyContestClaim checks a claim token and returns the ids it was issued for
+func VerifyContestClaim(token string) (winnerID, contestID primitive.ObjectID, err error) {
+	ids, err := contestClaimSigner.Verify(token, 2)
+	if err != nil {
+		return winnerID, contestID, err
+	}
+	return ids[0], ids[1], nil
+}
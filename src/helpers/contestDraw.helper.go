@@ -0,0 +1,104 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+   Auditable contest draws
+   -----------------------------------
+   1. On creation a random 32 byte seed is stored and sha256(seed) is published.
+   2. At the draw, the eligible entry numbers are sorted and hashed:
+      entries_hash = hex(sha256("1\n4\n5\n..."))
+   3. Random numbers come from HMAC-SHA256(key=seed, msg=entries_hash ":" counter),
+      first 8 bytes big-endian, counter starting at 0. A number below m is taken
+      by rejecting values v >= M - (M mod m), M = 2^64-1, and using v mod m.
+   4. A partial Fisher-Yates shuffle of the sorted entry numbers picks the order:
+      for i := 0..k-1 { j := i + next(n-i); swap(i, j) }
+   After the draw the seed is published, so anyone can check it against the
+   committed hash and recompute the order.
+   -----------------------------------
+*/
+
+// NewContestSeed returns a random hex seed and its public commitment
+func NewContestSeed() (seed, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	seed = hex.EncodeToString(raw)
+	return seed, ContestSeedHash(seed), nil
+}
+
+// ContestSeedHash is the commitment published before the draw
+func ContestSeedHash(seed string) string {
+	raw, err := hex.DecodeString(seed)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ContestEntriesHash hashes the sorted eligible entry numbers
+func ContestEntriesHash(entryNumbers []int) string {
+	var b strings.Builder
+	for _, n := range entryNumbers {
+		b.WriteString(strconv.Itoa(n))
+		b.WriteByte('\n')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// ContestDrawOrder draws up to k of the sorted entryNumbers, in order
+func ContestDrawOrder(seed, entriesHash string, entryNumbers []int, k int) ([]int, error) {
+	key, err := hex.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed: %w", err)
+	}
+
+	pool := append([]int(nil), entryNumbers...)
+	k = min(k, len(pool))
+
+	counter := 0
+	next := func(m int) int {
+		limit := math.MaxUint64 - math.MaxUint64%uint64(m)
+		for {
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(entriesHash + ":" + strconv.Itoa(counter)))
+			counter++
+			v := binary.BigEndian.Uint64(mac.Sum(nil)[:8])
+			if v < limit {
+				return int(v % uint64(m))
+			}
+		}
+	}
+
+	for i := 0; i < k; i++ {
+		j := i + next(len(pool)-i)
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+	return pool[:k], nil
+}
+
+// MaskName shortens a full name for public lists: "Juan dela Cruz" -> "Juan C."
+func MaskName(name string) string {
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	}
+	last := []rune(parts[len(parts)-1])
+	return parts[0] + " " + strings.ToUpper(string(last[0])) + "."
+}
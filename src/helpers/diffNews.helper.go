@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"magic-server-2026/src/models"
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// fields that change on every write and are not part of a revision's content
+var ignoredNewsDiffFields = map[string]bool{
+	"_id":        true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// DiffNews returns the field-level changes needed to go from one news snapshot to another.
+// Fields are compared on their stored (bson) form so the names match the database.
+func DiffNews(from, to models.News) ([]models.NewsFieldChange, error) {
+	fromDoc, err := newsToDoc(from)
+	if err != nil {
+		return nil, err
+	}
+	toDoc, err := newsToDoc(to)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	for k := range fromDoc {
+		keys[k] = struct{}{}
+	}
+	for k := range toDoc {
+		keys[k] = struct{}{}
+	}
+
+	fields := make([]string, 0, len(keys))
+	for k := range keys {
+		if !ignoredNewsDiffFields[k] {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []models.NewsFieldChange{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(fromDoc[field], toDoc[field]) {
+			changes = append(changes, models.NewsFieldChange{
+				Field: field,
+				From:  fromDoc[field],
+				To:    toDoc[field],
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func newsToDoc(news models.News) (bson.M, error) {
+	raw, err := bson.Marshal(news)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
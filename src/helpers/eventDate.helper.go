@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"magic-server-2026/src/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	isoDatePrefix  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:[T\s]+(.*))?$`)
+	yearDatePrefix = regexp.MustCompile(`^(.*?\b\d{4})\b[\s,@-]*(?:at\s+)?(.*)$`)
+)
+
+// ParseEventDate reads free-text event dates from public forms, e.g. "2026-03-05",
+// "March 5, 2026 2PM", "3/5/2026 @ 14:00" or "2026-03-05T14:00". The result is in
+// Manila time; hasTime is false when only a date was given (midnight is returned).
+func ParseEventDate(value string) (at time.Time, hasTime bool, ok bool) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(time.RFC3339, value, utils.LocationAsiaManila); err == nil {
+		return t.In(utils.LocationAsiaManila), true, true
+	}
+
+	match := isoDatePrefix.FindStringSubmatch(value)
+	if match == nil {
+		match = yearDatePrefix.FindStringSubmatch(value)
+	}
+	if match == nil {
+		return at, false, false
+	}
+
+	day, ok := ParseLooseDate(strings.TrimRight(match[1], " ,"))
+	if !ok {
+		return at, false, false
+	}
+	rest := strings.TrimSpace(match[2])
+	if rest == "" {
+		return day, false, true
+	}
+	clock, ok := ParseClock(rest)
+	if !ok {
+		return day, false, true // trailing text that is not a time ("whole day", venue)
+	}
+	return day.Add(clock), true, true
+}
@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"fmt"
+	"html"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// icsTime is the UTC DATE-TIME form
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsEscape escapes TEXT values (RFC 5545 3.3.11); stored text is sanitized HTML, so entities are decoded first
+func icsEscape(text string) string {
+	text = html.UnescapeString(text)
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(text)
+}
+
+// icsLine writes a content line folded at 75 octets, never inside a UTF-8 sequence
+func icsLine(b *strings.Builder, name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// EventICS renders an event as a single-event iCalendar file. The UID is stable
+// and SEQUENCE follows the event's edits, so calendar apps update the copy
+// they imported instead of adding a second one.
+func EventICS(event models.Event) string {
+	host := "magic899.com"
+	if u, err := url.Parse(utils.ServerOrigin()); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	// keep paragraph breaks when the description is flattened to text
+	breaks := strings.NewReplacer("</p>", "\n", "<br>", "\n", "<br/>", "\n", "<br />", "\n")
+	var description []string
+	if text := strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(breaks.Replace(event.Description))); text != "" {
+		description = append(description, text)
+	}
+	if len(event.Lineup) > 0 {
+		names := make([]string, 0, len(event.Lineup))
+		for _, entry := range event.Lineup {
+			names = append(names, entry.Name)
+		}
+		description = append(description, "Lineup: "+strings.Join(names, ", "))
+	}
+	if tickets := EventTicketSummary(event.Tickets); tickets != "" {
+		description = append(description, "Tickets: "+tickets)
+	}
+	description = append(description, EventURL(event.Slug))
+
+	var location []string
+	for _, part := range []string{event.Venue.Name, event.Venue.Address, event.Venue.City} {
+		if part = strings.TrimSpace(part); part != "" {
+			location = append(location, part)
+		}
+	}
+
+	status := "CONFIRMED"
+	if event.Cancelled {
+		status = "CANCELLED"
+	}
+
+	var b strings.Builder
+	icsLine(&b, "BEGIN", "VCALENDAR")
+	icsLine(&b, "VERSION", "2.0")
+	icsLine(&b, "PRODID", "-//Magic 89.9//Events//EN")
+	icsLine(&b, "CALSCALE", "GREGORIAN")
+	icsLine(&b, "METHOD", "PUBLISH")
+	icsLine(&b, "BEGIN", "VEVENT")
+	icsLine(&b, "UID", event.ID.Hex()+"@"+host)
+	icsLine(&b, "DTSTAMP", icsTime(event.Updated_at.Time()))
+	icsLine(&b, "DTSTART", icsTime(event.Starts_at.Time()))
+	icsLine(&b, "DTEND", icsTime(event.Ends_at.Time()))
+	icsLine(&b, "SEQUENCE", strconv.Itoa(event.Sequence))
+	icsLine(&b, "STATUS", status)
+	icsLine(&b, "SUMMARY", icsEscape(event.Title))
+	icsLine(&b, "DESCRIPTION", icsEscape(strings.Join(description, "\n\n")))
+	if len(location) > 0 {
+		icsLine(&b, "LOCATION", icsEscape(strings.Join(location, ", ")))
+	}
+	if event.Venue.Lat != nil && event.Venue.Lng != nil {
+		icsLine(&b, "GEO", fmt.Sprintf("%.6f;%.6f", *event.Venue.Lat, *event.Venue.Lng))
+	}
+	icsLine(&b, "URL", EventURL(event.Slug))
+	if event.Series != "" {
+		icsLine(&b, "CATEGORIES", icsEscape(event.Series))
+	}
+	icsLine(&b, "END", "VEVENT")
+	icsLine(&b, "END", "VCALENDAR")
+	return b.String()
+}
+
+// EventTicketSummary describes ticket prices in a line, e.g. "PHP 500 - 1,500"
+func EventTicketSummary(tickets models.EventTickets) string {
+	var parts []string
+	switch {
+	case tickets.Free:
+		parts = append(parts, "Free")
+	case tickets.Price_min > 0 && tickets.Price_max > tickets.Price_min:
+		parts = append(parts, "PHP "+FormatPesos(tickets.Price_min)+" - "+FormatPesos(tickets.Price_max))
+	case tickets.Price_min > 0:
+		parts = append(parts, "PHP "+FormatPesos(tickets.Price_min))
+	}
+	if tickets.Url != "" {
+		parts = append(parts, tickets.Url)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatPesos writes whole pesos with thousands separators
+func FormatPesos(amount int) string {
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
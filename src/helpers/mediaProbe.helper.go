@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+/*
+   Media probing
+   -----------------------------------
+   Just enough container parsing to check uploads without ffprobe:
+   the duration of an MP4/MOV (moov > mvhd) and whether a file is MP3.
+   -----------------------------------
+*/
+
+var ErrUnsupportedMedia = errors.New("unsupported media format")
+
+// mp4Boxes walks the boxes in data and calls fn with each type and body
+func mp4Boxes(data []byte, fn func(kind string, body []byte) bool) error {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		kind := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0: // box runs to the end of the file
+			size = uint64(len(data))
+		case 1: // 64-bit size follows the type
+			if len(data) < 16 {
+				return ErrUnsupportedMedia
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return ErrUnsupportedMedia
+		}
+		if !fn(kind, data[header:size]) {
+			return nil
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// MP4Duration returns the presentation length of an MP4 or QuickTime file
+func MP4Duration(data []byte) (time.Duration, error) {
+	return MP4DurationAt(bytes.NewReader(data), int64(len(data)))
+}
+
+// MP4DurationAt is MP4Duration for a file that is not in memory: only the box
+// headers and the moov box are read, so a multi-gigabyte recording costs a few reads
+func MP4DurationAt(r io.ReaderAt, size int64) (time.Duration, error) {
+	var header [16]byte
+	for offset := int64(0); offset+8 <= size; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return 0, err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		kind := string(header[4:8])
+		if offset == 0 && (size < 12 || kind != "ftyp") {
+			return 0, ErrUnsupportedMedia
+		}
+		headerSize := int64(8)
+		switch boxSize {
+		case 0: // box runs to the end of the file
+			boxSize = size - offset
+		case 1: // 64-bit size follows the type
+			if offset+16 > size {
+				return 0, ErrUnsupportedMedia
+			}
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return 0, err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if boxSize < headerSize || boxSize > size-offset {
+			return 0, ErrUnsupportedMedia
+		}
+		if kind == "moov" {
+			if boxSize > mp4MaxMoovSize {
+				return 0, ErrUnsupportedMedia
+			}
+			moov := make([]byte, boxSize-headerSize)
+			if _, err := r.ReadAt(moov, offset+headerSize); err != nil {
+				return 0, err
+			}
+			return moovDuration(moov)
+		}
+		offset += boxSize
+	}
+	return 0, ErrUnsupportedMedia
+}
+
+// mp4MaxMoovSize bounds the index read into memory (hours of video stay in the low MBs)
+const mp4MaxMoovSize = 256 << 20
+
+// moovDuration reads the duration from the mvhd box inside moov
+func moovDuration(moov []byte) (time.Duration, error) {
+	var mvhd []byte
+	err := mp4Boxes(moov, func(kind string, body []byte) bool {
+		if kind == "mvhd" {
+			mvhd = body
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(mvhd) < 4 {
+		return 0, ErrUnsupportedMedia
+	}
+
+	var timescale, duration uint64
+	switch mvhd[0] { // version
+	case 0:
+		if len(mvhd) < 20 {
+			return 0, ErrUnsupportedMedia
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	case 1:
+		if len(mvhd) < 32 {
+			return 0, ErrUnsupportedMedia
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	default:
+		return 0, ErrUnsupportedMedia
+	}
+	if timescale == 0 {
+		return 0, ErrUnsupportedMedia
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// IsMP3 reports whether data starts like an MP3 file (ID3 tag or MPEG audio frame)
+func IsMP3(data []byte) bool {
+	if bytes.HasPrefix(data, []byte("ID3")) {
+		return true
+	}
+	// frame sync (11 bits), MPEG version not reserved, layer III
+	return len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 &&
+		data[1]&0x18 != 0x08 && data[1]&0x06 == 0x02
+}
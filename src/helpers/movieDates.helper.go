@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MovieReleaseTime is the start of a movie's release day in Asia/Manila, from Release_date
+func MovieReleaseTime(movie models.Movies) (time.Time, bool) {
+	return ParseLooseDate(movie.Release_date)
+}
+
+// MovieReleaseDay prefers the stored release_at and falls back to parsing
+// Release_date for movies that have not been backfilled yet
+func MovieReleaseDay(movie models.Movies) (time.Time, bool) {
+	if movie.Release_at != 0 {
+		return movie.Release_at.Time().In(utils.LocationAsiaManila), true
+	}
+	return MovieReleaseTime(movie)
+}
+
+// MovieScreeningTime is when a movie's advanced screening starts in Asia/Manila,
+// from Advanced_screening_date and Screening_time. Without a readable time the
+// screening is taken to start at the beginning of its day.
+func MovieScreeningTime(movie models.Movies) (time.Time, bool) {
+	day, ok := ParseLooseDate(movie.Advanced_screening_date)
+	if !ok {
+		return time.Time{}, false
+	}
+	if offset, ok := ParseClock(movie.Screening_time); ok {
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, utils.LocationAsiaManila).Add(offset), true
+	}
+	return day, true
+}
+
+// MovieDateFields returns the $set / $unset documents that bring release_at and
+// screening_at in line with the movie's date strings. Unreadable strings clear
+// the typed field rather than leaving a stale one behind.
+func MovieDateFields(movie models.Movies) (set, unset bson.M) {
+	set, unset = bson.M{}, bson.M{}
+	if release, ok := MovieReleaseTime(movie); ok {
+		set["release_at"] = primitive.NewDateTimeFromTime(release)
+	} else {
+		unset["release_at"] = ""
+	}
+	if screening, ok := MovieScreeningTime(movie); ok {
+		set["screening_at"] = primitive.NewDateTimeFromTime(screening)
+	} else {
+		unset["screening_at"] = ""
+	}
+	return set, unset
+}
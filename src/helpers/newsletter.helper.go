@@ -0,0 +1,46 @@
+package helpers
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+/*
+   Newsletter tokens
+   -----------------------------------
+   A confirmation token signs <subscriber id><confirm nonce>; the nonce is
+   replaced every time a link is sent, so only the newest link works, and
+   its timestamp lets the link expire. A subscription token signs the
+   subscriber id alone and never expires: it is what the one-click
+   unsubscribe and preference links carry.
+   -----------------------------------
+*/
+
+var newsletterConfirmSigner = newIDSigner("NEWSLETTER_SECRET", "super_secret_newsletter_key", "newsletter-confirm")
+
+var newsletterSubscriptionSigner = newIDSigner("NEWSLETTER_SECRET", "super_secret_newsletter_key", "newsletter-subscription")
+
+// SignNewsletterConfirm returns the token of a subscriber's confirmation link
+func SignNewsletterConfirm(subscriberID, nonce primitive.ObjectID) string {
+	return newsletterConfirmSigner.Sign(subscriberID, nonce)
+}
+
+// VerifyNewsletterConfirm checks a confirmation token and returns the subscriber id and nonce
+func VerifyNewsletterConfirm(token string) (subscriberID, nonce primitive.ObjectID, err error) {
+	ids, err := newsletterConfirmSigner.Verify(token, 2)
+	if err != nil {
+		return subscriberID, nonce, err
+	}
+	return ids[0], ids[1], nil
+}
+
+// SignNewsletterSubscription returns the token of a subscriber's unsubscribe and preference links
+func SignNewsletterSubscription(subscriberID primitive.ObjectID) string {
+	return newsletterSubscriptionSigner.Sign(subscriberID)
+}
+
+// VerifyNewsletterSubscription checks a subscription token and returns the subscriber id
+func VerifyNewsletterSubscription(token string) (primitive.ObjectID, error) {
+	ids, err := newsletterSubscriptionSigner.Verify(token, 1)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return ids[0], nil
+}
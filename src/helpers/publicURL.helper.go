@@ -0,0 +1,136 @@
+package helpers
+
+import (
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NewsSlug turns a title into the hyphenated form used by the frontend routes.
+// It round-trips through GetNewsByTitleAndCategory (hyphens -> spaces -> NormalizeName).
+func NewsSlug(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// NewsURL is the canonical public page of a news item
+func NewsURL(news models.News) string {
+	return utils.SiteOrigin() + "/news/" + url.PathEscape(strings.ToLower(news.Category)) + "/" + url.PathEscape(NewsSlug(news.Title))
+}
+
+// PublicImageURL resolves an image field (media store filename or absolute URL)
+// to a URL that clients without our RSP/Referer headers can fetch.
+func PublicImageURL(image string) string {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return ""
+	}
+	if strings.HasPrefix(image, "https://") || strings.HasPrefix(image, "http://") {
+		return image
+	}
+	return utils.ServerOrigin() + "/media/images/" + url.PathEscape(image)
+}
+
+// ImageVariantWidths are the widths media store images can be resized to (?w=)
+var ImageVariantWidths = []int{160, 320, 480, 640, 768, 960, 1280, 1600, 1920}
+
+// PublicImageSrcset lists the resized variants of a media store image up to its own width,
+// in the format srcset expects ("" for absolute URLs, which we cannot resize)
+func PublicImageSrcset(image string, width int, format string) string {
+	base := PublicImageURL(image)
+	if base == "" || !strings.HasPrefix(base, PublicImageBase()) {
+		return ""
+	}
+	var entries []string
+	for _, w := range ImageVariantWidths {
+		if w >= width {
+			break
+		}
+		entries = append(entries, base+"?w="+strconv.Itoa(w)+"&fmt="+format+" "+strconv.Itoa(w)+"w")
+	}
+	entries = append(entries, base+" "+strconv.Itoa(width)+"w")
+	return strings.Join(entries, ", ")
+}
+
+// PublicImageBase is the prefix for media store images in rendered HTML
+func PublicImageBase() string {
+	return utils.ServerOrigin() + "/media/images/"
+}
+
+// ShowURL is the public page of a show (GetByShowName matches the name without spaces)
+func ShowURL(show models.Shows) string {
+	if show.Show_name == nil {
+		return utils.SiteOrigin() + "/shows/" + show.ID.Hex()
+	}
+	return utils.SiteOrigin() + "/shows/" + url.PathEscape(strings.ReplaceAll(*show.Show_name, " ", ""))
+}
+
+// MovieURL is the public page of a movie
+func MovieURL(movie models.Movies) string {
+	return utils.SiteOrigin() + "/movies/" + movie.ID.Hex()
+}
+
+// MusicURL is the public chart entry of a track
+func MusicURL(music models.Music) string {
+	return utils.SiteOrigin() + "/music/" + music.ID.Hex()
+}
+
+// VideoURL is the public page of a Magic video
+func VideoURL(video models.MagicVideos) string {
+	return utils.SiteOrigin() + "/videos/" + video.ID.Hex()
+}
+
+// ShareCardURL is the 1200x630 share image of a chart entry ("music"), article ("news") or show ("shows")
+func ShareCardURL(kind, id string) string {
+	return utils.ServerOrigin() + "/share/" + kind + "/" + id + ".png"
+}
+
+// ScreeningPassURL is the QR code image of a screening pass token
+func ScreeningPassURL(token string) string {
+	return utils.ServerOrigin() + "/screening-passes/" + token + ".png"
+}
+
+// ContestClaimURL is the site page where a winner claims their prize
+func ContestClaimURL(contestID, token string) string {
+	return utils.SiteOrigin() + "/contests/" + contestID + "/claim?token=" + url.QueryEscape(token)
+}
+
+// NewsletterConfirmURL is the site page that confirms a newsletter signup
+func NewsletterConfirmURL(token string) string {
+	return utils.SiteOrigin() + "/newsletter/confirm?token=" + url.QueryEscape(token)
+}
+
+// NewsletterPreferencesURL is the site page where a subscriber picks topics or unsubscribes
+func NewsletterPreferencesURL(token string) string {
+	return utils.SiteOrigin() + "/newsletter/preferences?token=" + url.QueryEscape(token)
+}
+
+// NewsletterUnsubscribeURL is the RFC 8058 one-click unsubscribe endpoint (POST)
+func NewsletterUnsubscribeURL(token string) string {
+	return utils.ServerOrigin() + "/newsletter/unsubscribe/" + token
+}
+
+// AlbumURL is the public page of a photo album
+func AlbumURL(slug string) string {
+	return utils.SiteOrigin() + "/gallery/" + url.PathEscape(slug)
+}
+
+// AlbumDownloadURL is the ZIP download of a whole photo album
+func AlbumDownloadURL(slug string) string {
+	return utils.ServerOrigin() + "/albums/" + url.PathEscape(slug) + ".zip"
+}
+
+// EventURL is the public page of an event
+func EventURL(slug string) string {
+	return utils.SiteOrigin() + "/events/" + url.PathEscape(slug)
+}
+
+// EventCalendarURL is the .ics file of an event, for "add to calendar" links
+func EventCalendarURL(slug string) string {
+	return utils.ServerOrigin() + "/events/" + url.PathEscape(slug) + ".ics"
+}
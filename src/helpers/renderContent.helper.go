@@ -0,0 +1,96 @@
+package helpers
+
+import (
+	"html"
+	"magic-server-2026/src/models"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RenderContentHTML renders a block document as HTML for consumers that cannot
+// render blocks themselves (feeds, emails). imageBase is prefixed to image filenames.
+// Text is sanitized again since legacy paragraphs were stored unsanitized.
+func RenderContentHTML(blocks models.ContentBlocks, imageBase string) string {
+	var b strings.Builder
+	for _, block := range blocks {
+		switch block.Type {
+		case models.BlockParagraph:
+			b.WriteString("<p>" + inlinePolicy.Sanitize(block.Text) + "</p>\n")
+
+		case models.BlockHeading:
+			level := block.Level
+			if level < 2 || level > 4 {
+				level = 2
+			}
+			tag := "h" + strconv.Itoa(level)
+			b.WriteString("<" + tag + ">" + strictPolicy.Sanitize(block.Text) + "</" + tag + ">\n")
+
+		case models.BlockQuote:
+			b.WriteString("<blockquote><p>" + inlinePolicy.Sanitize(block.Text) + "</p>")
+			if block.Cite != "" {
+				b.WriteString("<cite>" + strictPolicy.Sanitize(block.Cite) + "</cite>")
+			}
+			b.WriteString("</blockquote>\n")
+
+		case models.BlockImage:
+			src := imageBase + url.PathEscape(block.Image)
+			b.WriteString(`<figure><img src="` + html.EscapeString(src) + `" alt="` + strictPolicy.Sanitize(block.Alt) + `">`)
+			if block.Caption != "" || block.Credit != "" {
+				b.WriteString("<figcaption>" + inlinePolicy.Sanitize(block.Caption))
+				if block.Credit != "" {
+					b.WriteString(" <small>" + strictPolicy.Sanitize(block.Credit) + "</small>")
+				}
+				b.WriteString("</figcaption>")
+			}
+			b.WriteString("</figure>\n")
+
+		case models.BlockEmbed:
+			// Feed readers strip iframes, so embeds are rendered as links
+			b.WriteString(`<p><a href="` + html.EscapeString(block.URL) + `">` + html.EscapeString(block.URL) + "</a></p>\n")
+
+		case models.BlockList:
+			tag := "ul"
+			if block.Ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">")
+			for _, item := range block.Items {
+				b.WriteString("<li>" + inlinePolicy.Sanitize(item) + "</li>")
+			}
+			b.WriteString("</" + tag + ">\n")
+		}
+	}
+	return b.String()
+}
+
+// ContentPlainText flattens a block document to plain text (summaries, search, metadata)
+func ContentPlainText(blocks models.ContentBlocks) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		switch block.Type {
+		case models.BlockParagraph, models.BlockHeading, models.BlockQuote:
+			parts = append(parts, strictPolicy.Sanitize(block.Text))
+		case models.BlockImage:
+			parts = append(parts, strictPolicy.Sanitize(block.Caption))
+		case models.BlockList:
+			for _, item := range block.Items {
+				parts = append(parts, strictPolicy.Sanitize(item))
+			}
+		}
+	}
+	return html.UnescapeString(strings.Join(strings.Fields(strings.Join(parts, " ")), " "))
+}
+
+// Summarize returns the first max runes of text, cut on a word boundary
+func Summarize(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > max/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
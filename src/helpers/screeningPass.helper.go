@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	qrcode "github.com/skip2/go-qrcode"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+   Screening passes
+   -----------------------------------
+   A pass token signs <registration id><movie id> (see signedIDs.helper.go).
+   It is what the QR code holds and what the door staff scan; the
+   registration's status decides whether it still admits anyone.
+   -----------------------------------
+*/
+
+var ErrInvalidScreeningPass = ErrInvalidSignedIDs
+
+var screeningPassSigner = newIDSigner("SCREENING_PASS_SECRET", "super_secret_screening_pass_key", "screening-pass")
+
+// SignScreeningPass returns the pass token of a registration
+func SignScreeningPass(registrationID, movieID primitive.ObjectID) string {
+	return screeningPassSigner.Sign(registrationID, movieID)
+}
+
+// VerifyScreeningPass checks a pass token's signature and returns the ids it was issued for
+func VerifyScreeningPass(token string) (registrationID, movieID primitive.ObjectID, err error) {
+	ids, err := screeningPassSigner.Verify(token, 2)
+	if err != nil {
+		return registrationID, movieID, err
+	}
+	return ids[0], ids[1], nil
+}
+
+// ScreeningPassQR renders a pass token as a size x size PNG QR code
+func ScreeningPassQR(token string, size int) ([]byte, error) {
+	return qrcode.Encode(token, qrcode.Medium, size)
+}
@@ -0,0 +1,163 @@
+package helpers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShowSchedule is a show's weekly airing slot parsed from its free-text day and time
+type ShowSchedule struct {
+	Days     []time.Weekday
+	Start    time.Duration // offset from midnight
+	Duration time.Duration
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var (
+	weekdayPattern  = regexp.MustCompile(`(?i)\b(sun|mon|tue|wed|thu|fri|sat)[a-z]*\b`)
+	clockPattern    = regexp.MustCompile(`(?i)(\d{1,2})(?::(\d{2}))?\s*(am|pm|nn|mn|noon|midnight)?`)
+	dayRangePattern = regexp.MustCompile(`(?i)\b(sun|mon|tue|wed|thu|fri|sat)[a-z]*\s*(?:-|–|to|thru|through)\s*(sun|mon|tue|wed|thu|fri|sat)[a-z]*\b`)
+	everydayPattern = regexp.MustCompile(`(?i)\b(daily|everyday|every day)\b`)
+	weekdaysPattern = regexp.MustCompile(`(?i)\bweekdays\b`)
+	weekendsPattern = regexp.MustCompile(`(?i)\bweekends?\b`)
+)
+
+// ParseShowSchedule reads values like "Monday - Friday" / "6:00 AM - 10:00 AM",
+// "Weekends" / "8PM-12MN" or "Tue, Thu" / "21:00 - 23:00". ok is false when
+// either part cannot be understood, so callers can leave the schedule out.
+func ParseShowSchedule(day, clock string) (schedule ShowSchedule, ok bool) {
+	schedule.Days = parseShowDays(day)
+	if len(schedule.Days) == 0 {
+		return schedule, false
+	}
+
+	matches := clockPattern.FindAllStringSubmatch(clock, 2)
+	if len(matches) != 2 {
+		return schedule, false
+	}
+	start, okStart := clockOffset(matches[0], matches[1][3])
+	end, okEnd := clockOffset(matches[1], "")
+	if !okStart || !okEnd {
+		return schedule, false
+	}
+	if end <= start {
+		end += 24 * time.Hour // runs past midnight
+	}
+
+	schedule.Start = start
+	schedule.Duration = end - start
+	return schedule, true
+}
+
+func parseShowDays(day string) []time.Weekday {
+	switch {
+	case everydayPattern.MatchString(day):
+		return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+	case weekdaysPattern.MatchString(day):
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	case weekendsPattern.MatchString(day):
+		return []time.Weekday{time.Saturday, time.Sunday}
+	}
+
+	seen := make(map[time.Weekday]bool)
+	var days []time.Weekday
+	add := func(d time.Weekday) {
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+
+	for _, r := range dayRangePattern.FindAllStringSubmatch(day, -1) {
+		from, to := weekdayNames[strings.ToLower(r[1])], weekdayNames[strings.ToLower(r[2])]
+		for d := from; ; d = (d + 1) % 7 {
+			add(d)
+			if d == to {
+				break
+			}
+		}
+	}
+	for _, m := range weekdayPattern.FindAllStringSubmatch(day, -1) {
+		add(weekdayNames[strings.ToLower(m[1])])
+	}
+	return days
+}
+
+// clockOffset converts a clockPattern match to a time of day. A missing
+// meridiem borrows the other end's ("6-9 AM"); none at all means 24h time.
+func clockOffset(match []string, fallbackMeridiem string) (time.Duration, bool) {
+	hour, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	meridiem := strings.ToLower(match[3])
+	if meridiem == "" {
+		meridiem = strings.ToLower(fallbackMeridiem)
+	}
+	switch meridiem {
+	case "am", "mn", "midnight":
+		if hour > 12 {
+			return 0, false
+		}
+		hour %= 12
+	case "pm", "nn", "noon":
+		if hour > 12 {
+			return 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 24 || minute > 59 {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// ParseClock reads a single time of day like "7:30 PM", "7PM", "12NN" or "19:30".
+// For ranges ("7PM - 10PM") the start is returned.
+func ParseClock(clock string) (time.Duration, bool) {
+	matches := clockPattern.FindAllStringSubmatch(clock, 2)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	fallback := ""
+	if len(matches) == 2 {
+		fallback = matches[1][3]
+	}
+	return clockOffset(matches[0], fallback)
+}
+
+// Next returns the next airing that has not ended yet, in loc
+func (s ShowSchedule) Next(now time.Time, loc *time.Location) (start, end time.Time) {
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	// start one day back so a show running past midnight is still "current"
+	for offset := -1; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, d := range s.Days {
+			if day.Weekday() != d {
+				continue
+			}
+			start = day.Add(s.Start)
+			end = start.Add(s.Duration)
+			if end.After(now) {
+				return start, end
+			}
+		}
+	}
+	return time.Time{}, time.Time{}
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+	"magic-server-2026/src/utils"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+   Signed id tokens
+   -----------------------------------
+   A token is a few ObjectIDs (12 bytes each) plus a truncated
+   HMAC-SHA256, base64url encoded, so it fits in a QR code or a link.
+   Each purpose has its own secret (env) and MAC prefix. The fallback
+   secrets are for development only: in production every secret must
+   be set, and CheckSigningSecrets stops the server at startup if one
+   is missing.
+   -----------------------------------
+*/
+
+const signedIDsMACSize = 16
+
+var ErrInvalidSignedIDs = errors.New("invalid signed token")
+
+type idSigner struct {
+	env      string // secret env var
+	fallback string // development secret when env is unset
+	purpose  string
+
+	once   sync.Once
+	secret []byte
+}
+
+// idSigners are every signer, for CheckSigningSecrets
+var idSigners []*idSigner
+
+func newIDSigner(env, fallback, purpose string) *idSigner {
+	s := &idSigner{env: env, fallback: fallback, purpose: purpose}
+	idSigners = append(idSigners, s)
+	return s
+}
+
+// CheckSigningSecrets stops the server when a token secret is missing in production
+func CheckSigningSecrets() {
+	if !utils.IsProduction() {
+		return
+	}
+	for _, s := range idSigners {
+		if utils.GetEnv(s.env) == "" {
+			log.Fatalf("[TOKENS] %s must be set in production", s.env)
+		}
+	}
+}
+
+func (s *idSigner) key() []byte {
+	s.once.Do(func() {
+		s.secret = []byte(utils.GetEnv(s.env))
+		if len(s.secret) == 0 {
+			if utils.IsProduction() {
+				log.Fatalf("[TOKENS] %s must be set in production", s.env)
+			}
+			log.Printf("[TOKENS] %s is not set, using the development secret", s.env)
+			s.secret = []byte(s.fallback)
+		}
+	})
+	return s.secret
+}
+
+func (s *idSigner) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.key())
+	h.Write([]byte(s.purpose + ":"))
+	h.Write(payload)
+	return h.Sum(nil)[:signedIDsMACSize]
+}
+
+// Sign returns the token of ids
+func (s *idSigner) Sign(ids ...primitive.ObjectID) string {
+	payload := make([]byte, 0, len(ids)*12+signedIDsMACSize)
+	for _, id := range ids {
+		payload = append(payload, id[:]...)
+	}
+	return base64.RawURLEncoding.EncodeToString(append(payload, s.mac(payload)...))
+}
+
+// Verify checks a token carrying n ids and returns them
+func (s *idSigner) Verify(token string, n int) ([]primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil || len(raw) != n*12+signedIDsMACSize {
+		return nil, ErrInvalidSignedIDs
+	}
+	payload, mac := raw[:n*12], raw[n*12:]
+	if !hmac.Equal(mac, s.mac(payload)) {
+		return nil, ErrInvalidSignedIDs
+	}
+	ids := make([]primitive.ObjectID, n)
+	for i := range ids {
+		copy(ids[i][:], payload[i*12:])
+	}
+	return ids, nil
+}
@@ -0,0 +1,212 @@
+package helpers
+
+import (
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+	"strings"
+	"time"
+)
+
+// Station details used in OpenGraph tags and schema.org JSON-LD
+const (
+	StationName        = "Magic 89.9"
+	StationCallSign    = "DWTM"
+	StationFrequency   = "89.9 MHz"
+	StationDescription = "Music, shows, news and movies from Magic 89.9."
+	StationArea        = "Metro Manila"
+)
+
+// StationLogoURL is an absolute logo URL for previews (SITE_LOGO_URL), empty when unset
+func StationLogoURL() string {
+	return strings.TrimSpace(utils.GetEnv("SITE_LOGO_URL"))
+}
+
+func stationRef() map[string]interface{} {
+	return map[string]interface{}{
+		"@type": "RadioStation",
+		"@id":   utils.SiteOrigin() + "/#station",
+		"name":  StationName,
+	}
+}
+
+// RadioStationLD describes the station itself
+func RadioStationLD() map[string]interface{} {
+	station := map[string]interface{}{
+		"@context":    "https://schema.org",
+		"@type":       "RadioStation",
+		"@id":         utils.SiteOrigin() + "/#station",
+		"name":        StationName,
+		"url":         utils.SiteOrigin() + "/",
+		"description": StationDescription,
+		"areaServed":  StationArea,
+		"address": map[string]interface{}{
+			"@type":           "PostalAddress",
+			"addressLocality": StationArea,
+			"addressCountry":  "PH",
+		},
+		"broadcastService": broadcastService(),
+	}
+	if logo := StationLogoURL(); logo != "" {
+		station["logo"] = logo
+		station["image"] = logo
+	}
+	return station
+}
+
+func broadcastService() map[string]interface{} {
+	return map[string]interface{}{
+		"@type":                "BroadcastService",
+		"name":                 StationName,
+		"callSign":             StationCallSign,
+		"broadcastFrequency":   StationFrequency,
+		"broadcastTimezone":    "Asia/Manila",
+		"broadcastDisplayName": StationName,
+		"areaServed":           StationArea,
+		"broadcaster":          stationRef(),
+	}
+}
+
+// NewsArticleLD describes a news item
+func NewsArticleLD(news models.News) map[string]interface{} {
+	article := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "NewsArticle",
+		"headline":         Summarize(news.Title, 110),
+		"description":      Summarize(ContentPlainText(news.Content), 200),
+		"url":              NewsURL(news),
+		"mainEntityOfPage": NewsURL(news),
+		"articleSection":   news.Category,
+		"datePublished":    news.Created_at.Time().UTC().Format(time.RFC3339),
+		"dateModified":     news.Updated_at.Time().UTC().Format(time.RFC3339),
+		"publisher":        publisherLD(),
+	}
+	if news.Writer != "" {
+		article["author"] = map[string]interface{}{"@type": "Person", "name": news.Writer}
+	}
+	if image := PublicImageURL(news.News_Image); image != "" {
+		article["image"] = []string{image}
+	}
+	if len(news.Tags) > 0 {
+		article["keywords"] = strings.Join(news.Tags, ", ")
+	}
+	return article
+}
+
+func publisherLD() map[string]interface{} {
+	publisher := map[string]interface{}{
+		"@type": "Organization",
+		"name":  StationName,
+		"url":   utils.SiteOrigin() + "/",
+	}
+	if logo := StationLogoURL(); logo != "" {
+		publisher["logo"] = map[string]interface{}{"@type": "ImageObject", "url": logo}
+	}
+	return publisher
+}
+
+// BroadcastEventLD describes the next airing of a show. Dates are left out
+// when the show's day/time text cannot be parsed.
+func BroadcastEventLD(show models.Shows, now time.Time) map[string]interface{} {
+	name := derefString(show.Show_name)
+	event := map[string]interface{}{
+		"@context":            "https://schema.org",
+		"@type":               "BroadcastEvent",
+		"name":                name,
+		"description":         Summarize(ContentPlainText(show.Show_desc), 200),
+		"url":                 ShowURL(show),
+		"publishedOn":         broadcastService(),
+		"location":            stationRef(),
+		"eventStatus":         "https://schema.org/EventScheduled",
+		"eventAttendanceMode": "https://schema.org/OnlineEventAttendanceMode",
+		"organizer":           publisherLD(),
+	}
+	if host := derefString(show.Show_host); host != "" {
+		event["performer"] = map[string]interface{}{"@type": "Person", "name": host}
+	}
+	if image := PublicImageURL(show.Show_Image); image != "" {
+		event["image"] = []string{image}
+	}
+
+	if schedule, ok := ParseShowSchedule(derefString(show.Show_day), derefString(show.Show_time)); ok {
+		if start, end := schedule.Next(now, utils.LocationAsiaManila); !start.IsZero() {
+			event["startDate"] = start.Format(time.RFC3339)
+			event["endDate"] = end.Format(time.RFC3339)
+			event["isLiveBroadcast"] = !now.Before(start)
+		}
+	}
+	return event
+}
+
+// MovieLD describes a movie
+func MovieLD(movie models.Movies) map[string]interface{} {
+	ld := map[string]interface{}{
+		"@context":    "https://schema.org",
+		"@type":       "Movie",
+		"name":        movie.Title,
+		"description": Summarize(ContentPlainText(movie.Content), 200),
+		"url":         MovieURL(movie),
+	}
+	if image := PublicImageURL(movie.Movie_Image); image != "" {
+		ld["image"] = image
+	}
+	if len(movie.Category) > 0 {
+		ld["genre"] = movie.Category
+	}
+	if movie.Directed_by != "" {
+		ld["director"] = map[string]interface{}{"@type": "Person", "name": movie.Directed_by}
+	}
+	if len(movie.Cast) > 0 {
+		actors := make([]map[string]interface{}, 0, len(movie.Cast))
+		for _, name := range movie.Cast {
+			actors = append(actors, map[string]interface{}{"@type": "Person", "name": name})
+		}
+		ld["actor"] = actors
+	}
+	if movie.Rated != "" {
+		ld["contentRating"] = movie.Rated
+	}
+	if released, ok := MovieReleaseDay(movie); ok {
+		ld["datePublished"] = released.Format("2006-01-02")
+	}
+	if movie.Review_count > 0 {
+		ld["aggregateRating"] = map[string]interface{}{
+			"@type":       "AggregateRating",
+			"ratingValue": movie.Rating,
+			"ratingCount": movie.Review_count,
+			"bestRating":  5,
+			"worstRating": 1,
+		}
+	}
+	return ld
+}
+
+// looseDateLayouts are the formats staff type release dates in
+var looseDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"January 2, 2006",
+	"January 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"2 January 2006",
+	"01/02/2006",
+	"1/2/2006",
+}
+
+// ParseLooseDate parses a date typed in one of the common layouts
+func ParseLooseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range looseDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, utils.LocationAsiaManila); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+var talentApplicationSigner = newIDSigner("TALENT_APPLICATION_SECRET", "super_secret_talent_application_key", "talent-application")
+
+// SignTalentApplication returns the token an applicant uses to upload media and submit
+func SignTalentApplication(applicantID primitive.ObjectID) string {
+	return talentApplicationSigner.Sign(applicantID)
+}
+
+// VerifyTalentApplication checks an application token and returns the applicant id
+func VerifyTalentApplication(token string) (primitive.ObjectID, error) {
+	ids, err := talentApplicationSigner.Verify(token, 1)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return ids[0], nil
+}
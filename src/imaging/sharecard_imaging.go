@@ -0,0 +1,290 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+/*
+   Share cards
+   -----------------------------------
+   1200x630 branded images for link previews: artwork on the left,
+   kicker / title / subtitle on the right and the station mark below.
+   -----------------------------------
+*/
+
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+
+	// bump when the layout changes so cached cards are re-rendered
+	CardLayoutVersion = "1"
+
+	// MaxSourcePixels guards against decompression bombs in artwork
+	MaxSourcePixels = 40_000_000
+)
+
+var (
+	cardTop      = color.NRGBA{0x24, 0x0b, 0x3f, 0xff}
+	cardBottom   = color.NRGBA{0x8a, 0x1c, 0x7c, 0xff}
+	cardAccent   = color.NRGBA{0xff, 0xc8, 0x3d, 0xff}
+	cardTitle    = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	cardSubtitle = color.NRGBA{0xe8, 0xdc, 0xf4, 0xff}
+	cardMuted    = color.NRGBA{0xc9, 0xb6, 0xdd, 0xff}
+)
+
+// Card is the text and artwork of a share card
+type Card struct {
+	Kicker   string // e.g. "#3 · Local chart", "News · Music"
+	Title    string
+	Subtitle string
+	Footer   string // usually the site host
+	Artwork  image.Image
+}
+
+// Formats a card can be encoded in
+const (
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// ContentType returns the MIME type of an image format
+func ContentType(format string) string {
+	switch format {
+	case FormatWebP:
+		return "image/webp"
+	case FormatJPEG:
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Encode writes img as PNG or lossless WebP
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatPNG:
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		return encoder.Encode(w, img)
+	case FormatWebP:
+		return EncodeWebP(w, img)
+	}
+	return errors.New("unsupported image format: " + format)
+}
+
+// DecodeImage decodes JPEG, PNG, GIF or WebP data, refusing oversized images
+func DecodeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxSourcePixels {
+		return nil, errors.New("image dimensions out of range")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+var (
+	logoOnce sync.Once
+	logo     image.Image
+)
+
+// stationLogo loads SHARE_CARD_LOGO (a local PNG/JPEG) once; nil means draw the wordmark
+func stationLogo() image.Image {
+	logoOnce.Do(func() {
+		path := strings.TrimSpace(os.Getenv("SHARE_CARD_LOGO"))
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Println("[SHARE] logo not loaded:", err)
+			return
+		}
+		if logo, err = DecodeImage(data); err != nil {
+			log.Println("[SHARE] logo not decoded:", err)
+		}
+	})
+	return logo
+}
+
+// RenderCard draws a share card
+func RenderCard(card Card) (*image.NRGBA, error) {
+	img := image.NewNRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	drawGradient(img, cardTop, cardBottom)
+
+	textLeft, textRight := 80, CardWidth-80
+	if card.Artwork != nil {
+		const size, x, y, radius = 470, 70, 80, 28
+		art := coverSquare(card.Artwork, size)
+		draw.DrawMask(img, image.Rect(x, y, x+size, y+size), art, image.Point{}, roundedMask{size, size, radius}, image.Point{}, draw.Over)
+		textLeft = x + size + 60
+	}
+	textWidthPx := textRight - textLeft
+
+	kickerFace, err := newFace(true, 26)
+	if err != nil {
+		return nil, err
+	}
+	titleFace, err := newFace(true, 58)
+	if err != nil {
+		return nil, err
+	}
+	subtitleFace, err := newFace(false, 30)
+	if err != nil {
+		return nil, err
+	}
+	markFace, err := newFace(true, 36)
+	if err != nil {
+		return nil, err
+	}
+	footerFace, err := newFace(false, 22)
+	if err != nil {
+		return nil, err
+	}
+
+	y := 130
+	if card.Kicker != "" {
+		lines, _ := wrapText(kickerFace, strings.ToUpper(card.Kicker), textWidthPx, 1)
+		for _, line := range lines {
+			drawText(img, kickerFace, cardAccent, textLeft, y, line)
+		}
+		// accent rule under the kicker
+		draw.Draw(img, image.Rect(textLeft, y+18, textLeft+64, y+24), image.NewUniform(cardAccent), image.Point{}, draw.Src)
+		y += 90
+	} else {
+		y += 40
+	}
+
+	titleLines, _ := wrapText(titleFace, card.Title, textWidthPx, 4)
+	if len(titleLines) > 3 {
+		// long titles use a smaller size rather than losing words
+		if smaller, err := newFace(true, 46); err == nil {
+			titleFace = smaller
+			titleLines, _ = wrapText(titleFace, card.Title, textWidthPx, 4)
+		}
+	}
+	lineHeight := titleFace.Metrics().Height.Ceil() + 4
+	for _, line := range titleLines {
+		drawText(img, titleFace, cardTitle, textLeft, y, line)
+		y += lineHeight
+	}
+
+	if card.Subtitle != "" {
+		y += 10
+		subtitleLines, _ := wrapText(subtitleFace, card.Subtitle, textWidthPx, 2)
+		for _, line := range subtitleLines {
+			drawText(img, subtitleFace, cardSubtitle, textLeft, y, line)
+			y += subtitleFace.Metrics().Height.Ceil() + 4
+		}
+	}
+
+	// station mark along the bottom
+	baseline := CardHeight - 60
+	markX := textLeft
+	if l := stationLogo(); l != nil {
+		const logoHeight = 64
+		b := l.Bounds()
+		w := b.Dx() * logoHeight / max(1, b.Dy())
+		scaled := image.NewNRGBA(image.Rect(0, 0, w, logoHeight))
+		xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), l, b, draw.Over, nil)
+		draw.Draw(img, image.Rect(markX, baseline-logoHeight+12, markX+w, baseline+12), scaled, image.Point{}, draw.Over)
+		markX += w + 20
+	} else {
+		drawText(img, markFace, cardTitle, markX, baseline, "MAGIC 89.9")
+		markX += textWidth(markFace, "MAGIC 89.9") + 20
+	}
+	if card.Footer != "" {
+		drawText(img, footerFace, cardMuted, markX, baseline, card.Footer)
+	}
+
+	return img, nil
+}
+
+// drawGradient fills img with a diagonal gradient from top-left to bottom-right
+func drawGradient(img *image.NRGBA, from, to color.NRGBA) {
+	b := img.Bounds()
+	span := b.Dx() + b.Dy()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[(y-b.Min.Y)*img.Stride:]
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// quantize so flat bands compress well
+			t := ((x + y) * 64 / span)
+			mix := func(a, b uint8) uint8 { return uint8(int(a) + (int(b)-int(a))*t/64) }
+			i := (x - b.Min.X) * 4
+			row[i] = mix(from.R, to.R)
+			row[i+1] = mix(from.G, to.G)
+			row[i+2] = mix(from.B, to.B)
+			row[i+3] = 0xff
+		}
+	}
+}
+
+// coverSquare scales and center-crops src to a size x size square
+func coverSquare(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	crop := b
+	if b.Dx() > b.Dy() {
+		offset := (b.Dx() - b.Dy()) / 2
+		crop = image.Rect(b.Min.X+offset, b.Min.Y, b.Min.X+offset+b.Dy(), b.Max.Y)
+	} else if b.Dy() > b.Dx() {
+		offset := (b.Dy() - b.Dx()) / 2
+		crop = image.Rect(b.Min.X, b.Min.Y+offset, b.Max.X, b.Min.Y+offset+b.Dx())
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// roundedMask is an alpha mask of a w x h rectangle with rounded corners
+type roundedMask struct {
+	w, h, r int
+}
+
+func (m roundedMask) ColorModel() color.Model { return color.AlphaModel }
+
+func (m roundedMask) Bounds() image.Rectangle { return image.Rect(0, 0, m.w, m.h) }
+
+func (m roundedMask) At(x, y int) color.Color {
+	// work with pixel centers so both sides of the rectangle round the same way
+	px, py, r := float64(x)+0.5, float64(y)+0.5, float64(m.r)
+	cx, cy := px, py
+	switch {
+	case px < r:
+		cx = r
+	case px > float64(m.w)-r:
+		cx = float64(m.w) - r
+	}
+	switch {
+	case py < r:
+		cy = r
+	case py > float64(m.h)-r:
+		cy = float64(m.h) - r
+	}
+
+	// distance from the corner circle's center, with a one pixel soft edge
+	d := math.Hypot(px-cx, py-cy)
+	switch {
+	case d <= r-0.5:
+		return color.Alpha{0xff}
+	case d >= r+0.5:
+		return color.Alpha{0}
+	}
+	return color.Alpha{uint8(255 * (r + 0.5 - d))}
+}
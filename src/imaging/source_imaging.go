@@ -0,0 +1,77 @@
+package imaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	// MaxSourceBytes caps artwork downloads and files
+	MaxSourceBytes = 10 << 20
+	fetchTimeout   = 5 * time.Second
+)
+
+// publicOnlyClient refuses to connect to loopback, private and link-local
+// addresses, so staff-entered artwork URLs cannot reach internal services.
+var publicOnlyClient = &http.Client{
+	Timeout: fetchTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: fetchTimeout,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(host)
+				if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+					ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+					return fmt.Errorf("refusing to fetch from %s", host)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout:   fetchTimeout,
+		ResponseHeaderTimeout: fetchTimeout,
+		MaxIdleConns:          4,
+		IdleConnTimeout:       30 * time.Second,
+	},
+}
+
+// FetchImage downloads and decodes an https image
+func FetchImage(ctx context.Context, rawURL string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "https" {
+		return nil, errors.New("only https images can be fetched")
+	}
+	req.Header.Set("Accept", "image/webp,image/png,image/jpeg,image/gif")
+
+	resp, err := publicOnlyClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image fetch failed: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSourceBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSourceBytes {
+		return nil, errors.New("image too large")
+	}
+	return DecodeImage(data)
+}
@@ -0,0 +1,117 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	fontsOnce   sync.Once
+	fontRegular *opentype.Font
+	fontBold    *opentype.Font
+	fontsErr    error
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		if fontRegular, fontsErr = opentype.Parse(goregular.TTF); fontsErr != nil {
+			return
+		}
+		fontBold, fontsErr = opentype.Parse(gobold.TTF)
+	})
+	return fontsErr
+}
+
+// newFace returns a face at size px. Faces are not safe for concurrent use,
+// so every render makes its own.
+func newFace(bold bool, size float64) (font.Face, error) {
+	if err := loadFonts(); err != nil {
+		return nil, err
+	}
+	f := fontRegular
+	if bold {
+		f = fontBold
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// wrapText breaks text into at most maxLines lines no wider than width,
+// ending the last line with an ellipsis when the text does not fit.
+func wrapText(face font.Face, text string, width, maxLines int) (lines []string, truncated bool) {
+	limit := fixed.I(width)
+	words := strings.Fields(text)
+
+	var line string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= limit {
+			line = candidate
+			continue
+		}
+
+		if line == "" {
+			// a single word wider than the line: cut it and carry the rest over
+			cut := fitRunes(face, word, limit)
+			lines = append(lines, cut)
+			words[i] = word[len(cut):]
+		} else {
+			lines = append(lines, line)
+			line = ""
+		}
+		i-- // retry the (rest of the) word on the next line
+		if len(lines) == maxLines {
+			return ellipsize(face, lines, limit), true
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines, false
+}
+
+func fitRunes(face font.Face, word string, limit fixed.Int26_6) string {
+	runes := []rune(word)
+	n := len(runes)
+	for n > 1 && font.MeasureString(face, string(runes[:n])) > limit {
+		n--
+	}
+	return string(runes[:n])
+}
+
+func ellipsize(face font.Face, lines []string, limit fixed.Int26_6) []string {
+	last := []rune(strings.TrimRight(lines[len(lines)-1], " "))
+	for len(last) > 0 && font.MeasureString(face, string(last)+"…") > limit {
+		last = last[:len(last)-1]
+	}
+	lines[len(lines)-1] = strings.TrimRight(string(last), " ,.;:-") + "…"
+	return lines
+}
+
+// drawText draws one line with its baseline at y
+func drawText(dst draw.Image, face font.Face, c color.Color, x, y int, text string) {
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+// textWidth is the advance of text in pixels
+func textWidth(face font.Face, text string) int {
+	return font.MeasureString(face, text).Ceil()
+}
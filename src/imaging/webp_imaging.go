@@ -0,0 +1,669 @@
+package imaging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+)
+
+/*
+   Lossless WebP (VP8L) encoder
+   -----------------------------------
+   golang.org/x/image/webp can only decode. This writes a minimal but valid
+   VP8L stream: subtract-green + predictor transforms, one Huffman code
+   per channel, greedy LZ77 copies and no color cache. Generated graphics
+   (flat colors, gradients, text) compress about as well as with PNG.
+   -----------------------------------
+*/
+
+const (
+	vp8lMaxSize         = 1 << 14
+	vp8lMaxCodeLength   = 15
+	vp8lMaxLengthLength = 7
+	vp8lPredictorBits   = 5 // 32x32 predictor blocks
+	vp8lGreenAlphabet   = 256 + 24
+	vp8lDistAlphabet    = 40
+	vp8lMinCopy         = 3
+	vp8lMaxCopy         = 4096
+	vp8lWindow          = 1 << 18 // pixels searched back for copies
+	vp8lHashBits        = 16
+	vp8lChainDepth      = 32
+)
+
+// vp8lDistanceMap holds the (dy<<4 | 8-dx) neighbourhood offsets behind distance codes 1-120
+var vp8lDistanceMap = [120]uint8{
+	0x18, 0x07, 0x17, 0x19, 0x28, 0x06, 0x27, 0x29, 0x16, 0x1a,
+	0x26, 0x2a, 0x38, 0x05, 0x37, 0x39, 0x15, 0x1b, 0x36, 0x3a,
+	0x25, 0x2b, 0x48, 0x04, 0x47, 0x49, 0x14, 0x1c, 0x35, 0x3b,
+	0x46, 0x4a, 0x24, 0x2c, 0x58, 0x45, 0x4b, 0x34, 0x3c, 0x03,
+	0x57, 0x59, 0x13, 0x1d, 0x56, 0x5a, 0x23, 0x2d, 0x44, 0x4c,
+	0x55, 0x5b, 0x33, 0x3d, 0x68, 0x02, 0x67, 0x69, 0x12, 0x1e,
+	0x66, 0x6a, 0x22, 0x2e, 0x54, 0x5c, 0x43, 0x4d, 0x65, 0x6b,
+	0x32, 0x3e, 0x78, 0x01, 0x77, 0x79, 0x53, 0x5d, 0x11, 0x1f,
+	0x64, 0x6c, 0x42, 0x4e, 0x76, 0x7a, 0x21, 0x2f, 0x75, 0x7b,
+	0x31, 0x3f, 0x63, 0x6d, 0x52, 0x5e, 0x00, 0x74, 0x7c, 0x41,
+	0x4f, 0x10, 0x20, 0x62, 0x6e, 0x30, 0x73, 0x7d, 0x51, 0x5f,
+	0x40, 0x72, 0x7e, 0x61, 0x6f, 0x50, 0x71, 0x7f, 0x60, 0x70,
+}
+
+// order in which code length code lengths are stored
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img as a lossless WebP file
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxSize || height > vp8lMaxSize {
+		return errors.New("webp: image size out of range")
+	}
+
+	rgba, ok := img.(*image.NRGBA)
+	if !ok || rgba.Rect.Min != (image.Point{}) {
+		rgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	}
+
+	opaque := true
+	argb := make([]uint32, width*height)
+	for y := 0; y < height; y++ {
+		row := rgba.Pix[y*rgba.Stride:]
+		for x := 0; x < width; x++ {
+			p := row[x*4 : x*4+4]
+			if p[3] != 0xff {
+				opaque = false
+			}
+			argb[y*width+x] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+		}
+	}
+
+	residuals, modes := vp8lTransform(argb, width, height)
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if opaque {
+		bw.write(0, 1)
+	} else {
+		bw.write(1, 1)
+	}
+	bw.write(0, 3) // version
+
+	// transforms, in the order the encoder applied them
+	bw.write(1, 1)
+	bw.write(2, 2) // subtract green
+	bw.write(1, 1)
+	bw.write(0, 2) // predictor
+	bw.write(vp8lPredictorBits-2, 3)
+	bw.writeImageData(modes, vp8lSubSize(width), false)
+	bw.write(0, 1) // no more transforms
+
+	bw.writeImageData(residuals, width, true)
+
+	data := bw.flush()
+	chunk := len(data)
+	pad := chunk & 1
+
+	bufw := bufio.NewWriter(w)
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+chunk+pad))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(chunk))
+	bufw.Write(header)
+	bufw.Write(data)
+	if pad == 1 {
+		bufw.WriteByte(0)
+	}
+	return bufw.Flush()
+}
+
+func vp8lSubSize(size int) int {
+	return (size + 1<<vp8lPredictorBits - 1) >> vp8lPredictorBits
+}
+
+// predictor modes tried per block (see the VP8L spec for the full list)
+var vp8lPredictorModes = []uint32{1, 2, 4, 7, 11}
+
+// vp8lTransform applies subtract-green then the predictor, picking per block
+// the mode with the smallest residuals. It returns residuals and the mode image.
+func vp8lTransform(argb []uint32, width, height int) ([]uint32, []uint32) {
+	green := make([]uint32, len(argb))
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p>>16)&0xff - g) & 0xff
+		b := (p&0xff - g) & 0xff
+		green[i] = p&0xff00ff00 | r<<16 | b
+	}
+
+	subWidth, subHeight := vp8lSubSize(width), vp8lSubSize(height)
+	modes := make([]uint32, subWidth*subHeight)
+	out := make([]uint32, len(green))
+	block := 1 << vp8lPredictorBits
+
+	for by := 0; by < subHeight; by++ {
+		for bx := 0; bx < subWidth; bx++ {
+			x0, y0 := bx*block, by*block
+			x1, y1 := min(x0+block, width), min(y0+block, height)
+
+			best, bestCost := vp8lPredictorModes[0], -1
+			for _, mode := range vp8lPredictorModes {
+				cost := 0
+				for y := y0; y < y1 && (bestCost < 0 || cost < bestCost); y++ {
+					for x := x0; x < x1; x++ {
+						cost += vp8lResidualCost(vp8lSub(green[y*width+x], vp8lPredict(green, width, x, y, mode)))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+
+			modes[by*subWidth+bx] = 0xff000000 | best<<8
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					i := y*width + x
+					out[i] = vp8lSub(green[i], vp8lPredict(green, width, x, y, best))
+				}
+			}
+		}
+	}
+	return out, modes
+}
+
+// vp8lPredict returns the prediction for pixel (x, y); border pixels use fixed rules
+func vp8lPredict(pixels []uint32, width, x, y int, mode uint32) uint32 {
+	i := y*width + x
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return pixels[i-1]
+	case x == 0:
+		return pixels[i-width]
+	}
+
+	left, top, topLeft := pixels[i-1], pixels[i-width], pixels[i-width-1]
+	switch mode {
+	case 1:
+		return left
+	case 2:
+		return top
+	case 4:
+		return topLeft
+	case 7:
+		return vp8lAverage(left, top)
+	case 11:
+		return vp8lSelect(left, top, topLeft)
+	}
+	return 0xff000000
+}
+
+func vp8lAverage(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func vp8lSelect(left, top, topLeft uint32) uint32 {
+	distL, distT := 0, 0
+	for shift := 0; shift < 32; shift += 8 {
+		l, t, tl := int(left>>shift&0xff), int(top>>shift&0xff), int(topLeft>>shift&0xff)
+		estimate := l + t - tl
+		distL += abs(estimate - l)
+		distT += abs(estimate - t)
+	}
+	if distL < distT {
+		return left
+	}
+	return top
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// vp8lResidualCost approximates how expensive a residual is to code
+func vp8lResidualCost(p uint32) int {
+	cost := 0
+	for shift := 0; shift < 32; shift += 8 {
+		cost += abs(int(int8(p >> shift)))
+	}
+	return cost
+}
+
+// vp8lSub subtracts per channel, modulo 256
+func vp8lSub(a, b uint32) uint32 {
+	alphaGreen := 0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)
+	redBlue := 0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)
+	return alphaGreen&0xff00ff00 | redBlue&0x00ff00ff
+}
+
+// ---------------------------
+// Bit writer and prefix codes
+// ---------------------------
+
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+// write appends the low n bits of v, least significant bit first
+func (bw *bitWriter) write(v uint32, n uint) {
+	bw.acc |= uint64(v) << bw.nbits
+	bw.nbits += n
+	for bw.nbits >= 8 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc >>= 8
+		bw.nbits -= 8
+	}
+}
+
+func (bw *bitWriter) flush() []byte {
+	if bw.nbits > 0 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc, bw.nbits = 0, 0
+	}
+	return bw.buf
+}
+
+// prefixCode maps symbols to bit-reversed canonical Huffman codes
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (pc *prefixCode) put(bw *bitWriter, symbol int) {
+	if n := pc.lengths[symbol]; n > 0 {
+		bw.write(pc.codes[symbol], uint(n))
+	}
+}
+
+// vp8lPrefix splits a copy length or distance code into prefix symbol and extra bits
+func vp8lPrefix(value int) (symbol int, extraBits uint, extra uint32) {
+	d := value - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	highest := 0
+	for d>>(highest+1) != 0 {
+		highest++
+	}
+	second := (d >> (highest - 1)) & 1
+	extraBits = uint(highest - 1)
+	return 2*highest + second, extraBits, uint32(d & (1<<extraBits - 1))
+}
+
+// vp8lToken is either a literal pixel or a copy of length pixels; dist is the distance code
+type vp8lToken struct {
+	pixel  uint32
+	length int
+	dist   int
+}
+
+// vp8lDistanceCodes maps linear distances that have a short neighbourhood code to that code
+func vp8lDistanceCodes(width int) map[int]int {
+	codes := make(map[int]int, len(vp8lDistanceMap))
+	for code := len(vp8lDistanceMap); code >= 1; code-- {
+		entry := int(vp8lDistanceMap[code-1])
+		if d := (entry>>4)*width + 8 - entry&0xf; d >= 1 {
+			codes[d] = code // lower codes win
+		}
+	}
+	return codes
+}
+
+// vp8lTokens greedily replaces repeated pixel runs by copies, using hash chains
+func vp8lTokens(pixels []uint32, width int) []vp8lToken {
+	n := len(pixels)
+	near := vp8lDistanceCodes(width)
+
+	head := make([]int32, 1<<vp8lHashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, n)
+	hash := func(i int) uint32 {
+		return (pixels[i]*0x1e35a7bd ^ pixels[i+1]*0x9e3779b1 ^ pixels[i+2]*0x85ebca6b) >> (32 - vp8lHashBits)
+	}
+	insert := func(i int) {
+		if i+2 < n {
+			h := hash(i)
+			prev[i] = head[h]
+			head[h] = int32(i)
+		}
+	}
+	matchLength := func(i, j int) int {
+		limit := min(vp8lMaxCopy, n-i)
+		l := 0
+		for l < limit && pixels[i+l] == pixels[j+l] {
+			l++
+		}
+		return l
+	}
+
+	var tokens []vp8lToken
+	for i := 0; i < n; {
+		bestLength, bestDistance := 0, 0
+
+		// the left and upper pixels have the cheapest codes, try them first
+		for _, d := range [2]int{1, width} {
+			if i >= d {
+				if l := matchLength(i, i-d); l > bestLength {
+					bestLength, bestDistance = l, d
+				}
+			}
+		}
+		if i+2 < n && bestLength < vp8lMaxCopy {
+			for j, depth := head[hash(i)], 0; j >= 0 && depth < vp8lChainDepth && i-int(j) <= vp8lWindow; j, depth = prev[j], depth+1 {
+				if l := matchLength(i, int(j)); l > bestLength {
+					bestLength, bestDistance = l, i-int(j)
+				}
+			}
+		}
+
+		if bestLength < vp8lMinCopy {
+			tokens = append(tokens, vp8lToken{pixel: pixels[i]})
+			insert(i)
+			i++
+			continue
+		}
+
+		code, ok := near[bestDistance]
+		if !ok {
+			code = bestDistance + len(vp8lDistanceMap)
+		}
+		tokens = append(tokens, vp8lToken{length: bestLength, dist: code})
+		for k := 0; k < bestLength; k++ {
+			insert(i + k)
+		}
+		i += bestLength
+	}
+	return tokens
+}
+
+// writeImageData writes an entropy-coded image: color cache flag, (main image only)
+// meta prefix flag, the five prefix codes and the pixels.
+func (bw *bitWriter) writeImageData(pixels []uint32, width int, main bool) {
+	tokens := vp8lTokens(pixels, width)
+
+	var hist [5][]int
+	hist[0] = make([]int, vp8lGreenAlphabet)
+	for i := 1; i < 4; i++ {
+		hist[i] = make([]int, 256)
+	}
+	hist[4] = make([]int, vp8lDistAlphabet)
+	for _, t := range tokens {
+		if t.length > 0 {
+			lengthSymbol, _, _ := vp8lPrefix(t.length)
+			distSymbol, _, _ := vp8lPrefix(t.dist)
+			hist[0][256+lengthSymbol]++
+			hist[4][distSymbol]++
+			continue
+		}
+		p := t.pixel
+		hist[0][(p>>8)&0xff]++
+		hist[1][(p>>16)&0xff]++
+		hist[2][p&0xff]++
+		hist[3][p>>24]++
+	}
+
+	bw.write(0, 1) // no color cache
+	if main {
+		bw.write(0, 1) // single prefix code group
+	}
+
+	var codes [5]*prefixCode
+	for i := range hist {
+		codes[i] = bw.writePrefixCode(hist[i])
+	}
+
+	for _, t := range tokens {
+		if t.length > 0 {
+			symbol, n, extra := vp8lPrefix(t.length)
+			codes[0].put(bw, 256+symbol)
+			bw.write(extra, n)
+			symbol, n, extra = vp8lPrefix(t.dist)
+			codes[4].put(bw, symbol)
+			bw.write(extra, n)
+			continue
+		}
+		p := t.pixel
+		codes[0].put(bw, int((p>>8)&0xff))
+		codes[1].put(bw, int((p>>16)&0xff))
+		codes[2].put(bw, int(p&0xff))
+		codes[3].put(bw, int(p>>24))
+	}
+}
+
+// writePrefixCode stores the code for a histogram and returns it
+func (bw *bitWriter) writePrefixCode(hist []int) *prefixCode {
+	var used []int
+	for symbol, count := range hist {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	// "simple" codes hold one or two symbols below 256; one symbol costs no bits per pixel
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		pc := &prefixCode{lengths: make([]uint8, len(hist)), codes: make([]uint32, len(hist))}
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		bw.write(1, 1)
+		bw.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(used[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			bw.write(uint32(used[1]), 8)
+			pc.lengths[used[0]], pc.lengths[used[1]] = 1, 1
+			pc.codes[used[1]] = 1
+		}
+		return pc
+	}
+
+	lengths := huffmanLengths(hist, vp8lMaxCodeLength)
+	bw.write(0, 1)
+	bw.writeCodeLengths(lengths)
+	return newPrefixCode(lengths)
+}
+
+// writeCodeLengths stores code lengths using the code length code (symbols 0-15 literal,
+// 16 repeat previous, 17/18 runs of zeros)
+func (bw *bitWriter) writeCodeLengths(lengths []uint8) {
+	type token struct {
+		symbol, extra int
+	}
+	var tokens []token
+	prev := uint8(8)
+	for i := 0; i < len(lengths); {
+		n := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == n {
+			run++
+		}
+
+		if n == 0 {
+			left := run
+			for left >= 3 {
+				if left >= 11 {
+					k := min(left, 138)
+					tokens = append(tokens, token{18, k - 11})
+					left -= k
+				} else {
+					k := min(left, 10)
+					tokens = append(tokens, token{17, k - 3})
+					left -= k
+				}
+			}
+			for ; left > 0; left-- {
+				tokens = append(tokens, token{0, 0})
+			}
+		} else {
+			left := run
+			if n != prev {
+				tokens = append(tokens, token{int(n), 0})
+				prev = n
+				left--
+			}
+			for left >= 3 {
+				k := min(left, 6)
+				tokens = append(tokens, token{16, k - 3})
+				left -= k
+			}
+			for ; left > 0; left-- {
+				tokens = append(tokens, token{int(n), 0})
+			}
+		}
+		i += run
+	}
+
+	hist := make([]int, 19)
+	for _, t := range tokens {
+		hist[t.symbol]++
+	}
+	// the decoder needs a real tree here, so never let it collapse to one symbol
+	distinct := 0
+	for _, count := range hist {
+		if count > 0 {
+			distinct++
+		}
+	}
+	if distinct < 2 {
+		if hist[0] == 0 {
+			hist[0] = 1
+		} else {
+			hist[1] = 1
+		}
+	}
+
+	lengthLengths := huffmanLengths(hist, vp8lMaxLengthLength)
+	count := 19
+	for count > 4 && lengthLengths[vp8lCodeLengthOrder[count-1]] == 0 {
+		count--
+	}
+	bw.write(uint32(count-4), 4)
+	for i := 0; i < count; i++ {
+		bw.write(uint32(lengthLengths[vp8lCodeLengthOrder[i]]), 3)
+	}
+
+	bw.write(0, 1) // max_symbol: all symbols are coded
+	code := newPrefixCode(lengthLengths)
+	for _, t := range tokens {
+		code.put(bw, t.symbol)
+		switch t.symbol {
+		case 16:
+			bw.write(uint32(t.extra), 2)
+		case 17:
+			bw.write(uint32(t.extra), 3)
+		case 18:
+			bw.write(uint32(t.extra), 7)
+		}
+	}
+}
+
+// newPrefixCode assigns canonical codes (shorter first, then by symbol), bit-reversed for LSB-first output
+func newPrefixCode(lengths []uint8) *prefixCode {
+	pc := &prefixCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+
+	var count [vp8lMaxCodeLength + 1]uint32
+	for _, n := range lengths {
+		count[n]++
+	}
+	count[0] = 0
+
+	var next [vp8lMaxCodeLength + 2]uint32
+	code := uint32(0)
+	for n := 1; n <= vp8lMaxCodeLength; n++ {
+		code = (code + count[n-1]) << 1
+		next[n] = code
+	}
+
+	for symbol, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		c := next[n]
+		next[n]++
+		var reversed uint32
+		for i := uint8(0); i < n; i++ {
+			reversed = reversed<<1 | (c>>i)&1
+		}
+		pc.codes[symbol] = reversed
+	}
+	return pc
+}
+
+// huffmanLengths builds code lengths for hist (at least two used symbols),
+// flattening the histogram until no code is longer than maxLength.
+func huffmanLengths(hist []int, maxLength int) []uint8 {
+	counts := append([]int(nil), hist...)
+	for {
+		lengths, longest := huffmanTree(counts)
+		if longest <= maxLength {
+			return lengths
+		}
+		for i, c := range counts {
+			if c > 0 {
+				counts[i] = c/2 + 1
+			}
+		}
+	}
+}
+
+func huffmanTree(counts []int) ([]uint8, int) {
+	type node struct {
+		weight      int
+		symbol      int // leaves only
+		left, right int // children indexes, -1 for leaves
+	}
+
+	var nodes []node
+	var queue []int
+	for symbol, c := range counts {
+		if c > 0 {
+			nodes = append(nodes, node{weight: c, symbol: symbol, left: -1, right: -1})
+			queue = append(queue, len(nodes)-1)
+		}
+	}
+
+	lengths := make([]uint8, len(counts))
+	if len(queue) == 1 {
+		lengths[nodes[0].symbol] = 1
+		return lengths, 1
+	}
+
+	for len(queue) > 1 {
+		sort.SliceStable(queue, func(i, j int) bool { return nodes[queue[i]].weight < nodes[queue[j]].weight })
+		a, b := queue[0], queue[1]
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, left: a, right: b})
+		queue = append(queue[2:], len(nodes)-1)
+	}
+
+	longest := 0
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		if nodes[i].left < 0 {
+			lengths[nodes[i].symbol] = uint8(min(depth, 255))
+			longest = max(longest, depth)
+			return
+		}
+		walk(nodes[i].left, depth+1)
+		walk(nodes[i].right, depth+1)
+	}
+	walk(queue[0], 0)
+	return lengths, longest
+}
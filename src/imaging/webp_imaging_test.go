@@ -0,0 +1,135 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand/v2"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// fill builds a width x height NRGBA image from a pixel function
+func fill(width, height int, pixel func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, pixel(x, y))
+		}
+	}
+	return img
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	noise := rand.New(rand.NewPCG(1, 2))
+	random := func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(noise.IntN(256)), uint8(noise.IntN(256)), uint8(noise.IntN(256)), 0xff}
+	}
+	randomAlpha := func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(noise.IntN(256)), uint8(noise.IntN(256)), uint8(noise.IntN(256)), uint8(noise.IntN(256))}
+	}
+	gradient := func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(x * 7), uint8(y * 5), uint8(x + y), 0xff}
+	}
+	alphaGradient := func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(x * 3), 0x80, uint8(y * 9), uint8(x * 255 / 40)}
+	}
+	// repeated tiles exercise backward copies, including across rows
+	tiles := func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8((x % 4) * 60), uint8((y % 3) * 80), 0x40, 0xff}
+	}
+	// a flat card with a few shapes, like the share cards
+	card := func(x, y int) color.NRGBA {
+		switch {
+		case (x-60)*(x-60)+(y-40)*(y-40) < 400:
+			return color.NRGBA{0xe0, 0x20, 0x40, 0xff}
+		case y > 60 && x%10 < 6:
+			return color.NRGBA{0xff, 0xff, 0xff, 0xff}
+		}
+		return color.NRGBA{0x10, 0x10, 0x30, 0xff}
+	}
+
+	tests := []struct {
+		name          string
+		width, height int
+		pixel         func(x, y int) color.NRGBA
+	}{
+		{"1x1", 1, 1, gradient},
+		{"1x1 transparent", 1, 1, func(x, y int) color.NRGBA { return color.NRGBA{0x12, 0x34, 0x56, 0} }},
+		{"single row", 37, 1, gradient},
+		{"single column", 1, 29, gradient},
+		{"odd gradient", 41, 23, gradient},
+		{"alpha gradient", 41, 17, alphaGradient},
+		{"solid", 64, 64, func(x, y int) color.NRGBA { return color.NRGBA{0x20, 0x40, 0x60, 0xff} }},
+		{"tiles", 97, 53, tiles},
+		{"card", 121, 81, card},
+		{"noise", 33, 31, random},
+		{"noise with alpha", 19, 27, randomAlpha},
+		{"wide", 700, 3, gradient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := fill(tt.width, tt.height, tt.pixel)
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, src); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal("decode:", err)
+			}
+			if decoded.Bounds() != src.Bounds() {
+				t.Fatalf("bounds = %v, want %v", decoded.Bounds(), src.Bounds())
+			}
+			got := image.NewNRGBA(decoded.Bounds())
+			draw.Draw(got, got.Rect, decoded, image.Point{}, draw.Src)
+			for y := 0; y < tt.height; y++ {
+				for x := 0; x < tt.width; x++ {
+					want, have := src.NRGBAAt(x, y), got.NRGBAAt(x, y)
+					if want.A == 0 && have.A == 0 {
+						continue // transparent pixels may come back with any colour
+					}
+					if want != have {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, have, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestEncodeWebPSubImage checks images whose bounds do not start at the origin
+func TestEncodeWebPSubImage(t *testing.T) {
+	src := fill(30, 20, func(x, y int) color.NRGBA { return color.NRGBA{uint8(x * 8), uint8(y * 12), 0x99, 0xff} })
+	sub := src.SubImage(image.Rect(5, 3, 22, 18)).(*image.NRGBA)
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, sub); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := webp.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != image.Rect(0, 0, 17, 15) {
+		t.Fatalf("bounds = %v", decoded.Bounds())
+	}
+	for y := 0; y < 15; y++ {
+		for x := 0; x < 17; x++ {
+			r, g, b, a := decoded.At(x, y).RGBA()
+			want := src.NRGBAAt(x+5, y+3)
+			if uint8(r>>8) != want.R || uint8(g>>8) != want.G || uint8(b>>8) != want.B || uint8(a>>8) != want.A {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, decoded.At(x, y), want)
+			}
+		}
+	}
+}
+
+func TestEncodeWebPSizeLimits(t *testing.T) {
+	for _, rect := range []image.Rectangle{image.Rect(0, 0, 0, 5), image.Rect(0, 0, vp8lMaxSize+1, 1)} {
+		if err := EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(rect)); err == nil {
+			t.Errorf("EncodeWebP(%v) did not fail", rect)
+		}
+	}
+}
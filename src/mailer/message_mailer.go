@@ -0,0 +1,208 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Outbox statuses
+const (
+	StatusQueued  = "queued"
+	StatusSending = "sending" // claimed by a worker until Lease_until
+	StatusSent    = "sent"
+	StatusDead    = "dead" // gave up; staff can requeue it
+)
+
+var ErrInvalidMessage = errors.New("invalid mail message")
+
+var headerName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]{0,63}$`)
+
+// reservedHeaders are written by buildMIME and cannot be set through Message.Headers
+var reservedHeaders = map[string]bool{
+	"From": true, "To": true, "Cc": true, "Bcc": true, "Reply-To": true, "Subject": true, "Date": true,
+	"Message-Id": true, "Mime-Version": true, "Content-Type": true, "Content-Transfer-Encoding": true,
+}
+
+// Message is one email in the outbox collection
+type Message struct {
+	ID               primitive.ObjectID `bson:"_id" json:"id"`
+	To               []string           `bson:"to" json:"to"`
+	From_name        string             `bson:"from_name,omitempty" json:"from_name,omitempty"`
+	Reply_to         string             `bson:"reply_to,omitempty" json:"reply_to,omitempty"`
+	Subject          string             `bson:"subject" json:"subject"`
+	HTML             string             `bson:"html" json:"html"`
+	Text             string             `bson:"text,omitempty" json:"text,omitempty"`
+	Tag              string             `bson:"tag,omitempty" json:"tag,omitempty"`         // what sent it, e.g. "shoutbox.auto-reply"
+	Headers          map[string]string  `bson:"headers,omitempty" json:"headers,omitempty"` // extra headers, e.g. List-Unsubscribe
+	Template         string             `bson:"template,omitempty" json:"template,omitempty"`
+	Template_version int                `bson:"template_version,omitempty" json:"template_version,omitempty"`
+	Status           string             `bson:"status" json:"status"`
+	Attempts         int                `bson:"attempts" json:"attempts"`
+	Max_attempts     int                `bson:"max_attempts" json:"max_attempts"`
+	Next_attempt_at  primitive.DateTime `bson:"next_attempt_at" json:"next_attempt_at"`
+	Lease_until      primitive.DateTime `bson:"lease_until,omitempty" json:"-"`
+	Last_error       string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	Transport        string             `bson:"transport,omitempty" json:"transport,omitempty"`
+	Sent_at          primitive.DateTime `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	Created_at       primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at       primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// validate rejects messages that would break the headers or have nowhere to go
+func (m *Message) validate() error {
+	if len(m.To) == 0 || strings.TrimSpace(m.Subject) == "" || m.HTML == "" {
+		return fmt.Errorf("%w: to, subject and html are required", ErrInvalidMessage)
+	}
+	for _, value := range []string{m.From_name, m.Subject, m.Reply_to} {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: header contains a line break", ErrInvalidMessage)
+		}
+	}
+	for key, value := range m.Headers {
+		if !headerName.MatchString(key) || reservedHeaders[textproto.CanonicalMIMEHeaderKey(key)] || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: bad header %q", ErrInvalidMessage, key)
+		}
+	}
+	addresses := append([]string{}, m.To...)
+	if m.Reply_to != "" {
+		addresses = append(addresses, m.Reply_to)
+	}
+	for _, address := range addresses {
+		if _, err := mail.ParseAddress(address); err != nil || strings.ContainsAny(address, "\r\n") {
+			return fmt.Errorf("%w: bad address %q", ErrInvalidMessage, address)
+		}
+	}
+	return nil
+}
+
+// buildMIME renders the message as an RFC 5322 email with HTML and plain text
+// parts. It validates again, since outbox documents can be edited by hand
+func buildMIME(m *Message, from string) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(from, "\r\n") {
+		return nil, fmt.Errorf("%w: bad sender %q", ErrInvalidMessage, from)
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) { buf.WriteString(key + ": " + value + "\r\n") }
+	header("From", (&mail.Address{Name: m.From_name, Address: from}).String())
+	header("To", strings.Join(m.To, ", "))
+	if m.Reply_to != "" {
+		header("Reply-To", m.Reply_to)
+	}
+	header("Subject", encodeSubject(m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+m.ID.Hex()+"."+hex.EncodeToString(token)+"@"+domain+">")
+	keys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		header(textproto.CanonicalMIMEHeaderKey(key), m.Headers[key])
+	}
+	header("MIME-Version", "1.0")
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+
+	text := m.Text
+	if text == "" {
+		text = plainText(m.HTML)
+	}
+	for _, part := range []struct{ kind, body string }{{"text/plain", text}, {"text/html", m.HTML}} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.kind + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeSubject Q-encodes a subject that is not plain ASCII. An ASCII subject
+// holding "=?" is base64-encoded as well, or clients would decode that part as
+// an encoded word; it is split into words of at most 45 bytes (75 characters)
+func encodeSubject(subject string) string {
+	if !strings.Contains(subject, "=?") {
+		return mime.QEncoding.Encode("utf-8", subject)
+	}
+	var words []string
+	for subject != "" {
+		n := min(len(subject), 45)
+		for n < len(subject) && !utf8.RuneStart(subject[n]) {
+			n--
+		}
+		words = append(words, "=?utf-8?b?"+base64.StdEncoding.EncodeToString([]byte(subject[:n]))+"?=")
+		subject = subject[n:]
+	}
+	return strings.Join(words, " ")
+}
+
+var blockTags = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</h3>", "\n\n", "</li>", "\n", "</tr>", "\n")
+
+// plainText is a rough text version of an HTML body for clients that prefer it
+func plainText(body string) string {
+	body = blockTags.Replace(body)
+	var out strings.Builder
+	inTag := false
+	for _, r := range body {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			out.WriteRune(r)
+		}
+	}
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text := strings.Join(lines, "\n")
+	for strings.Contains(text, "\n\n\n") {
+		text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(html.UnescapeString(text))
+}
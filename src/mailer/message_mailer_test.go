@@ -0,0 +1,241 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testMessage() *Message {
+	return &Message{
+		ID:      primitive.NewObjectID(),
+		To:      []string{"listener@example.com"},
+		Subject: "Shoutbox Inquiry - Foundation Day",
+		HTML:    "<p>Hello</p>",
+	}
+}
+
+func TestBuildMIMERejectsLineBreaks(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(m *Message)
+		from string
+	}{
+		{name: "subject", edit: func(m *Message) { m.Subject = "Hi\r\nBcc: everyone@example.com" }},
+		{name: "bare lf in subject", edit: func(m *Message) { m.Subject = "Hi\nBcc: everyone@example.com" }},
+		{name: "from name", edit: func(m *Message) { m.From_name = "Magic\r\nX-Spam: no" }},
+		{name: "reply-to", edit: func(m *Message) { m.Reply_to = "dj@example.com\r\nBcc: everyone@example.com" }},
+		{name: "recipient", edit: func(m *Message) { m.To = []string{"a@example.com\r\nBcc: b@example.com"} }},
+		{name: "header value", edit: func(m *Message) {
+			m.Headers = map[string]string{"List-Unsubscribe": "<https://x>\r\nBcc: b@example.com"}
+		}},
+		{name: "header name", edit: func(m *Message) { m.Headers = map[string]string{"X-A\r\nBcc": "b@example.com"} }},
+		{name: "reserved header", edit: func(m *Message) { m.Headers = map[string]string{"bcc": "b@example.com"} }},
+		{name: "sender", from: "no-reply@example.com\r\nBcc: b@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testMessage()
+			if tt.edit != nil {
+				tt.edit(m)
+			}
+			from := tt.from
+			if from == "" {
+				from = "no-reply@example.com"
+			}
+			raw, err := buildMIME(m, from)
+			if !errors.Is(err, ErrInvalidMessage) {
+				t.Fatalf("err = %v, want ErrInvalidMessage", err)
+			}
+			if raw != nil {
+				t.Errorf("built %q", raw)
+			}
+		})
+	}
+}
+
+// parseMIME reads a built message back, returning its header and its parts by content type
+func parseMIME(t *testing.T, raw []byte) (mail.Header, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kind, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || kind != "multipart/alternative" || params["boundary"] == "" {
+		t.Fatalf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	parts := map[string]string{}
+	var order []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(part) // quoted-printable is decoded by the reader
+		if err != nil {
+			t.Fatal(err)
+		}
+		partKind, partParams, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if partParams["charset"] != "UTF-8" {
+			t.Errorf("%s charset = %q", partKind, partParams["charset"])
+		}
+		parts[partKind] = string(body)
+		order = append(order, partKind)
+	}
+	// clients show the last alternative they understand, so HTML goes last
+	if strings.Join(order, ",") != "text/plain,text/html" {
+		t.Errorf("parts = %v", order)
+	}
+	return msg.Header, parts
+}
+
+func TestBuildMIME(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		html    string
+		text    string
+		want    string // the plain text part
+	}{
+		{
+			name:    "ascii",
+			subject: "New Shoutbox Inquiry Received",
+			html:    "<p>Hello &amp; welcome</p><p>Second</p>",
+			want:    "Hello & welcome\r\n\r\nSecond", // text parts use CRLF line ends
+		},
+		{
+			name:    "non-ascii subject",
+			subject: "Salamat, Ñiño! Fresh Groove 🎵 — Top 10",
+			html:    "<p>Maligayang pagdating sa Magic 89.9 ✨</p>",
+			text:    "Maligayang pagdating ✨",
+			want:    "Maligayang pagdating ✨",
+		},
+		{
+			name:    "long subject",
+			subject: strings.Repeat("Ñ Fresh Groove Talent Search ", 8),
+			html:    "<p>x</p>",
+			want:    "x",
+		},
+		{
+			name:    "encoded-word lookalike",
+			subject: "=?utf-8?q?not_really?= plain",
+			html:    "<p>x</p>",
+			want:    "x",
+		},
+		{
+			name:    "long encoded-word lookalike",
+			subject: strings.Repeat("=?é?= ", 30),
+			html:    "<p>x</p>",
+			want:    "x",
+		},
+		{
+			// a body line that looks like a boundary must not end the part
+			name:    "boundary lookalikes",
+			subject: "Boundaries",
+			html:    "<pre>\r\n--\r\n--boundary\r\n--boundary--\r\n</pre>",
+			text:    "--\r\n--boundary--\r\n" + strings.Repeat("=", 100) + "\r\n.",
+			want:    "--\r\n--boundary--\r\n" + strings.Repeat("=", 100) + "\r\n.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testMessage()
+			m.Subject, m.HTML, m.Text = tt.subject, tt.html, tt.text
+			m.From_name = "Magic 89.9 Ñ"
+			m.Reply_to = "dj@example.com"
+			m.Headers = map[string]string{"list-unsubscribe": "<https://example.com/u>", "X-Tag": "test"}
+			raw, err := buildMIME(m, "no-reply@magic899.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			head, _, _ := bytes.Cut(raw, []byte("\r\n\r\n"))
+			for i, b := range head {
+				if b >= 0x80 {
+					t.Fatalf("raw 8-bit byte in the header at %d: %q", i, head)
+				}
+			}
+			for _, line := range strings.Split(string(raw), "\r\n") {
+				if strings.ContainsAny(line, "\r\n") {
+					t.Fatalf("bare line break in %q", line)
+				}
+			}
+
+			header, parts := parseMIME(t, raw)
+			for _, word := range strings.Fields(header.Get("Subject")) {
+				if strings.HasPrefix(word, "=?") && len(word) > 75 {
+					t.Errorf("encoded word of %d characters: %s", len(word), word)
+				}
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", subject, tt.subject)
+			}
+			from, err := header.AddressList("From")
+			if err != nil || len(from) != 1 || from[0].Name != m.From_name || from[0].Address != "no-reply@magic899.com" {
+				t.Errorf("From = %q (%v)", header.Get("From"), err)
+			}
+			if header.Get("To") != "listener@example.com" || header.Get("Reply-To") != "dj@example.com" {
+				t.Errorf("To = %q, Reply-To = %q", header.Get("To"), header.Get("Reply-To"))
+			}
+			if header.Get("List-Unsubscribe") != "<https://example.com/u>" || header.Get("X-Tag") != "test" {
+				t.Errorf("extra headers = %v", header)
+			}
+			if id := header.Get("Message-Id"); !strings.HasPrefix(id, "<"+m.ID.Hex()+".") || !strings.HasSuffix(id, "@magic899.com>") {
+				t.Errorf("Message-ID = %q", id)
+			}
+			if _, err := header.Date(); err != nil {
+				t.Errorf("Date: %v", err)
+			}
+
+			if parts["text/html"] != tt.html {
+				t.Errorf("html = %q, want %q", parts["text/html"], tt.html)
+			}
+			if parts["text/plain"] != tt.want {
+				t.Errorf("text = %q, want %q", parts["text/plain"], tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildMIMEBoundary checks that every message gets its own boundary
+func TestBuildMIMEBoundary(t *testing.T) {
+	boundaries := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		raw, err := buildMIME(testMessage(), "no-reply@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		boundary := params["boundary"]
+		if boundaries[boundary] {
+			t.Fatalf("boundary %q repeated", boundary)
+		}
+		boundaries[boundary] = true
+		if n := strings.Count(string(raw), "--"+boundary); n != 3 {
+			t.Errorf("boundary appears %d times, want 3 (two parts and the close)", n)
+		}
+		if !strings.HasSuffix(string(raw), "--"+boundary+"--\r\n") {
+			t.Errorf("message does not end with the closing boundary")
+		}
+	}
+}
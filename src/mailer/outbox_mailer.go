@@ -0,0 +1,243 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/utils"
+	"math/rand/v2"
+	"net/mail"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Outbox
+   -----------------------------------
+   Enqueue stores a message in mail_outbox and wakes the worker; callers
+   never wait on SMTP. The worker claims due messages with a lease, so a
+   crash mid-send only delays a message until the lease runs out.
+   Failures back off exponentially (1m, 2m, 4m ... capped at 6h) and a
+   message that fails DefaultMaxAttempts times, or is rejected outright,
+   is marked dead for staff to inspect and requeue.
+   -----------------------------------
+*/
+
+const (
+	DefaultMaxAttempts = 8
+	baseBackoff        = time.Minute
+	maxBackoff         = 6 * time.Hour
+	sendLease          = 2 * time.Minute
+	pollInterval       = 30 * time.Second
+)
+
+var (
+	cfg        config
+	configOnce sync.Once
+	wakeCh     = make(chan struct{}, 1)
+	indexOnce  sync.Once
+)
+
+func settings() config {
+	configOnce.Do(func() { cfg = loadConfig() })
+	return cfg
+}
+
+// Collection is the outbox
+func Collection() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "mail_outbox")
+	indexOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		})
+		if err != nil {
+			log.Println("[MAILER] index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+// From is the address mail is sent from
+func From() string { return settings().from }
+
+// StaffInbox is where staff notifications are delivered
+func StaffInbox() string { return settings().inbox }
+
+// Init picks the transport from the environment and starts the send worker
+func Init() {
+	if _, logOnly := settings().transport.(logTransport); logOnly && utils.IsProduction() {
+		log.Fatal("[MAILER] no mail transport configured in production; set SMTP_HOST and SMTP_USER (or MAIL_TRANSPORT)")
+	}
+	log.Printf("[MAILER] sending as %s via %s", settings().from, settings().transport.Name())
+	go work()
+}
+
+// Enqueue validates a message and stores it for the worker to send
+func Enqueue(ctx context.Context, msg Message) (primitive.ObjectID, error) {
+	if err := msg.validate(); err != nil {
+		return primitive.NilObjectID, err
+	}
+	now := primitive.NewDateTimeFromTime(time.Now())
+	msg.ID = primitive.NewObjectID()
+	msg.Status = StatusQueued
+	msg.Attempts = 0
+	if msg.Max_attempts <= 0 {
+		msg.Max_attempts = DefaultMaxAttempts
+	}
+	msg.Next_attempt_at, msg.Created_at, msg.Updated_at = now, now, now
+
+	if _, err := Collection().InsertOne(ctx, msg); err != nil {
+		return primitive.NilObjectID, err
+	}
+	wake()
+	return msg.ID, nil
+}
+
+// Requeue gives a dead (or stuck) message a fresh set of attempts
+func Requeue(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	result, err := Collection().UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$ne": StatusSent}},
+		bson.M{
+			"$set":   bson.M{"status": StatusQueued, "attempts": 0, "next_attempt_at": now, "updated_at": now},
+			"$unset": bson.M{"lease_until": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		wake()
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// backoff is the wait after the given number of failed attempts, with ±20% jitter
+func backoff(attempts int) time.Duration {
+	wait := maxBackoff
+	if attempts < 20 {
+		wait = min(baseBackoff<<(attempts-1), maxBackoff)
+	}
+	jitter := 0.8 + 0.4*rand.Float64()
+	return time.Duration(float64(wait) * jitter)
+}
+
+func work() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		for sendNext() {
+		}
+		select {
+		case <-wakeCh:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim takes the next due message (or one whose lease ran out) for this worker
+func claim(ctx context.Context, collection *mongo.Collection, now time.Time) (*Message, error) {
+	nowDT := primitive.NewDateTimeFromTime(now)
+	var msg Message
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"status": StatusQueued, "next_attempt_at": bson.M{"$lte": nowDT}},
+			bson.M{"status": StatusSending, "lease_until": bson.M{"$lte": nowDT}},
+		}},
+		bson.M{
+			"$set": bson.M{"status": StatusSending, "lease_until": primitive.NewDateTimeFromTime(now.Add(sendLease)), "updated_at": nowDT},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&msg)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &msg, err
+}
+
+// sendNext sends one due message; false when there was nothing to do
+func sendNext() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), sendLease)
+	defer cancel()
+
+	msg, err := claim(ctx, Collection(), time.Now())
+	if err != nil {
+		log.Println("[MAILER] claim failed:", err)
+		return false
+	}
+	if msg == nil {
+		return false
+	}
+
+	conf := settings()
+	err = deliver(ctx, conf, msg)
+	update := settle(msg, err, time.Now())
+	update["transport"] = conf.transport.Name()
+	switch update["status"] {
+	case StatusDead:
+		log.Printf("[MAILER] %s to %v is dead after %d attempts: %v", msg.ID.Hex(), msg.To, msg.Attempts, err)
+	case StatusQueued:
+		log.Printf("[MAILER] %s attempt %d failed, retrying: %v", msg.ID.Hex(), msg.Attempts, err)
+	}
+
+	_, err = Collection().UpdateOne(context.Background(),
+		bson.M{"_id": msg.ID, "status": StatusSending},
+		bson.M{"$set": update, "$unset": bson.M{"lease_until": ""}},
+	)
+	if err != nil {
+		log.Println("[MAILER] status update failed:", err)
+	}
+	return true
+}
+
+// settle is what an attempt leaves behind: sent, queued again after a backoff,
+// or dead once the failure is permanent or the attempts ran out
+func settle(msg *Message, err error, now time.Time) bson.M {
+	nowDT := primitive.NewDateTimeFromTime(now)
+	update := bson.M{"updated_at": nowDT}
+	switch {
+	case err == nil:
+		update["status"], update["sent_at"] = StatusSent, nowDT
+	case isPermanent(err) || msg.Attempts >= msg.Max_attempts:
+		update["status"], update["last_error"] = StatusDead, err.Error()
+	default:
+		update["status"], update["last_error"] = StatusQueued, err.Error()
+		update["next_attempt_at"] = primitive.NewDateTimeFromTime(now.Add(backoff(msg.Attempts)))
+	}
+	return update
+}
+
+func deliver(ctx context.Context, conf config, msg *Message) error {
+	raw, err := buildMIME(msg, conf.from)
+	if err != nil {
+		return permanentError{err}
+	}
+	to := make([]string, 0, len(msg.To))
+	for _, address := range msg.To {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return permanentError{errors.New("bad address " + address)}
+		}
+		to = append(to, parsed.Address)
+	}
+	return conf.transport.Send(ctx, conf.from, to, raw)
+}
@@ -0,0 +1,184 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{7, 64 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, maxBackoff}, // 512m is past the cap
+		{19, maxBackoff},
+		{20, maxBackoff},
+		{64, maxBackoff}, // would overflow the shift
+	}
+	for _, tt := range tests {
+		low, high := tt.want*8/10, tt.want*12/10
+		for i := 0; i < 50; i++ {
+			if got := backoff(tt.attempts); got < low || got > high {
+				t.Fatalf("backoff(%d) = %v, want %v ±20%%", tt.attempts, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSettle(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	transient := errors.New("421 try again later")
+	permanent := permanentError{errors.New("550 no such mailbox")}
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		status   string
+		retry    time.Duration // expected backoff before jitter; 0 when not queued
+	}{
+		{name: "sent", attempts: 1, status: StatusSent},
+		{name: "sent on the last attempt", attempts: DefaultMaxAttempts, status: StatusSent},
+		{name: "first failure", attempts: 1, err: transient, status: StatusQueued, retry: time.Minute},
+		{name: "later failure", attempts: 4, err: transient, status: StatusQueued, retry: 8 * time.Minute},
+		{name: "last attempt fails", attempts: DefaultMaxAttempts, err: transient, status: StatusDead},
+		{name: "past the limit", attempts: DefaultMaxAttempts + 1, err: transient, status: StatusDead},
+		{name: "permanent on the first attempt", attempts: 1, err: permanent, status: StatusDead},
+		{name: "wrapped permanent", attempts: 2, err: fmt.Errorf("smtp: %w", permanent), status: StatusDead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{Attempts: tt.attempts, Max_attempts: DefaultMaxAttempts}
+			update := settle(msg, tt.err, now)
+			if update["status"] != tt.status {
+				t.Fatalf("status = %v, want %s", update["status"], tt.status)
+			}
+			if update["updated_at"] != primitive.NewDateTimeFromTime(now) {
+				t.Errorf("updated_at = %v", update["updated_at"])
+			}
+			_, hasSent := update["sent_at"]
+			if hasSent != (tt.status == StatusSent) {
+				t.Errorf("sent_at = %v", update["sent_at"])
+			}
+			if tt.err != nil && update["last_error"] != tt.err.Error() {
+				t.Errorf("last_error = %v, want %q", update["last_error"], tt.err)
+			}
+			next, queued := update["next_attempt_at"].(primitive.DateTime)
+			if queued != (tt.status == StatusQueued) {
+				t.Fatalf("next_attempt_at = %v", update["next_attempt_at"])
+			}
+			if queued {
+				wait := next.Time().Sub(now)
+				if wait < tt.retry*8/10 || wait > tt.retry*12/10 {
+					t.Errorf("next attempt in %v, want %v ±20%%", wait, tt.retry)
+				}
+			}
+		})
+	}
+}
+
+// TestSettleSchedule follows a message that never gets through
+func TestSettleSchedule(t *testing.T) {
+	now := time.Now()
+	msg := &Message{Max_attempts: 4}
+	var statuses []string
+	for msg.Attempts = 1; msg.Attempts <= msg.Max_attempts; msg.Attempts++ {
+		statuses = append(statuses, settle(msg, errors.New("timeout"), now)["status"].(string))
+	}
+	want := []string{StatusQueued, StatusQueued, StatusQueued, StatusDead}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+}
+
+// TestClaim runs against a real MongoDB, in a throwaway database:
+//
+//	MONGO_TEST_URI=mongodb://localhost:27017 go test ./src/mailer
+func TestClaim(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	database := client.Database(fmt.Sprintf("mailer_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		database.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+	collection := database.Collection("mail_outbox")
+
+	now := time.Now().Truncate(time.Millisecond)
+	at := func(d time.Duration) primitive.DateTime { return primitive.NewDateTimeFromTime(now.Add(d)) }
+	message := func(tag, status string, attempts int, next, lease primitive.DateTime) Message {
+		return Message{
+			ID: primitive.NewObjectID(), Tag: tag, To: []string{"listener@example.com"}, Subject: tag, HTML: "<p>hi</p>",
+			Status: status, Attempts: attempts, Max_attempts: DefaultMaxAttempts, Next_attempt_at: next, Lease_until: lease,
+		}
+	}
+	docs := []interface{}{
+		message("due later", StatusQueued, 0, at(-time.Minute), 0),
+		message("due first", StatusQueued, 2, at(-time.Hour), 0),
+		message("not due", StatusQueued, 1, at(time.Hour), 0),
+		message("lease expired", StatusSending, 3, at(-2*time.Hour), at(-time.Second)),
+		message("lease held", StatusSending, 1, at(-3*time.Hour), at(time.Minute)),
+		message("sent", StatusSent, 1, at(-4*time.Hour), 0),
+		message("dead", StatusDead, DefaultMaxAttempts, at(-5*time.Hour), 0),
+	}
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// oldest next_attempt_at first; held leases, future and finished messages are left alone
+	want := []struct {
+		tag      string
+		attempts int
+	}{{"lease expired", 4}, {"due first", 3}, {"due later", 1}}
+	for _, w := range want {
+		msg, err := claim(ctx, collection, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == nil {
+			t.Fatalf("claim = nil, want %q", w.tag)
+		}
+		if msg.Tag != w.tag || msg.Attempts != w.attempts || msg.Status != StatusSending {
+			t.Fatalf("claim = %q (attempts %d, %s), want %q (attempts %d, sending)", msg.Tag, msg.Attempts, msg.Status, w.tag, w.attempts)
+		}
+		if lease := msg.Lease_until.Time(); !lease.Equal(now.Add(sendLease)) {
+			t.Errorf("%s: lease until %v, want %v", w.tag, lease, now.Add(sendLease))
+		}
+	}
+	if msg, err := claim(ctx, collection, now); err != nil || msg != nil {
+		t.Fatalf("claim after the due messages = %v, %v; want nothing", msg, err)
+	}
+
+	// a worker that died mid-send gives its message up once the lease runs out
+	msg, err := claim(ctx, collection, now.Add(sendLease+time.Second))
+	if err != nil || msg == nil {
+		t.Fatalf("claim after the leases ran out = %v, %v", msg, err)
+	}
+	if msg.Tag != "lease held" || msg.Attempts != 2 {
+		t.Errorf("claimed %q (attempts %d), want \"lease held\" (attempts 2)", msg.Tag, msg.Attempts)
+	}
+	if n, _ := collection.CountDocuments(ctx, bson.M{"status": StatusSending}); n != 4 {
+		t.Errorf("%d messages sending, want 4", n)
+	}
+}
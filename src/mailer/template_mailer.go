@@ -0,0 +1,378 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"magic-server-2026/src/db"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+   Templates
+   -----------------------------------
+   Every email the server sends is a named template. Version 0 of each
+   one ships with the code (templates/<name>.html and an optional
+   <name>.txt); staff can save new versions to mail_templates and
+   activate one, which then replaces the built-in until deactivated.
+   HTML is rendered with html/template, so values are escaped for the
+   context they land in; the subject and plain text part use
+   text/template. A template that references a key the data does not
+   have fails to render rather than sending a blank.
+   -----------------------------------
+*/
+
+//go:embed templates
+var builtinFiles embed.FS
+
+// templateSaveAttempts bounds the renumbering when saves collide
+const templateSaveAttempts = 5
+
+var (
+	ErrUnknownTemplate = errors.New("unknown mail template")
+	ErrTemplateVersion = errors.New("mail template version not found")
+	ErrBadTemplate     = errors.New("invalid mail template")
+)
+
+// TemplateDef describes a template the code sends and the data it is given
+type TemplateDef struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Subject     string                 `json:"subject"`
+	Sample      map[string]interface{} `json:"sample"` // used for previews and to test new versions
+}
+
+var builtinTemplates = []TemplateDef{
+	{
+		Name:        "shoutbox-auto-reply",
+		Description: "Sent to the requester of a shoutbox inquiry",
+		Subject:     "Shoutbox Inquiry - {{.Title}}",
+		Sample: map[string]interface{}{
+			"Name": "Juan dela Cruz", "Title": "Foundation Day Concert", "Event_date": "2026-11-20 18:00",
+			"Radio_spiel": "Join us for a night of music & fun at the school grounds, featuring student bands and special guests from Magic 89.9!",
+		},
+	},
+	{
+		Name:        "shoutbox-staff",
+		Description: "Tells the shoutbox inbox about a new inquiry",
+		Subject:     "New Shoutbox Inquiry Received",
+		Sample: map[string]interface{}{
+			"Name": "Juan dela Cruz", "Email": "juan@example.com", "School_name": "Rizal High School",
+			"Contact": "09171234567", "School_contact": "0286001234", "Title": "Foundation Day Concert",
+			"Event_date":  "2026-11-20 18:00",
+			"Radio_spiel": "Join us for a night of music & fun at the school grounds, featuring student bands and special guests from Magic 89.9!",
+		},
+	},
+	{
+		Name:        "fresh-groove-auto-reply",
+		Description: "Welcomes a Fresh Groove talent search registrant",
+		Subject:     "Fresh Groove Talent Search",
+		Sample:      map[string]interface{}{"Name": "Maria Santos", "Email": "maria@example.com"},
+	},
+	{
+		Name:        "newsletter-confirm",
+		Description: "Double opt-in link sent when someone subscribes to the newsletter",
+		Subject:     "Confirm your Magic 89.9 newsletter subscription",
+		Sample: map[string]interface{}{
+			"Name": "Maria Santos", "Confirm_url": "https://example.com/newsletter/confirm?token=sample", "Valid_days": 7,
+		},
+	},
+	{
+		Name:        "newsletter-digest",
+		Description: "The weekly digest; sections the subscriber did not pick are empty",
+		Subject:     "Magic 89.9 Weekly: {{.Week}}",
+		Sample: map[string]interface{}{
+			"Name": "Maria Santos", "Week": "Oct 12 - 18",
+			"Chart": []interface{}{
+				map[string]interface{}{"Position": 1, "Title": "Golden Hour", "Artist": "JVKE", "URL": "https://example.com/music/1"},
+				map[string]interface{}{"Position": 2, "Title": "Multo", "Artist": "Cup of Joe", "URL": "https://example.com/music/2"},
+			},
+			"News": []interface{}{
+				map[string]interface{}{"Title": "Concert season kicks off in Manila", "Category": "Music", "URL": "https://example.com/news/music/concert-season"},
+			},
+			"Screenings": []interface{}{
+				map[string]interface{}{"Title": "Sample Movie", "When": "Sat, Oct 24 7:00 PM", "Cinema": "SM Megamall Cinema 3", "URL": "https://example.com/movies/1"},
+			},
+			"Preferences_url": "https://example.com/newsletter/preferences?token=sample",
+			"Unsubscribe_url": "https://example.com/newsletter/unsubscribe/sample",
+		},
+	},
+	{
+		Name:        "contest-winner",
+		Description: "Tells a drawn winner (or promoted alternate) how to claim their prize",
+		Subject:     "You won: {{.Contest}}",
+		Sample: map[string]interface{}{
+			"Name": "Maria Santos", "Contest": "Kostcon Ticket Giveaway", "Prize": "2 VIP passes", "Entry_no": 42,
+			"Claim_url": "https://example.com/contests/sample/claim?token=sample", "Claim_deadline": "Sat, Oct 24 11:59 PM",
+		},
+	},
+	{
+		Name:        "form-staff-notification",
+		Description: "Lists a form submission for the form's staff recipients",
+		Subject:     "New {{.Form}} submission",
+		Sample: map[string]interface{}{
+			"Form": "Shoutbox Inquiry",
+			"Fields": []interface{}{
+				map[string]interface{}{"Label": "Name", "Value": "Juan dela Cruz"},
+				map[string]interface{}{"Label": "Email", "Value": "juan@example.com"},
+			},
+		},
+	},
+}
+
+// Templates lists the built-in templates
+func Templates() []TemplateDef { return builtinTemplates }
+
+// LookupTemplate returns the definition of a built-in template
+func LookupTemplate(name string) (TemplateDef, bool) {
+	for _, def := range builtinTemplates {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return TemplateDef{}, false
+}
+
+// TemplateVersion is one saved revision of a template; version 0 is the built-in
+type TemplateVersion struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name         string             `bson:"name" json:"name"`
+	Version      int                `bson:"version" json:"version"`
+	Subject      string             `bson:"subject" json:"subject"`
+	HTML         string             `bson:"html" json:"html"`
+	Text         string             `bson:"text,omitempty" json:"text,omitempty"` // derived from the HTML when empty
+	Note         string             `bson:"note,omitempty" json:"note,omitempty"`
+	Active       bool               `bson:"active" json:"active"`
+	Activated_at primitive.DateTime `bson:"activated_at,omitempty" json:"activated_at,omitempty"`
+	Created_by   string             `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	Created_at   primitive.DateTime `bson:"created_at,omitempty" json:"created_at,omitempty"`
+}
+
+// Rendered is a template filled in with data, ready to become a Message
+type Rendered struct {
+	Template string `json:"template"`
+	Version  int    `json:"version"`
+	Subject  string `json:"subject"`
+	HTML     string `json:"html"`
+	Text     string `json:"text"`
+}
+
+// Message starts an outbox message with the rendered content; callers add To and the rest
+func (r Rendered) Message() Message {
+	return Message{Subject: r.Subject, HTML: r.HTML, Text: r.Text, Template: r.Template, Template_version: r.Version}
+}
+
+// MIME is the multipart/alternative email the outbox would send, addressed to the staff inbox
+func (r Rendered) MIME() ([]byte, error) {
+	msg := r.Message()
+	msg.ID = primitive.NewObjectID()
+	msg.To = []string{StaffInbox()}
+	return buildMIME(&msg, From())
+}
+
+var templateIndexOnce sync.Once
+
+// TemplateCollection holds staff-saved template versions
+func TemplateCollection() *mongo.Collection {
+	collection := db.GetCollection("magic899_db", "mail_templates")
+	templateIndexOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Println("[MAILER] template index creation failed:", err)
+		}
+	})
+	return collection
+}
+
+// builtinVersion reads version 0 of a template from the embedded files
+func builtinVersion(def TemplateDef) TemplateVersion {
+	version := TemplateVersion{Name: def.Name, Subject: def.Subject}
+	if body, err := builtinFiles.ReadFile("templates/" + def.Name + ".html"); err == nil {
+		version.HTML = string(body)
+	}
+	if body, err := builtinFiles.ReadFile("templates/" + def.Name + ".txt"); err == nil {
+		version.Text = string(body)
+	}
+	return version
+}
+
+// TemplateVersions lists every version of a template, newest first, ending with the built-in
+func TemplateVersions(ctx context.Context, name string) ([]TemplateVersion, error) {
+	def, ok := LookupTemplate(name)
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+	cursor, err := TemplateCollection().Find(ctx, bson.M{"name": name}, options.Find().SetSort(bson.D{{Key: "version", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []TemplateVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	builtin := builtinVersion(def)
+	builtin.Active = true
+	for _, version := range versions {
+		if version.Active {
+			builtin.Active = false
+		}
+	}
+	return append(versions, builtin), nil
+}
+
+// GetTemplateVersion fetches one version; 0 is the built-in
+func GetTemplateVersion(ctx context.Context, name string, version int) (TemplateVersion, error) {
+	def, ok := LookupTemplate(name)
+	if !ok {
+		return TemplateVersion{}, ErrUnknownTemplate
+	}
+	if version == 0 {
+		return builtinVersion(def), nil
+	}
+	var found TemplateVersion
+	err := TemplateCollection().FindOne(ctx, bson.M{"name": name, "version": version}).Decode(&found)
+	if err == mongo.ErrNoDocuments {
+		return TemplateVersion{}, ErrTemplateVersion
+	}
+	return found, err
+}
+
+// ActiveTemplate is the version emails are currently sent with
+func ActiveTemplate(ctx context.Context, name string) (TemplateVersion, error) {
+	def, ok := LookupTemplate(name)
+	if !ok {
+		return TemplateVersion{}, ErrUnknownTemplate
+	}
+	var found TemplateVersion
+	err := TemplateCollection().FindOne(ctx, bson.M{"name": name, "active": true},
+		options.FindOne().SetSort(bson.D{{Key: "activated_at", Value: -1}})).Decode(&found)
+	if err == mongo.ErrNoDocuments {
+		builtin := builtinVersion(def)
+		builtin.Active = true
+		return builtin, nil
+	}
+	return found, err
+}
+
+// SaveTemplateVersion stores a new, inactive version after checking it renders with the sample data
+func SaveTemplateVersion(ctx context.Context, version TemplateVersion) (TemplateVersion, error) {
+	def, ok := LookupTemplate(version.Name)
+	if !ok {
+		return TemplateVersion{}, ErrUnknownTemplate
+	}
+	if strings.TrimSpace(version.Subject) == "" || strings.TrimSpace(version.HTML) == "" {
+		return TemplateVersion{}, fmt.Errorf("%w: subject and html are required", ErrBadTemplate)
+	}
+	if _, err := version.Render(def.Sample); err != nil {
+		return TemplateVersion{}, err
+	}
+
+	collection := TemplateCollection()
+	version.Active = false
+	// two saves at once read the same latest version; the unique index turns
+	// the second insert away and it numbers itself again
+	for attempt := 1; ; attempt++ {
+		var latest TemplateVersion
+		err := collection.FindOne(ctx, bson.M{"name": version.Name}, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})).Decode(&latest)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return TemplateVersion{}, err
+		}
+		version.ID = primitive.NewObjectID()
+		version.Version = latest.Version + 1
+		version.Created_at = primitive.NewDateTimeFromTime(time.Now())
+		_, err = collection.InsertOne(ctx, version)
+		if err == nil {
+			return version, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt == templateSaveAttempts {
+			return TemplateVersion{}, err
+		}
+	}
+}
+
+// ActivateTemplateVersion makes a version the one that is sent; 0 goes back to the built-in
+func ActivateTemplateVersion(ctx context.Context, name string, version int) error {
+	if _, err := GetTemplateVersion(ctx, name, version); err != nil {
+		return err
+	}
+	// a single write sets active on the chosen version and clears it on the
+	// others, so a failure cannot leave the old version off and the new one
+	// not on; activated_at decides between two activations that overlap
+	_, err := TemplateCollection().UpdateMany(ctx,
+		bson.M{"name": name, "$or": bson.A{bson.M{"active": true}, bson.M{"version": version}}},
+		bson.A{bson.M{"$set": bson.M{
+			"active":       bson.M{"$eq": bson.A{"$version", version}},
+			"activated_at": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$version", version}}, "$$NOW", "$activated_at"}},
+		}}})
+	return err
+}
+
+// Render fills in the active version of a template
+func Render(ctx context.Context, name string, data map[string]interface{}) (Rendered, error) {
+	version, err := ActiveTemplate(ctx, name)
+	if err != nil {
+		return Rendered{}, err
+	}
+	return version.Render(data)
+}
+
+// Render fills in this version; missing keys are an error
+func (v TemplateVersion) Render(data map[string]interface{}) (Rendered, error) {
+	rendered := Rendered{Template: v.Name, Version: v.Version}
+
+	subject, err := executeText("subject", v.Subject, data)
+	if err != nil {
+		return Rendered{}, err
+	}
+	rendered.Subject = strings.Join(strings.Fields(subject), " ")
+
+	page, err := htmltemplate.New("html").Option("missingkey=error").Parse(v.HTML)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("%w: html: %v", ErrBadTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, data); err != nil {
+		return Rendered{}, fmt.Errorf("%w: html: %v", ErrBadTemplate, err)
+	}
+	rendered.HTML = buf.String()
+
+	if v.Text != "" {
+		if rendered.Text, err = executeText("text", v.Text, data); err != nil {
+			return Rendered{}, err
+		}
+	} else {
+		rendered.Text = plainText(rendered.HTML)
+	}
+	return rendered, nil
+}
+
+func executeText(part, source string, data map[string]interface{}) (string, error) {
+	tmpl, err := texttemplate.New(part).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrBadTemplate, part, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrBadTemplate, part, err)
+	}
+	return buf.String(), nil
+}
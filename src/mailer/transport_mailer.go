@@ -0,0 +1,204 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"magic-server-2026/src/utils"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+   Transports
+   -----------------------------------
+   MAIL_TRANSPORT   smtp | file | log (default: smtp when SMTP_HOST and
+                    SMTP_USER are set, otherwise log). With ENV=production
+                    the server refuses to start on the log transport, since
+                    it would mark every message sent without delivering it.
+   SMTP_HOST, SMTP_PORT (587; 465 = implicit TLS), SMTP_USER, SMTP_PASSWORD
+   MAIL_FROM        sender address (default SMTP_USER)
+   MAIL_FILE_DIR    where the file transport writes .eml files (./mail-dev)
+   MAIL_STAFF_INBOX where staff notifications go (default MAIL_FROM)
+   -----------------------------------
+*/
+
+const smtpTimeout = 30 * time.Second
+
+// Transport delivers one rendered message
+type Transport interface {
+	Name() string
+	Send(ctx context.Context, from string, to []string, raw []byte) error
+}
+
+// permanentError marks failures that retrying will not fix (bad mailbox, rejected sender)
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func isPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+type config struct {
+	transport Transport
+	from      string
+	inbox     string
+}
+
+func loadConfig() config {
+	host, user := utils.GetEnv("SMTP_HOST"), utils.GetEnv("SMTP_USER")
+	from := utils.GetEnv("MAIL_FROM")
+	if from == "" {
+		from = user
+	}
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	cfg := config{from: from, inbox: utils.GetEnv("MAIL_STAFF_INBOX")}
+	if cfg.inbox == "" {
+		cfg.inbox = from
+	}
+
+	kind := strings.ToLower(utils.GetEnv("MAIL_TRANSPORT"))
+	if kind == "" {
+		kind = "log"
+		if host != "" && user != "" {
+			kind = "smtp"
+		}
+	}
+	switch kind {
+	case "smtp":
+		port := utils.GetEnv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		cfg.transport = &smtpTransport{host: host, port: port, user: user, password: utils.GetEnv("SMTP_PASSWORD")}
+	case "file":
+		dir := utils.GetEnv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "./mail-dev"
+		}
+		cfg.transport = &fileTransport{dir: dir}
+	default:
+		if kind != "log" {
+			log.Printf("[MAILER] unknown MAIL_TRANSPORT %q, using log", kind)
+		}
+		log.Println("[MAILER] ⚠️ no SMTP configured; emails are only logged")
+		cfg.transport = logTransport{}
+	}
+	return cfg
+}
+
+// smtpTransport sends through an SMTP relay with STARTTLS (or implicit TLS on 465)
+type smtpTransport struct {
+	host, port     string
+	user, password string
+}
+
+func (t *smtpTransport) Name() string { return "smtp" }
+
+func (t *smtpTransport) Send(ctx context.Context, from string, to []string, raw []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(t.host, t.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if t.port == "465" {
+		conn = tls.Client(conn, &tls.Config{ServerName: t.host})
+	}
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && t.port != "465" {
+		if err := client.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+			return err
+		}
+	}
+	if t.user != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.user, t.password, t.host)); err != nil {
+			return classifySMTP(err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return classifySMTP(err)
+	}
+	for _, address := range to {
+		if err := client.Rcpt(address); err != nil {
+			return classifySMTP(err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return classifySMTP(err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTP(err)
+	}
+	return client.Quit()
+}
+
+// classifySMTP treats 5xx replies as permanent; everything else is retried
+func classifySMTP(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return permanentError{err}
+	}
+	return err
+}
+
+// fileTransport writes each message as an .eml file for local development
+type fileTransport struct{ dir string }
+
+func (t *fileTransport) Name() string { return "file" }
+
+func (t *fileTransport) Send(_ context.Context, from string, to []string, raw []byte) error {
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000"), strings.NewReplacer("@", "_at_", "/", "_").Replace(to[0]))
+	path := filepath.Join(t.dir, name)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return err
+	}
+	log.Printf("[MAILER] wrote %s (from %s to %s)", path, from, strings.Join(to, ", "))
+	return nil
+}
+
+// logTransport only logs who would have received the message
+type logTransport struct{}
+
+func (logTransport) Name() string { return "log" }
+
+func (logTransport) Send(_ context.Context, from string, to []string, raw []byte) error {
+	subject := ""
+	if msg, err := mail.ReadMessage(strings.NewReader(string(raw))); err == nil {
+		subject = msg.Header.Get("Subject")
+	}
+	log.Printf("[MAILER] (log transport) from %s to %s: %s", from, strings.Join(to, ", "), subject)
+	return nil
+}
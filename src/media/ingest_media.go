@@ -0,0 +1,176 @@
+package media
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+
+	_ "golang.org/x/image/webp"
+)
+
+/*
+   Image ingestion
+   -----------------------------------
+   Every uploaded image goes through Ingest before it is stored:
+   1. size cap on the raw bytes
+   2. type from the magic bytes (never the filename or Content-Type);
+      only JPEG, PNG, WebP and AVIF are accepted
+   3. metadata stripped without re-encoding: EXIF (GPS, camera,
+      timestamps), XMP, IPTC and text chunks go, color profiles stay.
+      A JPEG's EXIF orientation is kept as a minimal EXIF block so the
+      photo does not turn sideways.
+   4. pixel dimensions checked before the image is decoded, then the
+      whole image decoded once to prove it is not corrupt (AVIF, which
+      Go cannot decode, is checked structurally)
+   5. named by the SHA-256 of the cleaned bytes, so the same picture
+      uploaded twice is stored once
+   -----------------------------------
+*/
+
+// Accepted image types
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeWebP = "image/webp"
+	TypeAVIF = "image/avif"
+)
+
+var extensions = map[string]string{TypeJPEG: ".jpg", TypePNG: ".png", TypeWebP: ".webp", TypeAVIF: ".avif"}
+
+var (
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("image must be a JPEG, PNG, WebP or AVIF file")
+	ErrDimensions      = errors.New("image dimensions are out of range")
+	ErrCorrupt         = errors.New("image file is damaged or not what it claims to be")
+)
+
+// Limits bound what Ingest accepts
+type Limits struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+	MaxPixels int // width x height
+}
+
+// DefaultImageLimits apply to the media store
+var DefaultImageLimits = Limits{
+	MaxBytes:  10 << 20,
+	MaxWidth:  8192,
+	MaxHeight: 8192,
+	MaxPixels: 40_000_000,
+}
+
+// Image is an accepted upload, cleaned and ready to store
+type Image struct {
+	Data   []byte
+	Type   string // MIME type
+	Width  int
+	Height int
+	Hash   string // hex SHA-256 of Data
+}
+
+// Ext is the file extension of the image type
+func (img Image) Ext() string { return extensions[img.Type] }
+
+// Name is the content-hash file name of the image
+func (img Image) Name() string { return img.Hash[:32] + img.Ext() }
+
+// Ingest checks and cleans an uploaded image
+func Ingest(data []byte, limits Limits) (Image, error) {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return Image{}, fmt.Errorf("%w (max %d MB)", ErrTooLarge, limits.MaxBytes>>20)
+	}
+	kind := Sniff(data)
+
+	var clean []byte
+	var width, height int
+	var err error
+	switch kind {
+	case TypeJPEG:
+		clean, err = stripJPEG(data)
+	case TypePNG:
+		clean, err = stripPNG(data)
+	case TypeWebP:
+		clean, err = stripWebP(data)
+	case TypeAVIF:
+		clean, width, height, err = stripAVIF(data)
+	default:
+		return Image{}, ErrUnsupportedType
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	if kind != TypeAVIF {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(clean))
+		if err != nil {
+			return Image{}, ErrCorrupt
+		}
+		width, height = cfg.Width, cfg.Height
+	}
+	if width <= 0 || height <= 0 ||
+		(limits.MaxWidth > 0 && width > limits.MaxWidth) ||
+		(limits.MaxHeight > 0 && height > limits.MaxHeight) ||
+		(limits.MaxPixels > 0 && width*height > limits.MaxPixels) {
+		return Image{}, fmt.Errorf("%w (%dx%d, max %dx%d)", ErrDimensions, width, height, limits.MaxWidth, limits.MaxHeight)
+	}
+	if kind != TypeAVIF {
+		if _, _, err := image.Decode(bytes.NewReader(clean)); err != nil {
+			return Image{}, ErrCorrupt
+		}
+	}
+
+	sum := sha256.Sum256(clean)
+	return Image{Data: clean, Type: kind, Width: width, Height: height, Hash: hex.EncodeToString(sum[:])}, nil
+}
+
+// Store writes the image to dir under name unless a file of that name is already
+// there (same name, same content); the write is atomic so readers never see half a file
+func Store(dir, name string, data []byte) error {
+	if name == "" || name != filepath.Base(name) {
+		return errors.New("invalid file name")
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Dimensions reads the pixel size of a stored image without decoding it
+func Dimensions(data []byte) (width, height int, err error) {
+	if Sniff(data) == TypeAVIF {
+		_, width, height, err = stripAVIF(data)
+		return width, height, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, ErrCorrupt
+	}
+	return cfg.Width, cfg.Height, nil
+}
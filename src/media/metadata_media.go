@@ -0,0 +1,490 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+/*
+   Metadata stripping
+   -----------------------------------
+   JPEG  drop APP1 (EXIF/XMP), APP13 (IPTC), COM, MPF (APP2) and vendor
+         APPn segments; keep JFIF, ICC (APP2) and Adobe (APP14); re-add the
+         orientation alone. The markers are walked through every scan and
+         the file ends at the first EOI after them, so the second images
+         of MPF and Ultra HDR files (with their own EXIF) are dropped too
+   PNG   keep an allowlist of rendering chunks; tEXt/zTXt/iTXt/eXIf/tIME
+         and private chunks go
+   WebP  drop the EXIF and XMP chunks and clear their VP8X flags
+   AVIF  zero the payload of Exif and XMP items in place, so the item
+         locations stay valid without rewriting the container
+   Anything after the end of the image (JPEG EOI, PNG IEND, the RIFF
+   size) is dropped, which also defeats polyglot files.
+   -----------------------------------
+*/
+
+// stripJPEG rewrites the marker segments of the first image, up to its EOI
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	insertAt := len(out) // where the orientation block goes: after SOI, or after APP0 when it leads
+	orientation := 1
+	scanned := false
+
+	for i := 2; ; {
+		if i >= len(data) || data[i] != 0xFF {
+			return nil, ErrCorrupt
+		}
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+		if i >= len(data) {
+			return nil, ErrCorrupt
+		}
+		marker := data[i]
+		i++
+		if marker == 0xD9 {
+			if !scanned {
+				return nil, ErrCorrupt // end of image before any scan
+			}
+			out = append(out, 0xFF, 0xD9)
+			break
+		}
+		if (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 {
+			out = append(out, 0xFF, marker)
+			continue
+		}
+		if i+2 > len(data) {
+			return nil, ErrCorrupt
+		}
+		length := int(binary.BigEndian.Uint16(data[i:]))
+		if length < 2 || i+length > len(data) {
+			return nil, ErrCorrupt
+		}
+
+		if marker == 0xDA {
+			// start of scan: the entropy-coded data runs to the next marker
+			// that is neither a stuffed 0xFF00 nor a restart marker
+			end := i + length
+			for ; end+1 < len(data); end++ {
+				if data[end] == 0xFF && data[end+1] != 0x00 && (data[end+1] < 0xD0 || data[end+1] > 0xD7) {
+					break
+				}
+			}
+			if end+1 >= len(data) {
+				return nil, ErrCorrupt
+			}
+			out = append(out, data[i-2:end]...)
+			i = end
+			scanned = true
+			continue
+		}
+
+		keep := true
+		switch {
+		case marker == 0xE1:
+			if o := exifOrientation(data[i+2 : i+length]); o > 1 && orientation == 1 {
+				orientation = o
+			}
+			keep = false
+		case marker == 0xE2:
+			// ICC profiles stay; the MPF index points at images that are cut off
+			keep = !bytes.HasPrefix(data[i+2:i+length], []byte("MPF\x00"))
+		case marker >= 0xE0 && marker <= 0xEF:
+			keep = marker == 0xE0 || marker == 0xE2 || marker == 0xEE
+		case marker == 0xFE:
+			keep = false
+		}
+		if keep {
+			if marker == 0xE0 && len(out) == 2 {
+				insertAt = 2 + 2 + length
+			}
+			out = append(out, data[i-2:i+length]...)
+		}
+		i += length
+	}
+
+	if orientation > 1 {
+		block := orientationEXIF(orientation)
+		out = append(out[:insertAt], append(block, out[insertAt:]...)...)
+	}
+	return out, nil
+}
+
+// exifOrientation reads tag 0x0112 from IFD0 of an APP1 EXIF payload (0 when absent)
+func exifOrientation(payload []byte) int {
+	if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationEXIF is an APP1 segment holding nothing but the orientation tag
+func orientationEXIF(orientation int) []byte {
+	return []byte{
+		0xFF, 0xE1, 0x00, 0x22, // APP1, length 34
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // big-endian TIFF, IFD0 at 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00, // Orientation SHORT
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+}
+
+// pngKeep are the chunks that affect how a PNG looks; everything else is dropped
+var pngKeep = map[string]bool{
+	"IHDR": true, "PLTE": true, "IDAT": true, "IEND": true,
+	"tRNS": true, "cHRM": true, "gAMA": true, "iCCP": true, "sBIT": true, "sRGB": true,
+	"cICP": true, "mDCv": true, "cLLi": true, "bKGD": true, "pHYs": true,
+	"acTL": true, "fcTL": true, "fdAT": true, // APNG frames
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrCorrupt
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	for i := len(pngSignature); ; {
+		if i+8 > len(data) {
+			return nil, ErrCorrupt
+		}
+		length := int64(binary.BigEndian.Uint32(data[i:]))
+		end := int64(i) + 12 + length
+		if end > int64(len(data)) {
+			return nil, ErrCorrupt
+		}
+		kind := string(data[i+4 : i+8])
+		if pngKeep[kind] {
+			out = append(out, data[i:end]...)
+		}
+		i = int(end)
+		if kind == "IEND" {
+			return out, nil
+		}
+	}
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	end := 8 + int64(binary.LittleEndian.Uint32(data[4:]))
+	if end > int64(len(data)) || end < 12 {
+		return nil, ErrCorrupt
+	}
+	out := make([]byte, 0, end)
+	out = append(out, "RIFF\x00\x00\x00\x00WEBP"...)
+	vp8x := -1
+	for i := int64(12); i < end; {
+		if i+8 > end {
+			return nil, ErrCorrupt
+		}
+		fourcc := string(data[i : i+4])
+		size := int64(binary.LittleEndian.Uint32(data[i+4:]))
+		if i+8+size > end {
+			return nil, ErrCorrupt
+		}
+		next := min(i+8+size+size&1, end)
+		if fourcc != "EXIF" && fourcc != "XMP " {
+			if fourcc == "VP8X" {
+				vp8x = len(out)
+			}
+			out = append(out, data[i:next]...)
+			if size&1 == 1 && i+8+size == end {
+				out = append(out, 0) // restore a missing pad byte
+			}
+		}
+		i = next
+	}
+	if vp8x >= 0 && len(out) > vp8x+8 {
+		out[vp8x+8] &^= 0x08 | 0x04 // EXIF and XMP present flags
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// isoBox is one ISO BMFF box: header at start, payload from body to end
+type isoBox struct {
+	kind             string
+	start, body, end int
+}
+
+func readBoxes(data []byte, start, end int) ([]isoBox, error) {
+	var boxes []isoBox
+	for i := start; i < end; {
+		if i+8 > end {
+			return nil, ErrCorrupt
+		}
+		size := int64(binary.BigEndian.Uint32(data[i:]))
+		kind := string(data[i+4 : i+8])
+		body := i + 8
+		switch size {
+		case 0:
+			size = int64(end - i)
+		case 1:
+			if i+16 > end {
+				return nil, ErrCorrupt
+			}
+			size = int64(binary.BigEndian.Uint64(data[i+8:]))
+			body = i + 16
+		}
+		if kind == "uuid" {
+			body += 16
+		}
+		if size < int64(body-i) || int64(i)+size > int64(end) {
+			return nil, ErrCorrupt
+		}
+		boxes = append(boxes, isoBox{kind: kind, start: i, body: body, end: i + int(size)})
+		i += int(size)
+	}
+	return boxes, nil
+}
+
+func findBox(boxes []isoBox, kind string) (isoBox, bool) {
+	for _, b := range boxes {
+		if b.kind == kind {
+			return b, true
+		}
+	}
+	return isoBox{}, false
+}
+
+// boxReader reads big-endian fields, remembering if it ran past the end
+type boxReader struct {
+	data     []byte
+	pos, end int
+	bad      bool
+}
+
+func (r *boxReader) uint(n int) uint64 {
+	if n == 0 {
+		return 0
+	}
+	if r.pos+n > r.end {
+		r.bad = true
+		return 0
+	}
+	var v uint64
+	for _, b := range r.data[r.pos : r.pos+n] {
+		v = v<<8 | uint64(b)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *boxReader) cstring() string {
+	zero := bytes.IndexByte(r.data[min(r.pos, r.end):r.end], 0)
+	if zero < 0 {
+		r.bad = true
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+zero])
+	r.pos += zero + 1
+	return s
+}
+
+// stripAVIF blanks Exif and XMP items and reads the image size from the ispe properties
+func stripAVIF(data []byte) ([]byte, int, int, error) {
+	clean := bytes.Clone(data)
+	top, err := readBoxes(clean, 0, len(clean))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	meta, ok := findBox(top, "meta")
+	if !ok || meta.body+4 > meta.end {
+		return nil, 0, 0, ErrCorrupt
+	}
+	children, err := readBoxes(clean, meta.body+4, meta.end)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	iinf, hasIinf := findBox(children, "iinf")
+	iloc, hasIloc := findBox(children, "iloc")
+	iprp, hasIprp := findBox(children, "iprp")
+	if !hasIinf || !hasIloc || !hasIprp {
+		return nil, 0, 0, ErrCorrupt
+	}
+
+	// which items are metadata
+	metadata := map[uint64]bool{}
+	r := &boxReader{data: clean, pos: iinf.body, end: iinf.end}
+	if r.uint(1) == 0 {
+		r.pos += 3
+		r.uint(2)
+	} else {
+		r.pos += 3
+		r.uint(4)
+	}
+	if r.bad {
+		return nil, 0, 0, ErrCorrupt
+	}
+	entries, err := readBoxes(clean, r.pos, iinf.end)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, infe := range entries {
+		if infe.kind != "infe" {
+			continue
+		}
+		e := &boxReader{data: clean, pos: infe.body, end: infe.end}
+		version := e.uint(1)
+		e.pos += 3
+		if version < 2 {
+			continue
+		}
+		var id uint64
+		if version == 2 {
+			id = e.uint(2)
+		} else {
+			id = e.uint(4)
+		}
+		e.uint(2) // protection index
+		kind := string(clean[min(e.pos, e.end):min(e.pos+4, e.end)])
+		e.pos += 4
+		e.cstring() // item name
+		if kind == "mime" {
+			if contentType := e.cstring(); strings.Contains(contentType, "rdf+xml") || strings.Contains(contentType, "xmp") {
+				metadata[id] = true
+			}
+		}
+		if kind == "Exif" {
+			metadata[id] = true
+		}
+	}
+
+	// blank their extents
+	idat, hasIdat := findBox(children, "idat")
+	l := &boxReader{data: clean, pos: iloc.body, end: iloc.end}
+	version := l.uint(1)
+	l.pos += 3
+	sizes := l.uint(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0F)
+	sizes = l.uint(1)
+	baseOffsetSize, indexSize := int(sizes>>4), 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0x0F)
+	}
+	var itemCount uint64
+	if version < 2 {
+		itemCount = l.uint(2)
+	} else {
+		itemCount = l.uint(4)
+	}
+	for n := uint64(0); n < itemCount && !l.bad; n++ {
+		var id uint64
+		if version < 2 {
+			id = l.uint(2)
+		} else {
+			id = l.uint(4)
+		}
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = l.uint(2) & 0x0F
+		}
+		l.uint(2) // data reference index
+		base := l.uint(baseOffsetSize)
+		extents := l.uint(2)
+		for x := uint64(0); x < extents && !l.bad; x++ {
+			l.uint(indexSize)
+			offset, length := base+l.uint(offsetSize), l.uint(lengthSize)
+			if !metadata[id] {
+				continue
+			}
+			origin, limit := uint64(0), uint64(len(clean))
+			switch {
+			case method == 1 && hasIdat:
+				origin, limit = uint64(idat.body), uint64(idat.end)
+			case method != 0:
+				return nil, 0, 0, ErrCorrupt
+			}
+			start, stop := origin+offset, origin+offset+length
+			if length == 0 || start > limit || stop > limit || stop < start {
+				return nil, 0, 0, ErrCorrupt
+			}
+			clear(clean[start:stop])
+		}
+	}
+	if l.bad {
+		return nil, 0, 0, ErrCorrupt
+	}
+
+	// the largest ispe is the full image (others belong to alpha planes or thumbnails)
+	width, height := 0, 0
+	properties, err := readBoxes(clean, iprp.body, iprp.end)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if ipco, ok := findBox(properties, "ipco"); ok {
+		props, err := readBoxes(clean, ipco.body, ipco.end)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		for _, prop := range props {
+			if prop.kind != "ispe" {
+				continue
+			}
+			p := &boxReader{data: clean, pos: prop.body + 4, end: prop.end}
+			w, h := int(p.uint(4)), int(p.uint(4))
+			if !p.bad && w*h > width*height {
+				width, height = w, h
+			}
+		}
+	}
+	return clean, width, height, nil
+}
+
+// JPEGOrientation is the EXIF orientation (1-8) of a JPEG; 1 when it has none
+func JPEGOrientation(data []byte) int {
+	if Sniff(data) != TypeJPEG {
+		return 1
+	}
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		if marker == 0xE1 {
+			if o := exifOrientation(data[i+4 : i+2+length]); o > 0 {
+				return o
+			}
+		}
+		i += 2 + length
+	}
+	return 1
+}
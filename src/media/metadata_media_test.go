@@ -0,0 +1,277 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// secret stands in for GPS coordinates, camera serials and the like
+const secret = "SECRET-GPS-48.8584N"
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 16), uint8(y * 32), 128, 255})
+		}
+	}
+	return img
+}
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// withSegments inserts segments right after the SOI of a JPEG
+func withSegments(jpg []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, jpg[:2]...)
+	for _, seg := range segments {
+		out = append(out, seg...)
+	}
+	return append(out, jpg[2:]...)
+}
+
+// exifPayload is an APP1 EXIF payload with an orientation tag and a GPS IFD holding secret
+func exifPayload(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x02,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00,
+		0x88, 0x25, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x26, // GPS IFD
+		0x00, 0x00, 0x00, 0x00,
+	}
+	return append(append([]byte("Exif\x00\x00"), tiff...), secret...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	base := testJPEG(t)
+	icc := jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile"))
+	mpf := jpegSegment(0xE2, []byte("MPF\x00II*\x00"+secret))
+	// the second image of an MPF / Ultra HDR file, with its own EXIF
+	second := withSegments(base, jpegSegment(0xE1, exifPayload(1)))
+
+	tests := []struct {
+		name        string
+		in          []byte
+		orientation int
+		keep        [][]byte
+		err         error
+	}{
+		{name: "plain", in: base},
+		{name: "exif with gps", in: withSegments(base, jpegSegment(0xE1, exifPayload(1)))},
+		{name: "exif orientation is kept alone", in: withSegments(base, jpegSegment(0xE1, exifPayload(6))), orientation: 6},
+		{name: "comment and iptc", in: withSegments(base, jpegSegment(0xFE, []byte(secret)), jpegSegment(0xED, []byte("Photoshop 3.0\x00"+secret)))},
+		{name: "icc profile is kept", in: withSegments(base, icc), keep: [][]byte{icc}},
+		{name: "trailer after eoi", in: append(append([]byte{}, base...), "PK\x03\x04"+secret...)},
+		{name: "mpf second image", in: append(withSegments(base, icc, mpf), second...), keep: [][]byte{icc}},
+		{name: "truncated scan", in: base[:len(base)-2], err: ErrCorrupt},
+		{name: "no scan", in: []byte{0xFF, 0xD8, 0xFF, 0xD9}, err: ErrCorrupt},
+		{name: "not a marker", in: []byte{0xFF, 0xD8, 0x00, 0x01}, err: ErrCorrupt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := stripJPEG(tt.in)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(out, []byte(secret)) {
+				t.Error("metadata survived")
+			}
+			if n := bytes.Count(out, []byte{0xFF, 0xD8}); n != 1 {
+				t.Errorf("%d SOI markers, want 1", n)
+			}
+			if !bytes.HasSuffix(out, []byte{0xFF, 0xD9}) {
+				t.Error("output does not end at EOI")
+			}
+			for _, seg := range tt.keep {
+				if !bytes.Contains(out, seg) {
+					t.Errorf("segment %q was dropped", seg[4:])
+				}
+			}
+			orientation := 1
+			if i := bytes.Index(out, []byte("Exif\x00\x00")); i >= 0 {
+				orientation = exifOrientation(out[i:])
+			}
+			if want := max(tt.orientation, 1); orientation != want {
+				t.Errorf("orientation = %d, want %d", orientation, want)
+			}
+			img, err := jpeg.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatal("stripped image does not decode:", err)
+			}
+			if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 8 {
+				t.Errorf("size = %v", b)
+			}
+		})
+	}
+}
+
+// TestStripJPEGRestartMarkers checks that RSTn and stuffed bytes do not end the scan
+func TestStripJPEGRestartMarkers(t *testing.T) {
+	scan := []byte{0x12, 0xFF, 0x00, 0x34, 0xFF, 0xD0, 0x56, 0xFF, 0xD7, 0x78}
+	in := []byte{0xFF, 0xD8}
+	in = append(in, jpegSegment(0xDA, []byte{1, 1, 0, 0, 63, 0})...)
+	in = append(in, scan...)
+	in = append(in, 0xFF, 0xD9)
+	in = append(in, "trailer"...)
+
+	out, err := stripJPEG(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := in[:len(in)-len("trailer")]; !bytes.Equal(out, want) {
+		t.Errorf("got % X\nwant % X", out, want)
+	}
+}
+
+func pngChunk(kind string, payload []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	base := buf.Bytes()
+	iend := len(base) - 12
+	// withChunks inserts chunks right before IEND
+	withChunks := func(chunks ...[]byte) []byte {
+		out := append([]byte{}, base[:iend]...)
+		for _, chunk := range chunks {
+			out = append(out, chunk...)
+		}
+		return append(out, base[iend:]...)
+	}
+	srgb := pngChunk("sRGB", []byte{0})
+
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+		err  error
+	}{
+		{name: "plain", in: base, want: base},
+		{name: "text chunks", in: withChunks(pngChunk("tEXt", []byte("Comment\x00"+secret)), pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+secret))), want: base},
+		{name: "exif and time", in: withChunks(pngChunk("eXIf", []byte(secret)), pngChunk("tIME", []byte{7, 234, 1, 1, 0, 0, 0})), want: base},
+		{name: "private chunk", in: withChunks(pngChunk("prVt", []byte(secret))), want: base},
+		{name: "rendering chunk is kept", in: withChunks(srgb), want: withChunks(srgb)},
+		{name: "trailer after iend", in: append(append([]byte{}, base...), secret...), want: base},
+		{name: "truncated", in: base[:iend], err: ErrCorrupt},
+		{name: "not a png", in: []byte("GIF89a"), err: ErrCorrupt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := stripPNG(tt.in)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("got %d bytes, want %d", len(out), len(tt.want))
+			}
+			if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+				t.Error("stripped image does not decode:", err)
+			}
+		})
+	}
+}
+
+func webpChunk(fourcc string, payload []byte) []byte {
+	chunk := append([]byte(fourcc), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func riff(chunks ...[]byte) []byte {
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X flags: 0x08 EXIF, 0x04 XMP, 0x10 alpha; canvas 16x8
+	vp8x := func(flags byte) []byte {
+		return webpChunk("VP8X", []byte{flags, 0, 0, 0, 15, 0, 0, 7, 0, 0})
+	}
+	frame := webpChunk("VP8L", []byte{0x2F, 0x0F, 0xC0, 0x01, 0x00})
+	iccp := webpChunk("ICCP", []byte("profile"))
+	exif := webpChunk("EXIF", exifPayload(1)[6:])
+	xmp := webpChunk("XMP ", []byte("<x:xmpmeta>"+secret+"</x:xmpmeta>"))
+
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+		err  error
+	}{
+		{name: "simple", in: riff(frame), want: riff(frame)},
+		{name: "exif and xmp", in: riff(vp8x(0x08|0x04|0x10), iccp, frame, exif, xmp), want: riff(vp8x(0x10), iccp, frame)},
+		{name: "trailer after riff", in: append(riff(frame), secret...), want: riff(frame)},
+		{name: "missing pad byte", in: riff(frame)[:len(riff(frame))-1], want: riff(frame)},
+		{name: "chunk past riff end", in: riff(webpChunk("VP8L", make([]byte, 4)))[:20], err: ErrCorrupt},
+		{name: "riff too short", in: []byte("RIFF\x02\x00\x00\x00WEBP"), err: ErrCorrupt},
+	}
+	// a RIFF whose size covers one byte less than the padded frame
+	tests[3].in = append([]byte{}, tests[3].in...)
+	binary.LittleEndian.PutUint32(tests[3].in[4:], uint32(len(tests[3].in)-8))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := stripWebP(tt.in)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("got  % X\nwant % X", out, tt.want)
+			}
+			if bytes.Contains(out, []byte(secret)) {
+				t.Error("metadata survived")
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Sniff names the image type from the magic bytes ("" when it is not an accepted type)
+func Sniff(data []byte) string {
+	switch {
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return TypeJPEG
+	case bytes.HasPrefix(data, pngSignature):
+		return TypePNG
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return TypeWebP
+	case isAVIF(data):
+		return TypeAVIF
+	}
+	return ""
+}
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+// isAVIF checks for an ftyp box whose major or compatible brands include avif/avis
+func isAVIF(data []byte) bool {
+	if len(data) < 16 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	size := int(binary.BigEndian.Uint32(data))
+	if size < 16 || size > len(data) {
+		return false
+	}
+	for i := 8; i+4 <= size; i += 4 {
+		if i == 12 {
+			continue // minor version
+		}
+		if brand := string(data[i : i+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
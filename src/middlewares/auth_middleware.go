@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/models"
+	"magic-server-2026/src/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+/*
+	Auth Middleware
+	- Resolves the logged-in staff user from the session_id + access_token cookies
+	- access_token is RSA encrypted with the user's public key (see controllers.Login)
+	- Exposes user_id, username and role as locals for RoleFilterMiddleware and controllers
+*/
+
+func AuthMiddleware(c fiber.Ctx) error {
+	sessionID := c.Cookies("session_id")
+	encryptedAccess := c.Cookies("access_token")
+
+	if sessionID == "" || encryptedAccess == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Missing session",
+		})
+	}
+
+	collection := db.Client.Database("magic899").Collection("users")
+	var user models.User
+	if err := collection.FindOne(c.Context(), bson.M{"session_id": sessionID}).Decode(&user); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid session",
+		})
+	}
+
+	accessToken, err := utils.DecryptWithPrivateKey([]byte(encryptedAccess), user.RSAPrivate)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid access token",
+		})
+	}
+
+	claims, err := ValidateAccessToken(string(accessToken))
+	if err != nil || claims.SessionID != sessionID || claims.UserID != user.ID.Hex() {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid access token",
+		})
+	}
+
+	c.Locals("user_id", user.ID)
+	c.Locals("username", user.Username)
+	c.Locals("role", claims.Role)
+
+	return c.Next()
+}
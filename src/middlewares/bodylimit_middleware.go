@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+// UploadBodyLimit is the request body limit of the upload routes below:
+// talent search videos (60 MB) and tus chunks (32 MB). Every other route
+// keeps fiber's default BodyLimit of 4 MB.
+const UploadBodyLimit = 64 << 20
+
+var uploadRoutes = []struct {
+	method string
+	path   *regexp.Regexp
+}{
+	{fiber.MethodPut, regexp.MustCompile(`(?i)^/api/v1/talent/applications/[^/]+/media/[^/]+/?$`)},
+	{fiber.MethodPatch, regexp.MustCompile(`(?i)^/api/v1/uploads/videos/[^/]+/?$`)},
+	{fiber.MethodPost, regexp.MustCompile(`(?i)^/api/v1/uploads/videos/[^/]+/?$`)}, // X-HTTP-Method-Override: PATCH
+}
+
+// SetupUploadBodyLimit raises the body limit for the upload routes only.
+// The body is read before any handler runs and fiber has a single BodyLimit,
+// so the limit is picked from the request line as soon as the headers arrive.
+func SetupUploadBodyLimit(app *fiber.App) {
+	app.Server().HeaderReceived = func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		path, _, _ := strings.Cut(string(header.RequestURI()), "?")
+		method := string(header.Method())
+		for _, route := range uploadRoutes {
+			if method == route.method && route.path.MatchString(path) {
+				return fasthttp.RequestConfig{MaxRequestBodySize: UploadBodyLimit}
+			}
+		}
+		return fasthttp.RequestConfig{}
+	}
+}
@@ -70,8 +70,27 @@ func CORSMiddleware(c fiber.Ctx) error {
 		"Accept",
 		"Origin",
 		"Cache-Control",
+		// tus resumable uploads
+		"Tus-Resumable",
+		"Upload-Length",
+		"Upload-Offset",
+		"Upload-Metadata",
+		"Upload-Checksum",
+		"Upload-Defer-Length",
+		"X-HTTP-Method-Override",
+	}, ", "))
+	c.Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+	c.Set("Access-Control-Expose-Headers", strings.Join([]string{
+		"Location",
+		"Upload-Offset",
+		"Upload-Length",
+		"Upload-Expires",
+		"Tus-Resumable",
+		"Tus-Version",
+		"Tus-Extension",
+		"Tus-Max-Size",
+		"Tus-Checksum-Algorithm",
 	}, ", "))
-	c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 	c.Set("Cache-Control", "no-store, max-age=15")
 	c.Set("Pragma", "no-cache")
 	c.Set("Expires", "0")
@@ -113,8 +132,8 @@ func CORSMiddleware(c fiber.Ctx) error {
 	}, "; "))
 	c.Set("Content-Security-Policy", csp)
 
-	// Preflight
-	if c.Method() == fiber.MethodOptions {
+	// Preflight; other OPTIONS requests (tus discovery) go on to their routes
+	if c.Method() == fiber.MethodOptions && c.Get("Access-Control-Request-Method") != "" {
 		return c.SendStatus(fiber.StatusNoContent)
 	}
 
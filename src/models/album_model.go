@@ -0,0 +1,48 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// PhotoAlbum is an ordered photo gallery from a station event. Images are media
+// store files; Cover is one of them (the first image when empty).
+type PhotoAlbum struct {
+	ID           primitive.ObjectID  `bson:"_id" json:"id"`
+	Slug         string              `bson:"slug" json:"slug"`
+	Title        string              `bson:"title" json:"title"`
+	Description  string              `bson:"description" json:"description"`
+	Venue        string              `bson:"venue,omitempty" json:"venue,omitempty"`
+	Taken_at     primitive.DateTime  `bson:"taken_at,omitempty" json:"taken_at,omitempty"` // day of the event
+	Photographer string              `bson:"photographer,omitempty" json:"photographer,omitempty"`
+	News_id      *primitive.ObjectID `bson:"news_id,omitempty" json:"news_id,omitempty"`
+	Cover        string              `bson:"cover" json:"cover"`
+	Images       []PhotoAlbumImage   `bson:"images" json:"images"`
+	Image_count  int                 `bson:"image_count" json:"image_count"`
+	Published    bool                `bson:"published" json:"published"`
+
+	Created_by string             `bson:"created_by" json:"created_by"`
+	Created_at primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// PhotoAlbumImage is one photo of an album, in display order
+type PhotoAlbumImage struct {
+	File_name string `bson:"file_name" json:"file_name"`
+	Caption   string `bson:"caption,omitempty" json:"caption,omitempty"`
+	Credit    string `bson:"credit,omitempty" json:"credit,omitempty"` // overrides the album photographer
+	Alt       string `bson:"alt,omitempty" json:"alt,omitempty"`
+	Width     int    `bson:"width,omitempty" json:"width,omitempty"`
+	Height    int    `bson:"height,omitempty" json:"height,omitempty"`
+}
+
+// PhotoAlbumInput is what staff send to create or update an album; nil fields are left unchanged
+type PhotoAlbumInput struct {
+	Slug         *string            `json:"slug"`
+	Title        *string            `json:"title"`
+	Description  *string            `json:"description"`
+	Venue        *string            `json:"venue"`
+	Taken_at     *string            `json:"taken_at"` // YYYY-MM-DD, "" clears
+	Photographer *string            `json:"photographer"`
+	News_id      *string            `json:"news_id"` // "" unlinks
+	Cover        *string            `json:"cover"`
+	Images       *[]PhotoAlbumImage `json:"images"` // replaces the list, in this order
+	Published    *bool              `json:"published"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Block types supported by ContentBlocks
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockImage     = "image"
+	BlockEmbed     = "embed"
+	BlockQuote     = "quote"
+	BlockList      = "list"
+)
+
+// ContentBlock is one typed block of a rich body (news content, show description, movie synopsis).
+// Only the fields relevant to Type are set; helpers.SanitizeContentBlocks validates them.
+type ContentBlock struct {
+	Type string `bson:"type" json:"type"`
+
+	// paragraph, heading, quote
+	Text  string `bson:"text,omitempty" json:"text,omitempty"`
+	Level int    `bson:"level,omitempty" json:"level,omitempty"` // heading: 2-4
+	Cite  string `bson:"cite,omitempty" json:"cite,omitempty"`   // quote attribution
+
+	// image (filename in the media store)
+	Image   string `bson:"image,omitempty" json:"image,omitempty"`
+	Alt     string `bson:"alt,omitempty" json:"alt,omitempty"`
+	Caption string `bson:"caption,omitempty" json:"caption,omitempty"`
+	Credit  string `bson:"credit,omitempty" json:"credit,omitempty"`
+
+	// embed
+	Provider string `bson:"provider,omitempty" json:"provider,omitempty"`
+	URL      string `bson:"url,omitempty" json:"url,omitempty"`
+
+	// list
+	Ordered bool     `bson:"ordered,omitempty" json:"ordered,omitempty"`
+	Items   []string `bson:"items,omitempty" json:"items,omitempty"`
+}
+
+// ContentBlocks is a block document. Legacy documents stored the body as
+// []string paragraphs; those are read back as paragraph blocks.
+type ContentBlocks []ContentBlock
+
+// UnmarshalBSONValue accepts both block documents and legacy string arrays
+func (b *ContentBlocks) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.Null || t == bsontype.Undefined {
+		*b = nil
+		return nil
+	}
+	if t != bsontype.Array {
+		return errors.New("content must be an array")
+	}
+
+	values, err := bson.Raw(data).Values()
+	if err != nil {
+		return err
+	}
+
+	blocks := make(ContentBlocks, 0, len(values))
+	for _, v := range values {
+		switch v.Type {
+		case bsontype.String:
+			blocks = append(blocks, ContentBlock{Type: BlockParagraph, Text: v.StringValue()})
+		case bsontype.EmbeddedDocument:
+			var block ContentBlock
+			if err := v.Unmarshal(&block); err != nil {
+				return err
+			}
+			blocks = append(blocks, block)
+		default:
+			return errors.New("content items must be strings or blocks")
+		}
+	}
+
+	*b = blocks
+	return nil
+}
+
+// UnmarshalJSON accepts both block objects and plain paragraph strings
+func (b *ContentBlocks) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*b = nil
+		return nil
+	}
+
+	blocks := make(ContentBlocks, 0, len(raw))
+	for _, item := range raw {
+		var text string
+		if err := json.Unmarshal(item, &text); err == nil {
+			blocks = append(blocks, ContentBlock{Type: BlockParagraph, Text: text})
+			continue
+		}
+
+		var block ContentBlock
+		if err := json.Unmarshal(item, &block); err != nil {
+			return errors.New("content items must be strings or blocks")
+		}
+		blocks = append(blocks, block)
+	}
+
+	*b = blocks
+	return nil
+}
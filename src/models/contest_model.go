@@ -0,0 +1,128 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Contest photo requirements
+const (
+	ContestPhotoNone     = "none"
+	ContestPhotoOptional = "optional"
+	ContestPhotoRequired = "required"
+)
+
+// Contest entry statuses
+const (
+	EntryValid        = "valid"
+	EntryDisqualified = "disqualified"
+)
+
+// Contest winner statuses
+const (
+	WinnerPending   = "pending" // waiting for the winner to claim
+	WinnerClaimed   = "claimed"
+	WinnerExpired   = "expired"   // claim deadline passed, slot went to an alternate
+	WinnerForfeited = "forfeited" // staff disqualified the winner, slot went to an alternate
+)
+
+// Contest is a giveaway or promo that listeners enter and staff draw winners from.
+// The draw seed is generated at creation and only its hash is public until the draw.
+type Contest struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Title       string             `bson:"title" json:"title"`
+	Description string             `bson:"description" json:"description"`
+	Prize       string             `bson:"prize" json:"prize"`
+	Kind        string             `bson:"kind" json:"kind"` // giveaway, photo_hunt, movie_passes ...
+	Image       string             `bson:"image,omitempty" json:"image,omitempty"`
+	Published   bool               `bson:"published" json:"published"`
+	Opens_at    primitive.DateTime `bson:"opens_at" json:"opens_at"`
+	Closes_at   primitive.DateTime `bson:"closes_at" json:"closes_at"`
+	Winners     int                `bson:"winners" json:"winners"`       // prizes to give away
+	Alternates  int                `bson:"alternates" json:"alternates"` // extra draws for unclaimed prizes
+	Claim_days  int                `bson:"claim_days" json:"claim_days"` // days a winner has to claim
+	Rules       ContestRules       `bson:"rules" json:"rules"`
+	Fields      []ContestField     `bson:"fields" json:"fields"`
+
+	Seed        string       `bson:"seed" json:"-"` // hex, revealed in Draw once drawn
+	Seed_hash   string       `bson:"seed_hash" json:"seed_hash"`
+	Entry_count int          `bson:"entry_count" json:"entry_count"`
+	Draw        *ContestDraw `bson:"draw,omitempty" json:"draw,omitempty"`
+
+	Created_by string             `bson:"created_by" json:"created_by"`
+	Created_at primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// ContestRules are the eligibility checks applied to every entry
+type ContestRules struct {
+	Min_age int      `bson:"min_age" json:"min_age"` // 0 = no age check
+	Regions []string `bson:"regions" json:"regions"` // empty = anywhere
+	Photo   string   `bson:"photo" json:"photo"`     // none | optional | required
+}
+
+// ContestField is an extra question on the entry form
+type ContestField struct {
+	Key        string   `bson:"key" json:"key"`
+	Label      string   `bson:"label" json:"label"`
+	Type       string   `bson:"type" json:"type"` // text | textarea | select
+	Options    []string `bson:"options,omitempty" json:"options,omitempty"`
+	Required   bool     `bson:"required" json:"required"`
+	Max_length int      `bson:"max_length,omitempty" json:"max_length,omitempty"`
+}
+
+// ContestDraw is the public record of a draw; anyone can recompute Order
+// from Seed and Entries_hash (see helpers.ContestDrawOrder).
+type ContestDraw struct {
+	Seed          string             `bson:"seed" json:"seed"`
+	Entries_hash  string             `bson:"entries_hash" json:"entries_hash"`
+	Entry_numbers []int              `bson:"entry_numbers" json:"entry_numbers"` // eligible entries, ascending
+	Order         []int              `bson:"order" json:"order"`                 // drawn entry numbers, winners then alternates
+	Next_position int                `bson:"next_position" json:"next_position"` // next Order index to promote
+	Drawn_by      string             `bson:"drawn_by" json:"drawn_by"`
+	Drawn_at      primitive.DateTime `bson:"drawn_at" json:"drawn_at"`
+}
+
+// ContestEntry is one listener's entry
+type ContestEntry struct {
+	ID                  primitive.ObjectID `bson:"_id" json:"id"`
+	Contest_id          primitive.ObjectID `bson:"contest_id" json:"contest_id"`
+	Entry_no            int                `bson:"entry_no" json:"entry_no"`
+	Name                string             `bson:"name" json:"name"`
+	Email               string             `bson:"email" json:"email"`
+	Phone               string             `bson:"phone" json:"phone"`
+	Birthdate           string             `bson:"birthdate,omitempty" json:"birthdate,omitempty"` // 2006-01-02
+	Region              string             `bson:"region,omitempty" json:"region,omitempty"`
+	Answers             map[string]string  `bson:"answers,omitempty" json:"answers,omitempty"`
+	Photo               string             `bson:"photo,omitempty" json:"photo,omitempty"` // media store filename
+	Status              string             `bson:"status" json:"status"`
+	Disqualified_reason string             `bson:"disqualified_reason,omitempty" json:"disqualified_reason,omitempty"`
+	Created_at          primitive.DateTime `bson:"created_at" json:"created_at"`
+}
+
+// ContestEntryInput is the public entry form (JSON, or multipart with a "photo" file)
+type ContestEntryInput struct {
+	Name      string            `json:"name" form:"name"`
+	Email     string            `json:"email" form:"email"`
+	Phone     string            `json:"phone" form:"phone"`
+	Birthdate string            `json:"birthdate" form:"birthdate"`
+	Region    string            `json:"region" form:"region"`
+	Answers   map[string]string `json:"answers"`
+}
+
+// ContestWinner is a drawn entry holding a prize slot
+type ContestWinner struct {
+	ID             primitive.ObjectID `bson:"_id" json:"id"`
+	Contest_id     primitive.ObjectID `bson:"contest_id" json:"contest_id"`
+	Entry_id       primitive.ObjectID `bson:"entry_id" json:"entry_id"`
+	Entry_no       int                `bson:"entry_no" json:"entry_no"`
+	Slot           int                `bson:"slot" json:"slot"`                   // prize number, 1..Winners
+	Draw_position  int                `bson:"draw_position" json:"draw_position"` // index in ContestDraw.Order
+	Name           string             `bson:"name" json:"name"`
+	Email          string             `bson:"email" json:"email,omitempty"`
+	Phone          string             `bson:"phone" json:"phone,omitempty"`
+	Status         string             `bson:"status" json:"status"`
+	Claim_deadline primitive.DateTime `bson:"claim_deadline" json:"claim_deadline"`
+	Claimed_at     primitive.DateTime `bson:"claimed_at,omitempty" json:"claimed_at,omitempty"`
+	Claim_details  map[string]string  `bson:"claim_details,omitempty" json:"claim_details,omitempty"`
+	Note           string             `bson:"note,omitempty" json:"note,omitempty"`
+	Created_at     primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at     primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
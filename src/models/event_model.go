@@ -0,0 +1,71 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Lineup entry kinds
+const (
+	LineupDJ     = "dj"     // station DJ, linked to the show they host
+	LineupArtist = "artist" // act, linked to their chart tracks by name
+	LineupGuest  = "guest"
+)
+
+// Event is a station event (Kostcon, Vinylthon, Tunog Kalye, Magic on the Go school
+// tours, parties). Times are stored in UTC and shown in Asia/Manila.
+type Event struct {
+	ID          primitive.ObjectID  `bson:"_id" json:"id"`
+	Slug        string              `bson:"slug" json:"slug"`
+	Title       string              `bson:"title" json:"title"`
+	Series      string              `bson:"series,omitempty" json:"series,omitempty"` // e.g. "Kostcon", "Magic on the Go"
+	Description string              `bson:"description" json:"description"`
+	Image       string              `bson:"image,omitempty" json:"image,omitempty"` // poster, media store filename
+	Venue       EventVenue          `bson:"venue" json:"venue"`
+	Starts_at   primitive.DateTime  `bson:"starts_at" json:"starts_at"`
+	Ends_at     primitive.DateTime  `bson:"ends_at" json:"ends_at"`
+	Lineup      []EventLineupEntry  `bson:"lineup" json:"lineup"`
+	Tickets     EventTickets        `bson:"tickets" json:"tickets"`
+	Sponsors    []EventSponsor      `bson:"sponsors" json:"sponsors"`
+	Album_id    *primitive.ObjectID `bson:"album_id,omitempty" json:"album_id,omitempty"` // photo gallery
+	Published   bool                `bson:"published" json:"published"`
+	Cancelled   bool                `bson:"cancelled" json:"cancelled"`
+	Sequence    int                 `bson:"sequence" json:"sequence"` // bumped on every edit, for calendar clients
+
+	Created_by string             `bson:"created_by" json:"created_by"`
+	Created_at primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// EventVenue is where an event happens
+type EventVenue struct {
+	Name    string   `bson:"name" json:"name"`
+	Address string   `bson:"address,omitempty" json:"address,omitempty"`
+	City    string   `bson:"city,omitempty" json:"city,omitempty"`
+	Map_url string   `bson:"map_url,omitempty" json:"map_url,omitempty"`
+	Lat     *float64 `bson:"lat,omitempty" json:"lat,omitempty"`
+	Lng     *float64 `bson:"lng,omitempty" json:"lng,omitempty"`
+}
+
+// EventLineupEntry is one act, in billing order
+type EventLineupEntry struct {
+	Name     string              `bson:"name" json:"name"`
+	Kind     string              `bson:"kind" json:"kind"` // dj | artist | guest
+	Show_id  *primitive.ObjectID `bson:"show_id,omitempty" json:"show_id,omitempty"`
+	Set_time primitive.DateTime  `bson:"set_time,omitempty" json:"set_time,omitempty"`
+}
+
+// EventTickets are in whole pesos; free events have no prices
+type EventTickets struct {
+	Free       bool               `bson:"free" json:"free"`
+	Price_min  int                `bson:"price_min,omitempty" json:"price_min,omitempty"`
+	Price_max  int                `bson:"price_max,omitempty" json:"price_max,omitempty"`
+	Url        string             `bson:"url,omitempty" json:"url,omitempty"`
+	On_sale_at primitive.DateTime `bson:"on_sale_at,omitempty" json:"on_sale_at,omitempty"`
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+}
+
+// EventSponsor is a partner shown on the event page
+type EventSponsor struct {
+	Name string `bson:"name" json:"name"`
+	Logo string `bson:"logo,omitempty" json:"logo,omitempty"` // media store filename
+	Url  string `bson:"url,omitempty" json:"url,omitempty"`
+	Tier string `bson:"tier,omitempty" json:"tier,omitempty"` // e.g. major, minor, media partner
+}
@@ -0,0 +1,116 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Form field types
+const (
+	FieldText        = "text"
+	FieldTextarea    = "textarea"
+	FieldEmail       = "email"
+	FieldPhone       = "phone" // Philippine mobile, stored as 09XXXXXXXXX
+	FieldNumber      = "number"
+	FieldDate        = "date" // 2006-01-02
+	FieldSelect      = "select"
+	FieldMultiselect = "multiselect"
+	FieldCheckbox    = "checkbox"
+	FieldFile        = "file"
+)
+
+// File kinds a file field can accept
+const (
+	FileImage = "image"
+	FileAudio = "audio" // MP3
+	FileVideo = "video" // MP4 / MOV
+	FilePDF   = "pdf"
+)
+
+// Form is a listener-facing form defined as data: its fields, who is
+// notified of submissions and the auto-reply sent to the submitter.
+type Form struct {
+	ID               primitive.ObjectID `bson:"_id" json:"id"`
+	Slug             string             `bson:"slug" json:"slug"`
+	Title            string             `bson:"title" json:"title"`
+	Description      string             `bson:"description,omitempty" json:"description,omitempty"`
+	Fields           []FormField        `bson:"fields" json:"fields"`
+	Unique_field     string             `bson:"unique_field,omitempty" json:"unique_field,omitempty"` // one submission per value, e.g. "email"
+	Notify           []string           `bson:"notify,omitempty" json:"notify,omitempty"`             // staff recipients
+	Auto_reply       *FormAutoReply     `bson:"auto_reply,omitempty" json:"auto_reply,omitempty"`
+	Success_message  string             `bson:"success_message,omitempty" json:"success_message,omitempty"`
+	Published        bool               `bson:"published" json:"published"`
+	Opens_at         primitive.DateTime `bson:"opens_at,omitempty" json:"opens_at,omitempty"`
+	Closes_at        primitive.DateTime `bson:"closes_at,omitempty" json:"closes_at,omitempty"`
+	Submission_count int                `bson:"submission_count" json:"submission_count"`
+	Created_by       string             `bson:"created_by" json:"created_by"`
+	Created_at       primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at       primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// FormField is one input and its validation rules
+type FormField struct {
+	Key         string   `bson:"key" json:"key"` // a-z, 0-9 and _, starts with a letter
+	Label       string   `bson:"label" json:"label"`
+	Type        string   `bson:"type" json:"type"`
+	Help        string   `bson:"help,omitempty" json:"help,omitempty"`
+	Required    bool     `bson:"required" json:"required"`
+	Min_length  int      `bson:"min_length,omitempty" json:"min_length,omitempty"`
+	Max_length  int      `bson:"max_length,omitempty" json:"max_length,omitempty"`
+	Min         *float64 `bson:"min,omitempty" json:"min,omitempty"` // number fields
+	Max         *float64 `bson:"max,omitempty" json:"max,omitempty"`
+	Pattern     string   `bson:"pattern,omitempty" json:"pattern,omitempty"` // regexp for text fields
+	Options     []string `bson:"options,omitempty" json:"options,omitempty"` // select / multiselect
+	Accept      []string `bson:"accept,omitempty" json:"accept,omitempty"`   // file kinds
+	Max_size_mb int      `bson:"max_size_mb,omitempty" json:"max_size_mb,omitempty"`
+}
+
+// FormAutoReply is emailed to the address in Email_field; {{key}} placeholders
+// in Subject and Body are replaced with the submitted values.
+type FormAutoReply struct {
+	Email_field string `bson:"email_field" json:"email_field"`
+	Subject     string `bson:"subject" json:"subject"`
+	Body        string `bson:"body" json:"body"` // HTML
+}
+
+// FormSubmission is one validated submission of a form
+type FormSubmission struct {
+	ID           primitive.ObjectID     `bson:"_id" json:"id"`
+	Form_id      primitive.ObjectID     `bson:"form_id" json:"form_id"`
+	Form_slug    string                 `bson:"form_slug" json:"form_slug"`
+	Values       map[string]interface{} `bson:"values" json:"values"`
+	Files        map[string]string      `bson:"files,omitempty" json:"files,omitempty"` // field key -> stored filename
+	Unique_value string                 `bson:"unique_value,omitempty" json:"-"`
+	IP_address   string                 `bson:"ip_address" json:"ip_address"`
+	Created_at   primitive.DateTime     `bson:"created_at" json:"created_at"`
+}
+
+// ShoutboxFormDefinition is RequestShoutbox and its auto-reply expressed as a form;
+// the staff recipients are filled in when it is seeded
+func ShoutboxFormDefinition() Form {
+	return Form{
+		Slug:        "shoutbox",
+		Title:       "Shoutbox Inquiry",
+		Description: "Request an on-air shoutout for your school or organization's event.",
+		Fields: []FormField{
+			{Key: "name", Label: "Name", Type: FieldText, Required: true, Max_length: 100},
+			{Key: "school_name", Label: "School Name", Type: FieldText, Required: true, Max_length: 200},
+			{Key: "email", Label: "Email", Type: FieldEmail, Required: true},
+			{Key: "position", Label: "Position", Type: FieldText, Required: true, Max_length: 100},
+			{Key: "contact", Label: "Contact Number", Type: FieldText, Required: true, Max_length: 11},
+			{Key: "school_contact", Label: "School Contact Number", Type: FieldText, Required: true, Max_length: 11},
+			{Key: "organization", Label: "Organization", Type: FieldText, Required: true, Max_length: 200},
+			{Key: "title", Label: "Event Title", Type: FieldText, Required: true, Max_length: 200},
+			{Key: "event_date", Label: "Event Date", Type: FieldText, Required: true, Max_length: 100},
+			{Key: "radio_spiel", Label: "Radio Spiel", Type: FieldTextarea, Required: true, Min_length: 100, Max_length: 300},
+		},
+		Auto_reply: &FormAutoReply{
+			Email_field: "email",
+			Subject:     "Shoutbox Inquiry - {{title}}",
+			Body: `<h3>Hello {{name}},</h3>
+<p>Thank you for your shoutbox request regarding "<b>{{title}}</b>". We have received your details and will get back to you soon.</p>
+<p><b>Event Date:</b> {{event_date}}</p>
+<p><b>Radio Spiel:</b> {{radio_spiel}}</p>
+<p>Best regards,<br>Magic 899 Team</p>`,
+		},
+		Success_message: "Auto-reply email sent successfully!",
+		Published:       true,
+	}
+}
@@ -12,6 +12,7 @@ type MagicVideos struct {
 	Thumbnail  string             `bson:"thumbnail" json:"thumbnail"`
 	Socials    []*string          `bson:"socials" json:"socials"`
 	Date       string             `bson:"date" json:"date"`
+	Duration   int                `bson:"duration,omitempty" json:"duration,omitempty"` // seconds, for uploaded videos
 	Created_at primitive.DateTime `bson:"created_at" json:"created_at"`
 	Updated_at primitive.DateTime `bson:"updated_at" json:"updated_at"`
 }
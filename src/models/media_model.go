@@ -0,0 +1,50 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// MediaAsset is one file in the media store (uploads/images) with its editorial
+// metadata and, as of the last library scan, every place that references it
+type MediaAsset struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	File_name   string             `bson:"file_name" json:"file_name"` // unique
+	Type        string             `bson:"type" json:"type"`           // MIME type from the magic bytes ("" when unrecognised)
+	Width       int                `bson:"width" json:"width"`
+	Height      int                `bson:"height" json:"height"`
+	Bytes       int64              `bson:"bytes" json:"bytes"`
+	Hash        string             `bson:"hash" json:"hash"`                                   // hex SHA-256 of the file
+	Fingerprint string             `bson:"fingerprint,omitempty" json:"fingerprint,omitempty"` // hex perceptual hash, "" when it cannot be decoded (AVIF)
+	Modified_at primitive.DateTime `bson:"modified_at" json:"modified_at"`                     // file mtime the hashes were taken from
+	Alt         string             `bson:"alt,omitempty" json:"alt,omitempty"`
+	Caption     string             `bson:"caption,omitempty" json:"caption,omitempty"`
+	Credit      string             `bson:"credit,omitempty" json:"credit,omitempty"`
+	Tags        []string           `bson:"tags" json:"tags"`
+	Usages      []MediaUsage       `bson:"usages" json:"usages"`
+	Usage_count int                `bson:"usage_count" json:"usage_count"`
+	Missing     bool               `bson:"missing,omitempty" json:"missing,omitempty"` // record kept for its metadata, file gone from disk
+	Scanned_at  primitive.DateTime `bson:"scanned_at" json:"scanned_at"`
+	Created_by  string             `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	Created_at  primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at  primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// MediaUsage is one reference to a media file from a content document
+type MediaUsage struct {
+	Collection  string             `bson:"collection" json:"collection"`
+	Document_id primitive.ObjectID `bson:"document_id" json:"document_id"`
+	Field       string             `bson:"field" json:"field"` // dotted path, e.g. "content.image"
+	Title       string             `bson:"title,omitempty" json:"title,omitempty"`
+}
+
+// MediaAssetInput is what editors can change on an asset
+type MediaAssetInput struct {
+	Alt     *string  `json:"alt"`
+	Caption *string  `json:"caption"`
+	Credit  *string  `json:"credit"`
+	Tags    []string `json:"tags"` // replaces the tags when not nil
+}
+
+// MediaDuplicateGroup is a set of files showing the same picture
+type MediaDuplicateGroup struct {
+	Exact  bool         `json:"exact"` // byte-identical, otherwise perceptually the same
+	Assets []MediaAsset `json:"assets"`
+}
@@ -5,7 +5,7 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 type Movies struct {
 	ID                      primitive.ObjectID `bson:"_id" json:"id"`
 	Title                   string             `json:"title" validate:"required,min=2,max=100"`
-	Content                 []string           `json:"content" validate:"required,min=2,max=100"`
+	Content                 ContentBlocks      `json:"content" validate:"required,min=2,max=100"`
 	Movie_Image             string             `json:"movie_image"`
 	Category                []string           `json:"category" validate:"required,min=2,max=100"`
 	Social_media            []string           `json:"social_media"`
@@ -18,7 +18,13 @@ type Movies struct {
 	Location_cinema         string             `json:"location_cinema" validate:"required,min=2,max=100"`
 	Advanced_screening_date string             `json:"advanced_screening_date" validate:"required,min=2,max=100"`
 	Screening_time          string             `json:"screening_time" validate:"required,min=2,max=100"`
-	Rating                  float64            `json:"rating"`
+	Release_at              primitive.DateTime `bson:"release_at,omitempty" json:"release_at,omitempty"`     // Release_date, typed (Asia/Manila)
+	Screening_at            primitive.DateTime `bson:"screening_at,omitempty" json:"screening_at,omitempty"` // Advanced_screening_date + Screening_time, typed
+	Screening_capacity      int                `json:"screening_capacity"`
+	Screening_seats_taken   int                `json:"screening_seats_taken"`
+	Rating                  float64            `json:"rating"` // average of approved listener reviews
+	Review_count            int                `json:"review_count"`
+	Rating_distribution     map[string]int     `json:"rating_distribution"` // "1".."5" stars -> approved reviews
 	Created_at              primitive.DateTime `json:"created_at"`
 	Updated_at              primitive.DateTime `json:"updated_at"`
 }
@@ -2,13 +2,17 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+// NewsCategories are the allowed values of News.Category
+var NewsCategories = []string{"News", "Sports", "celebrities", "Music", "Movies"}
+
 type News struct {
 	ID              primitive.ObjectID `bson:"_id" json:"id"`
 	Title           string             `json:"title" validate:"required,min=2,max=100"`
 	NormalizedTitle string             `bson:"normalized_title" json:"normalized_title"`
-	Content         []string           `json:"content" validate:"required,min=2,max=100"`
+	Content         ContentBlocks      `json:"content" validate:"required,min=2,max=100"`
 	News_Image      string             `json:"news_image"`
 	Category        string             `json:"category" validate:"required,min=2,max=100,eq=News|eq=Sports|eq=celebrities|eq=Music|eq=Movies"`
+	Tags            []string           `json:"tags"`
 	Social_media    []string           `json:"social_media"`
 	Writer          string             `json:"writer" validate:"required,min=2,max=100"`
 	Status          string             `json:"status" validate:"required,min=2,max=100,eq=pending|eq=approved|eq=rejected"`
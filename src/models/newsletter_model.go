@@ -0,0 +1,91 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Newsletter subscriber statuses
+const (
+	SubscriberPending      = "pending" // waiting for the confirmation link to be clicked
+	SubscriberActive       = "active"
+	SubscriberUnsubscribed = "unsubscribed"
+)
+
+// Newsletter topics; each is one section of the weekly digest
+const (
+	TopicChart      = "chart"
+	TopicNews       = "news"
+	TopicScreenings = "screenings"
+)
+
+// NewsletterTopics are the allowed values of NewsletterSubscriber.Topics
+var NewsletterTopics = []string{TopicChart, TopicNews, TopicScreenings}
+
+// Newsletter issue statuses
+const (
+	IssueSending    = "sending" // being fanned out to subscribers
+	IssueSent       = "sent"
+	IssueSuperseded = "superseded" // a newer issue started before this one finished
+)
+
+// NewsletterSubscriber is one email address on the weekly digest list
+type NewsletterSubscriber struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	Email           string             `bson:"email" json:"email"`
+	Name            string             `bson:"name,omitempty" json:"name,omitempty"`
+	Topics          []string           `bson:"topics" json:"topics"`
+	Status          string             `bson:"status" json:"status"`
+	Confirm_nonce   primitive.ObjectID `bson:"confirm_nonce,omitempty" json:"-"` // in the current confirmation link; its timestamp is when it was sent
+	Confirmed_at    primitive.DateTime `bson:"confirmed_at,omitempty" json:"confirmed_at,omitempty"`
+	Unsubscribed_at primitive.DateTime `bson:"unsubscribed_at,omitempty" json:"unsubscribed_at,omitempty"`
+	Last_issue_id   primitive.ObjectID `bson:"last_issue_id,omitempty" json:"last_issue_id,omitempty"` // newest digest queued for them
+	IP_address      string             `bson:"ip_address" json:"ip_address"`
+	Created_at      primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at      primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// NewsletterSubscribeInput is the public signup form
+type NewsletterSubscribeInput struct {
+	Email  string   `json:"email"`
+	Name   string   `json:"name"`
+	Topics []string `json:"topics"` // all topics when empty
+}
+
+// NewsletterIssue is one weekly digest, snapshotted when it was built
+type NewsletterIssue struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	Week            string             `bson:"week" json:"week"`   // ISO week, e.g. "2026-W42"; one issue per week
+	Label           string             `bson:"label" json:"label"` // e.g. "Oct 12 - 18", shown in the subject
+	News            []DigestNews       `bson:"news" json:"news"`
+	Chart           []DigestChartEntry `bson:"chart" json:"chart"`
+	Screenings      []DigestScreening  `bson:"screenings" json:"screenings"`
+	Status          string             `bson:"status" json:"status"`
+	Recipient_count int                `bson:"recipient_count" json:"recipient_count"`
+	Created_by      string             `bson:"created_by" json:"created_by"` // "scheduler" or a staff username
+	Created_at      primitive.DateTime `bson:"created_at" json:"created_at"`
+	Sent_at         primitive.DateTime `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	Lease_until     primitive.DateTime `bson:"lease_until,omitempty" json:"-"` // while an instance is sending it
+}
+
+// DigestNews is an approved article published during the digest's week
+type DigestNews struct {
+	Title    string `bson:"title" json:"title"`
+	Category string `bson:"category" json:"category"`
+	Image    string `bson:"image,omitempty" json:"image,omitempty"`
+	URL      string `bson:"url" json:"url"`
+}
+
+// DigestChartEntry is a chart position at the time the digest was built
+type DigestChartEntry struct {
+	Position int    `bson:"position" json:"position"`
+	Title    string `bson:"title" json:"title"`
+	Artist   string `bson:"artist" json:"artist"`
+	Votes    int    `bson:"votes" json:"votes"`
+	URL      string `bson:"url" json:"url"`
+}
+
+// DigestScreening is an upcoming advanced screening
+type DigestScreening struct {
+	Title  string             `bson:"title" json:"title"`
+	Cinema string             `bson:"cinema" json:"cinema"`
+	At     primitive.DateTime `bson:"at" json:"at"`
+	URL    string             `bson:"url" json:"url"`
+}
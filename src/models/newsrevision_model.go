@@ -0,0 +1,24 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// NewsRevision is a full snapshot of a News document taken on every write.
+// Revisions are append-only; restoring an old revision creates a new one.
+type NewsRevision struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	News_id       primitive.ObjectID `bson:"news_id" json:"news_id"`
+	Revision      int                `bson:"revision" json:"revision"`                               // 0 is the baseline of items older than the history
+	Action        string             `bson:"action" json:"action"`                                   // create | update | restore | baseline
+	Restored_from int                `bson:"restored_from,omitempty" json:"restored_from,omitempty"` // empty on a restore of the baseline
+	Author_id     primitive.ObjectID `bson:"author_id,omitempty" json:"author_id,omitempty"`
+	Author        string             `bson:"author" json:"author"`
+	Snapshot      News               `bson:"snapshot" json:"snapshot"`
+	Created_at    primitive.DateTime `bson:"created_at" json:"created_at"`
+}
+
+// NewsFieldChange is a single field-level difference between two revisions
+type NewsFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
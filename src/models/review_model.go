@@ -0,0 +1,56 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Review moderation statuses
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// MovieReview is a listener's star rating (1-5) and optional review of a movie.
+// One review per email per movie; only approved reviews are public and counted.
+type MovieReview struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	Movie_id        primitive.ObjectID `bson:"movie_id" json:"movie_id"`
+	Name            string             `bson:"name" json:"name"`
+	Email           string             `bson:"email" json:"email,omitempty"` // staff only; public responses use PublicMovieReview
+	Rating          int                `bson:"rating" json:"rating"`
+	Title           string             `bson:"title,omitempty" json:"title,omitempty"`
+	Body            string             `bson:"body,omitempty" json:"body,omitempty"`
+	Status          string             `bson:"status" json:"status"`
+	Moderation_note string             `bson:"moderation_note,omitempty" json:"moderation_note,omitempty"`
+	Moderated_by    string             `bson:"moderated_by,omitempty" json:"moderated_by,omitempty"`
+	Moderated_at    primitive.DateTime `bson:"moderated_at,omitempty" json:"moderated_at,omitempty"`
+	Created_at      primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at      primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// PublicMovieReview is what listeners see of a review: no email or moderation details
+type PublicMovieReview struct {
+	ID         primitive.ObjectID `json:"id"`
+	Movie_id   primitive.ObjectID `json:"movie_id"`
+	Name       string             `json:"name"`
+	Rating     int                `json:"rating"`
+	Title      string             `json:"title,omitempty"`
+	Body       string             `json:"body,omitempty"`
+	Status     string             `json:"status"`
+	Created_at primitive.DateTime `json:"created_at"`
+}
+
+// MovieReviewInput is the public review form
+type MovieReviewInput struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Email  string `json:"email" validate:"required,email"`
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title" validate:"max=120"`
+	Body   string `json:"body" validate:"max=2000"`
+}
+
+// RatingSummary is the aggregate of a movie's approved reviews
+type RatingSummary struct {
+	Rating              float64        `json:"rating"`
+	Review_count        int            `json:"review_count"`
+	Rating_distribution map[string]int `json:"rating_distribution"`
+}
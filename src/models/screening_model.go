@@ -0,0 +1,47 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Screening registration statuses
+const (
+	ScreeningConfirmed  = "confirmed"
+	ScreeningWaitlisted = "waitlisted"
+	ScreeningCancelled  = "cancelled"
+)
+
+// ScreeningRegistration is one listener's seat (or waitlist spot) at a movie's advanced screening.
+// Active registrations are unique per movie by email and by phone.
+type ScreeningRegistration struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	Movie_id      primitive.ObjectID `bson:"movie_id" json:"movie_id"`
+	Name          string             `bson:"name" json:"name"`
+	Email         string             `bson:"email" json:"email"`
+	Phone         string             `bson:"phone" json:"phone"`
+	Status        string             `bson:"status" json:"status"`
+	Active        bool               `bson:"active" json:"-"` // false once cancelled, frees the email/phone
+	Checked_in_at primitive.DateTime `bson:"checked_in_at,omitempty" json:"checked_in_at,omitempty"`
+	Checked_in_by string             `bson:"checked_in_by,omitempty" json:"checked_in_by,omitempty"`
+	Promoted_at   primitive.DateTime `bson:"promoted_at,omitempty" json:"promoted_at,omitempty"`
+	Cancelled_at  primitive.DateTime `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`
+	Created_at    primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at    primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// ScreeningRegistrationInput is the public registration form
+type ScreeningRegistrationInput struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,email"`
+	Phone string `json:"phone" validate:"required"`
+}
+
+// ScreeningAvailability is the public seat count of a screening
+type ScreeningAvailability struct {
+	Movie_id   primitive.ObjectID `json:"movie_id"`
+	Cinema     string             `json:"location_cinema"`
+	Date       string             `json:"advanced_screening_date"`
+	Time       string             `json:"screening_time"`
+	Capacity   int                `json:"capacity"`
+	Seats_left int                `json:"seats_left"`
+	Waitlisted int64              `json:"waitlisted"`
+	Open       bool               `json:"open"`
+}
@@ -2,6 +2,15 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+// Shoutbox booking statuses
+const (
+	ShoutboxReceived  = "received"
+	ShoutboxReviewing = "reviewing"
+	ShoutboxScheduled = "scheduled"
+	ShoutboxAired     = "aired"
+	ShoutboxDeclined  = "declined"
+)
+
 // RequestShoutbox is the model for storing shoutbox requests.
 type RequestShoutbox struct {
 	ID             primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
@@ -17,4 +26,29 @@ type RequestShoutbox struct {
 	Radio_spiel    string             `json:"radio_spiel" validate:"required,min=100,max=300"`
 	Created_at     primitive.DateTime `json:"created_at"`
 	Updated_at     primitive.DateTime `json:"updated_at"`
+
+	// Booking lifecycle, managed by staff
+	Status      string                 `json:"status" bson:"status,omitempty"`
+	Event_at    primitive.DateTime     `json:"event_at,omitempty" bson:"event_at,omitempty"` // Event_date parsed, Manila
+	Show_id     primitive.ObjectID     `json:"show_id,omitempty" bson:"show_id,omitempty"`
+	Show_name   string                 `json:"show_name,omitempty" bson:"show_name,omitempty"`
+	Air_at      primitive.DateTime     `json:"air_at,omitempty" bson:"air_at,omitempty"` // start of the show airing it is read on
+	Status_note string                 `json:"status_note,omitempty" bson:"status_note,omitempty"`
+	History     []ShoutboxStatusChange `json:"history,omitempty" bson:"history,omitempty"`
+}
+
+// ShoutboxStatusChange is one step of a booking's history
+type ShoutboxStatusChange struct {
+	Status string             `json:"status" bson:"status"`
+	Note   string             `json:"note,omitempty" bson:"note,omitempty"`
+	By     string             `json:"by" bson:"by"`
+	At     primitive.DateTime `json:"at" bson:"at"`
+}
+
+// ShoutboxDay is one day of the booking calendar
+type ShoutboxDay struct {
+	Date       string            `json:"date"` // 2006-01-02, Manila
+	Bookings   []RequestShoutbox `json:"bookings"`
+	Shows      map[string]int    `json:"shows"` // scheduled/aired bookings per show
+	Overbooked bool              `json:"overbooked"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import "encoding/xml"
+
+// ---------------------------
+// Sitemaps (sitemaps.org 0.9 + Google image extension)
+// ---------------------------
+
+type SitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	XMLNS    string         `xml:"xmlns,attr"`
+	Sitemaps []SitemapEntry `xml:"sitemap"`
+}
+
+type SitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type URLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	ImageNS string       `xml:"xmlns:image,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc        string         `xml:"loc"`
+	LastMod    string         `xml:"lastmod,omitempty"`
+	ChangeFreq string         `xml:"changefreq,omitempty"`
+	Priority   string         `xml:"priority,omitempty"`
+	Images     []SitemapImage `xml:"image:image,omitempty"`
+}
+
+type SitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+// ---------------------------
+// Link previews (OpenGraph / Twitter cards / JSON-LD)
+// ---------------------------
+
+// MetaTag is one <meta> tag; OpenGraph uses Property, Twitter uses Name
+type MetaTag struct {
+	Property string `json:"property,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Content  string `json:"content"`
+}
+
+type PageMeta struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Canonical   string        `json:"canonical"`
+	Image       string        `json:"image,omitempty"`
+	Tags        []MetaTag     `json:"tags"`
+	JSONLD      []interface{} `json:"json_ld"`
+}
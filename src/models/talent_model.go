@@ -0,0 +1,104 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Talent search application statuses
+const (
+	TalentDraft        = "draft"     // registered, media still being uploaded
+	TalentSubmitted    = "submitted" // complete and visible to judges
+	TalentDisqualified = "disqualified"
+)
+
+// Media an applicant uploads; covers are cover-1 .. cover-3
+const (
+	TalentPhoto      = "photo"
+	TalentVideo      = "video"
+	TalentCoverCount = 3
+)
+
+// TalentCriterion is one weighted line of the judging rubric
+type TalentCriterion struct {
+	Key    string  `json:"key"`
+	Label  string  `json:"label"`
+	Weight float64 `json:"weight"` // percent of the final score
+}
+
+// TalentCriteria is the Fresh Groove rubric; online_votes is computed from votes, the rest are judged 0-100
+var TalentCriteria = []TalentCriterion{
+	{Key: "vocals", Label: "Vocal Ability", Weight: 30},
+	{Key: "performance", Label: "Performance & Stage Presence", Weight: 25},
+	{Key: "interpretation", Label: "Song Interpretation", Weight: 20},
+	{Key: "originality", Label: "Originality", Weight: 15},
+	{Key: "online_votes", Label: "Online Voting", Weight: 10},
+}
+
+// TalentApplicant is a Fresh Groove talent search entry for one season (year)
+type TalentApplicant struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	Season        string             `bson:"season" json:"season"`
+	Name          string             `bson:"name" json:"name"`
+	Address       string             `bson:"address" json:"address,omitempty"`
+	Contact       string             `bson:"contact" json:"contact,omitempty"`
+	Email         string             `bson:"email" json:"email,omitempty"`
+	Birthdate     string             `bson:"birthdate" json:"birthdate,omitempty"` // 2006-01-02
+	School        string             `bson:"school,omitempty" json:"school,omitempty"`
+	Work          string             `bson:"work,omitempty" json:"work,omitempty"`
+	Photo         string             `bson:"photo,omitempty" json:"photo,omitempty"` // media store filename
+	Video         string             `bson:"video,omitempty" json:"video,omitempty"` // talent media filename
+	Video_seconds float64            `bson:"video_seconds,omitempty" json:"video_seconds,omitempty"`
+	Covers        []string           `bson:"covers" json:"covers,omitempty"` // talent media filenames, "" until uploaded
+	Status        string             `bson:"status" json:"status"`
+	Status_note   string             `bson:"status_note,omitempty" json:"status_note,omitempty"`
+	Votes         int                `bson:"votes" json:"votes"`
+	Submitted_at  primitive.DateTime `bson:"submitted_at,omitempty" json:"submitted_at,omitempty"`
+	Created_at    primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at    primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// TalentApplicantInput is the registration form
+type TalentApplicantInput struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Contact   string `json:"contact"`
+	Email     string `json:"email"`
+	Birthdate string `json:"birthdate"`
+	School    string `json:"school"`
+	Work      string `json:"work"`
+}
+
+// TalentScore is one judge's marks (0-100 per judged criterion) for an applicant
+type TalentScore struct {
+	ID             primitive.ObjectID `bson:"_id" json:"id"`
+	Applicant_id   primitive.ObjectID `bson:"applicant_id" json:"applicant_id"`
+	Season         string             `bson:"season" json:"season"`
+	Judge_id       primitive.ObjectID `bson:"judge_id" json:"judge_id"`
+	Judge          string             `bson:"judge" json:"judge"`
+	Vocals         float64            `bson:"vocals" json:"vocals"`
+	Performance    float64            `bson:"performance" json:"performance"`
+	Interpretation float64            `bson:"interpretation" json:"interpretation"`
+	Originality    float64            `bson:"originality" json:"originality"`
+	Comment        string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	Created_at     primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at     primitive.DateTime `bson:"updated_at" json:"updated_at"`
+}
+
+// TalentVote is one public vote; one per IP per applicant per day (Manila)
+type TalentVote struct {
+	Applicant_id primitive.ObjectID `bson:"applicant_id"`
+	IP_address   string             `bson:"ip_address"`
+	Date         string             `bson:"date"`
+	Created_at   primitive.DateTime `bson:"created_at"`
+}
+
+// TalentStanding is a leaderboard row; Criteria holds the 0-100 average per criterion
+type TalentStanding struct {
+	Rank         int                `json:"rank"`
+	Applicant_id primitive.ObjectID `json:"applicant_id"`
+	Name         string             `json:"name"`
+	Photo        string             `json:"photo,omitempty"`
+	Judges       int                `json:"judges"`
+	Votes        int                `json:"votes"`
+	Criteria     map[string]float64 `json:"criteria,omitempty"`
+	Total        float64            `json:"total"`
+	Unjudged     bool               `json:"unjudged,omitempty"` // no judge scores yet: not ranked
+}
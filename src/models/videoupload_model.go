@@ -0,0 +1,34 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Video upload states
+const (
+	VideoUploadReceiving  = "receiving"  // chunks are still coming in
+	VideoUploadProcessing = "processing" // every byte is in, being checked and stored
+	VideoUploadComplete   = "complete"   // stored and registered as a MagicVideos entry
+	VideoUploadFailed     = "failed"
+)
+
+// VideoUpload is a resumable (tus) upload of a show recording. The bytes are
+// staged on the local disk until Offset reaches Length.
+type VideoUpload struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	Length    int64              `bson:"length" json:"length"`
+	Offset    int64              `bson:"offset" json:"offset"`
+	Metadata  map[string]string  `bson:"metadata" json:"metadata"` // from Upload-Metadata: filename, title, show_name, ...
+	Status    string             `bson:"status" json:"status"`
+	Error     string             `bson:"error,omitempty" json:"error,omitempty"`
+	Staged_on string             `bson:"staged_on,omitempty" json:"staged_on,omitempty"` // host whose disk holds the bytes
+
+	// set once the upload is complete
+	File_name string              `bson:"file_name,omitempty" json:"file_name,omitempty"` // in the videos storage area
+	Sha256    string              `bson:"sha256,omitempty" json:"sha256,omitempty"`
+	Duration  int                 `bson:"duration,omitempty" json:"duration,omitempty"` // seconds
+	Video_id  *primitive.ObjectID `bson:"video_id,omitempty" json:"video_id,omitempty"`
+
+	Created_by string             `bson:"created_by" json:"created_by"`
+	Created_at primitive.DateTime `bson:"created_at" json:"created_at"`
+	Updated_at primitive.DateTime `bson:"updated_at" json:"updated_at"`
+	Expires_at primitive.DateTime `bson:"expires_at" json:"expires_at"` // unfinished uploads are removed after this
+}
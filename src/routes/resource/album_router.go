@@ -0,0 +1,28 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func AlbumRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/albums")
+
+	// Staff only (registered first so "drafts" is not taken for a slug)
+	api.Get("/drafts", auth, staff, controllers.GetAlbumDrafts)
+	api.Post("/", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CreateAlbum)
+	api.Put("/:slug", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateAlbum)
+	api.Delete("/:slug", auth, staff, middlewares.CSRFTokenMiddleware, controllers.DeleteAlbum)
+	api.Post("/:slug/import", auth, staff, middlewares.CSRFTokenMiddleware, controllers.ImportAlbumImages)
+
+	api.Get("/", controllers.GetAlbums)
+	api.Get("/:slug", controllers.GetAlbum)
+}
+
+// AlbumDownloadRouter serves album ZIPs on the app root so downloads can be plain links
+func AlbumDownloadRouter(app fiber.Router) {
+	app.Get("/albums/:slug.zip", controllers.DownloadAlbum)
+}
@@ -0,0 +1,29 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func ContestRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/contests")
+
+	api.Get("/", controllers.GetContests)
+	api.Get("/:id", controllers.GetContest)
+	api.Post("/:id/entries", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.EnterContest)
+	api.Get("/:id/draw", controllers.GetContestDraw)
+	api.Get("/:id/claim", controllers.GetContestClaim)
+	api.Post("/:id/claim", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.ClaimContestPrize)
+
+	// Staff only
+	api.Post("/", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CreateContest)
+	api.Put("/:id", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateContest)
+	api.Get("/:id/entries", auth, staff, controllers.GetContestEntries)
+	api.Patch("/:id/entries/:entryId", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateContestEntry)
+	api.Post("/:id/draw", auth, staff, middlewares.CSRFTokenMiddleware, controllers.DrawContest)
+	api.Get("/:id/winners", auth, staff, controllers.GetContestWinners)
+	api.Post("/:id/winners/:winnerId/forfeit", auth, staff, middlewares.CSRFTokenMiddleware, controllers.ForfeitContestWinner)
+}
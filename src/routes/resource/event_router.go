@@ -0,0 +1,30 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func EventRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/events")
+
+	api.Get("/", controllers.GetEvents)
+	api.Get("/upcoming", controllers.GetUpcomingEvents)
+	api.Get("/past", controllers.GetPastEvents)
+
+	// Staff only (registered before /:slug so "drafts" is not taken for a slug)
+	api.Get("/drafts", auth, staff, controllers.GetEventDrafts)
+	api.Post("/", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CreateEvent)
+	api.Put("/:slug", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateEvent)
+	api.Delete("/:slug", auth, staff, middlewares.CSRFTokenMiddleware, controllers.DeleteEvent)
+
+	api.Get("/:slug", controllers.GetEvent)
+}
+
+// EventCalendarRouter serves .ics files on the app root so calendar apps can fetch them
+func EventCalendarRouter(app fiber.Router) {
+	app.Get("/events/:slug.ics", controllers.GetEventCalendar)
+}
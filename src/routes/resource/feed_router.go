@@ -0,0 +1,22 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// FeedRouter is mounted on the app root, outside /api: feed readers and
+// aggregators cannot do the Referer + RSP token handshake.
+func FeedRouter(app fiber.Router) {
+	api := app.Group("/feeds")
+	api.Get("/news.xml", controllers.GetNewsRSS)
+	api.Get("/news.atom", controllers.GetNewsAtom)
+	api.Get("/news.json", controllers.GetNewsJSONFeed)
+	api.Get("/news/:category.xml", controllers.GetNewsRSS)
+	api.Get("/news/:category.atom", controllers.GetNewsAtom)
+	api.Get("/news/:category.json", controllers.GetNewsJSONFeed)
+
+	// Enclosure images referenced by the feeds
+	app.Get("/media/images/:filename", controllers.GetPublicImageHandler)
+}
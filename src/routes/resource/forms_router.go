@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func FormsRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/forms")
+
+	api.Get("/:slug", controllers.GetForm)
+	api.Post("/:slug/submissions", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.SubmitForm)
+
+	// Staff only
+	api.Get("/", auth, staff, controllers.GetForms)
+	api.Post("/", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CreateForm)
+	api.Put("/:slug", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateForm)
+	api.Get("/:slug/submissions", auth, staff, controllers.GetFormSubmissions)
+	api.Get("/:slug/submissions/export", auth, staff, controllers.ExportFormSubmissions)
+	api.Get("/:slug/files/:file", auth, staff, controllers.GetFormFile)
+}
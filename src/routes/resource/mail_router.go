@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func MailOutboxRouter(router fiber.Router) {
+	api := router.Group("/mail-outbox", middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin"))
+	api.Get("/", controllers.GetMailOutbox)
+	api.Post("/:id/retry", middlewares.CSRFTokenMiddleware, controllers.RetryMail)
+}
+
+func MailTemplateRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/mail-templates", auth, staff)
+	api.Get("/", controllers.GetMailTemplates)
+	api.Get("/:name", controllers.GetMailTemplate)
+	api.Post("/:name", middlewares.CSRFTokenMiddleware, controllers.CreateMailTemplateVersion)
+	api.Post("/:name/preview", middlewares.CSRFTokenMiddleware, controllers.PreviewMailTemplate)
+	api.Post("/:name/activate", middlewares.RoleFilterMiddleware("admin"), middlewares.CSRFTokenMiddleware, controllers.ActivateMailTemplateVersion)
+}
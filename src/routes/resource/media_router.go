@@ -0,0 +1,22 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// MediaLibraryRouter - staff only
+func MediaLibraryRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/media", auth, staff)
+
+	api.Get("/", controllers.GetMediaAssets)
+	api.Get("/duplicates", controllers.GetMediaDuplicates)
+	api.Get("/orphans", controllers.GetMediaOrphans)
+	api.Post("/scan", middlewares.CSRFTokenMiddleware, controllers.ScanMediaLibrary)
+	api.Get("/:id", controllers.GetMediaAsset)
+	api.Put("/:id", middlewares.CSRFTokenMiddleware, controllers.UpdateMediaAsset)
+	api.Delete("/:id", middlewares.CSRFTokenMiddleware, controllers.DeleteMediaAsset)
+}
@@ -2,6 +2,7 @@ package resources
 
 import (
 	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -9,6 +10,13 @@ import (
 func MoviesRouter(router fiber.Router) {
 	api := router.Group("/movies")
 	api.Get("/", controllers.GetMovies)
+	api.Get("/now-showing", controllers.GetNowShowingMovies)
+	api.Get("/coming-soon", controllers.GetComingSoonMovies)
+	api.Get("/past-screenings", controllers.GetPastScreeningMovies)
 	api.Get("/:id", controllers.GetMovie)
 	api.Get("/latest/now", controllers.GetLatestMovie)
+
+	// Staff only: re-type every movie's dates with ?all=true
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api.Post("/dates/backfill", auth, staff, middlewares.CSRFTokenMiddleware, controllers.BackfillMovieDatesHandler)
 }
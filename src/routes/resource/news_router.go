@@ -3,6 +3,7 @@ package resources
 import (
 	"magic-server-2026/src/controllers"
 	"magic-server-2026/src/handlers"
+	"magic-server-2026/src/middlewares"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -10,4 +11,13 @@ import (
 func NewsRouterResource(app fiber.Router) {
 	app.Get("/news", controllers.GetNews, handlers.RotateRSToken)
 	app.Get("/news/:id", controllers.GetNewsItem, handlers.RotateRSToken)
+
+	// Staff only: writes are versioned in news_revisions
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	app.Post("/news", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CreateNewsItem)
+	app.Put("/news/:id", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateNewsItem)
+	app.Get("/news/:id/revisions", auth, staff, controllers.GetNewsRevisions)
+	app.Get("/news/:id/revisions/diff", auth, staff, controllers.DiffNewsRevisions)
+	app.Get("/news/:id/revisions/:revision", auth, staff, controllers.GetNewsRevision)
+	app.Post("/news/:id/revisions/:revision/restore", auth, staff, middlewares.CSRFTokenMiddleware, controllers.RestoreNewsRevision)
 }
@@ -0,0 +1,32 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func NewsletterRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/newsletter")
+
+	api.Post("/subscribe", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.SubscribeNewsletter)
+	api.Post("/confirm", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.ConfirmNewsletter)
+	api.Get("/preferences/:token", controllers.GetNewsletterPreferences)
+	api.Put("/preferences/:token", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.UpdateNewsletterPreferences)
+	api.Post("/preferences/:token/unsubscribe", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.UnsubscribeNewsletter)
+
+	// Staff only
+	api.Get("/subscribers", auth, staff, controllers.GetNewsletterSubscribers)
+	api.Get("/issues", auth, staff, controllers.GetNewsletterIssues)
+	api.Get("/issues/preview", auth, staff, controllers.PreviewNewsletterIssue)
+	api.Post("/issues/send", auth, middlewares.RoleFilterMiddleware("admin"), middlewares.CSRFTokenMiddleware, controllers.SendNewsletterIssue)
+}
+
+// NewsletterUnsubscribeRouter serves the List-Unsubscribe URL on the app root: mail providers
+// POST to it directly (RFC 8058), without our Referer or resource token
+func NewsletterUnsubscribeRouter(app fiber.Router) {
+	app.Get("/newsletter/unsubscribe/:token", controllers.OneClickUnsubscribeLanding)
+	app.Post("/newsletter/unsubscribe/:token", controllers.OneClickUnsubscribeNewsletter)
+}
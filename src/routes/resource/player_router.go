@@ -6,7 +6,13 @@ import (
 	"github.com/gofiber/fiber/v3"
 )
 
+// GetPlayerRouter serves videos; every request needs a signed URL (see utils/signed_utils.go)
 func GetPlayerRouter(router fiber.Router) {
 	api := router.Group("/player")
 	api.Get("/:filename", controllers.GetVideoPlayer)
 }
+
+// PlayerURLRouter issues signed player URLs behind the RSP token
+func PlayerURLRouter(router fiber.Router) {
+	router.Get("/player/:filename/url", controllers.GetVideoPlayerSignedURL)
+}
@@ -0,0 +1,12 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RelatedRouter must be registered after the typed routers so their own routes match first
+func RelatedRouter(router fiber.Router) {
+	router.Get("/:type/:id/related", controllers.GetRelatedContent)
+}
@@ -0,0 +1,20 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func MovieReviewRouter(router fiber.Router) {
+	router.Get("/movies/:id/reviews", controllers.GetMovieReviews)
+	router.Post("/movies/:id/reviews", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.CreateMovieReview)
+
+	// Staff only: moderation
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/movie-reviews", auth, staff)
+	api.Get("/", controllers.GetReviewQueue)
+	api.Patch("/:reviewId", middlewares.CSRFTokenMiddleware, controllers.ModerateMovieReview)
+	api.Delete("/:reviewId", middlewares.CSRFTokenMiddleware, controllers.DeleteMovieReview)
+}
@@ -0,0 +1,27 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func ScreeningRouter(router fiber.Router) {
+	api := router.Group("/movies/:id/screening")
+	api.Get("/", controllers.GetScreening)
+	api.Post("/registrations", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.RegisterForScreening)
+	api.Post("/cancel", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.CancelScreeningRegistration)
+
+	// Staff only
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api.Put("/", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateScreeningCapacity)
+	api.Get("/registrations", auth, staff, controllers.GetScreeningRegistrations)
+	api.Delete("/registrations/:registrationId", auth, staff, middlewares.CSRFTokenMiddleware, controllers.DeleteScreeningRegistration)
+	api.Post("/check-in", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CheckInScreening)
+}
+
+// ScreeningPassRouter serves pass QR codes on the app root so they can be linked from emails
+func ScreeningPassRouter(app fiber.Router) {
+	app.Get("/screening-passes/:token.png", controllers.GetScreeningPass)
+}
@@ -0,0 +1,12 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func SearchRouter(router fiber.Router) {
+	api := router.Group("/search")
+	api.Get("/", controllers.SearchContent)
+}
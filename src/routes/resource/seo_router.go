@@ -0,0 +1,16 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// SEORouter is mounted on the app root: crawlers and the frontend's bot
+// prerender proxy cannot do the Referer + RSP token handshake.
+func SEORouter(app fiber.Router) {
+	app.Get("/robots.txt", controllers.GetRobots)
+	app.Get("/sitemap.xml", controllers.GetSitemapIndex)
+	app.Get("/sitemaps/:section.xml", controllers.GetSectionSitemap)
+	app.Get("/meta", controllers.GetPageMeta)
+}
@@ -0,0 +1,13 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ShareCardRouter is mounted on the app root so social crawlers can fetch og:image cards
+func ShareCardRouter(app fiber.Router) {
+	api := app.Group("/share")
+	api.Get("/:type/:id.:format", controllers.GetShareCard)
+}
@@ -11,4 +11,12 @@ func ShoutboxMailerRouter(router fiber.Router) {
 
 	api := router.Group("/shoutbox-mailer")
 	api.Post("/", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.SendAutoReplyShoutboxMailer)
+
+	// Staff only: booking lifecycle
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	bookings := router.Group("/shoutbox-bookings", auth, staff)
+	bookings.Get("/", controllers.GetShoutboxBookings)
+	bookings.Get("/calendar", controllers.GetShoutboxCalendar)
+	bookings.Get("/:id", controllers.GetShoutboxBooking)
+	bookings.Patch("/:id/status", middlewares.CSRFTokenMiddleware, controllers.UpdateShoutboxStatus)
 }
@@ -0,0 +1,36 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TalentRouter(router fiber.Router) {
+	auth := middlewares.AuthMiddleware
+	staff := middlewares.RoleFilterMiddleware("admin", "editor")
+	judges := middlewares.RoleFilterMiddleware("admin", "judge")
+	api := router.Group("/talent")
+
+	// Applicants
+	api.Post("/applications", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.RegisterTalentApplicant)
+	api.Get("/applications/:token", controllers.GetTalentApplication)
+	api.Put("/applications/:token/media/:slot", middlewares.CSRFTokenMiddleware, controllers.UploadTalentMedia)
+	api.Post("/applications/:token/submit", middlewares.CSRFTokenMiddleware, controllers.SubmitTalentApplication)
+
+	// Public voting
+	api.Get("/contestants", controllers.GetTalentContestants)
+	api.Post("/contestants/:id/vote", middlewares.RateLimiterMiddleware(), middlewares.CSRFTokenMiddleware, controllers.VoteTalentContestant)
+
+	// Judges
+	api.Get("/judging", auth, judges, controllers.GetJudgingQueue)
+	api.Get("/judging/:id", auth, judges, controllers.GetJudgingApplicant)
+	api.Put("/judging/:id/score", auth, judges, middlewares.CSRFTokenMiddleware, controllers.ScoreTalentApplicant)
+	api.Get("/media/:file", auth, middlewares.RoleFilterMiddleware("admin", "editor", "judge"), controllers.GetTalentMedia)
+	api.Get("/leaderboard", auth, middlewares.RoleFilterMiddleware("admin", "editor", "judge"), controllers.GetTalentLeaderboard)
+
+	// Staff only
+	api.Get("/applicants", auth, staff, controllers.GetTalentApplicants)
+	api.Patch("/applicants/:id", auth, staff, middlewares.CSRFTokenMiddleware, controllers.UpdateTalentApplicant)
+}
@@ -0,0 +1,27 @@
+package resources
+
+import (
+	"magic-server-2026/src/controllers"
+	"magic-server-2026/src/middlewares"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// VideoUploadRouter serves tus resumable uploads of show recordings (see videoupload.controller.go)
+func VideoUploadRouter(router fiber.Router) {
+	auth, staff := middlewares.AuthMiddleware, middlewares.RoleFilterMiddleware("admin", "editor")
+	api := router.Group("/uploads/videos")
+
+	// tus discovery, no credentials needed
+	api.Options("/", controllers.VideoUploadOptions)
+	api.Options("/:id", controllers.VideoUploadOptions)
+
+	api.Post("/", auth, staff, middlewares.CSRFTokenMiddleware, controllers.CreateVideoUpload)
+	api.Head("/:id", auth, staff, controllers.HeadVideoUpload)
+	api.Patch("/:id", auth, staff, middlewares.CSRFTokenMiddleware, controllers.PatchVideoUpload)
+	api.Delete("/:id", auth, staff, middlewares.CSRFTokenMiddleware, controllers.DeleteVideoUpload)
+	api.Post("/:id", auth, staff, middlewares.CSRFTokenMiddleware, controllers.VideoUploadMethodOverride)
+
+	api.Get("/", auth, staff, controllers.GetVideoUploads)
+	api.Get("/:id", auth, staff, controllers.GetVideoUpload)
+}
@@ -28,6 +28,21 @@ func SetupRouter(app *fiber.App) {
 		resources.MoviesRouter,
 		resources.RequestedSongRouter,
 		resources.ShoutboxMailerRouter,
+		resources.SearchRouter,
+		resources.RelatedRouter,
+		resources.ScreeningRouter,
+		resources.MovieReviewRouter,
+		resources.ContestRouter,
+		resources.TalentRouter,
+		resources.FormsRouter,
+		resources.MailOutboxRouter,
+		resources.MailTemplateRouter,
+		resources.NewsletterRouter,
+		resources.MediaLibraryRouter,
+		resources.AlbumRouter,
+		resources.EventRouter,
+		resources.PlayerURLRouter,
+		resources.VideoUploadRouter,
 	}
 
 	for _, r := range resourceRoutes {
@@ -38,4 +53,13 @@ func SetupRouter(app *fiber.App) {
 	resources.ImageRouter(api)
 	resources.GetPlayerRouter(api)
 	tokens.SetupTokenRouter(api)
+
+	// Public, cacheable endpoints (no Referer / RSP checks)
+	resources.FeedRouter(app)
+	resources.SEORouter(app)
+	resources.ShareCardRouter(app)
+	resources.ScreeningPassRouter(app)
+	resources.NewsletterUnsubscribeRouter(app)
+	resources.AlbumDownloadRouter(app)
+	resources.EventCalendarRouter(app)
 }
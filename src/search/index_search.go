@@ -0,0 +1,322 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Content types that can be searched
+const (
+	TypeNews  = "news"
+	TypeShow  = "show"
+	TypeMovie = "movie"
+	TypeMusic = "music"
+	TypeVideo = "video"
+)
+
+var Types = []string{TypeNews, TypeShow, TypeMovie, TypeMusic, TypeVideo}
+
+// field weights: a hit in the title counts more than one in the body
+const (
+	titleWeight = 3.0
+	extraWeight = 2.0
+	bodyWeight  = 1.0
+
+	prefixFactor = 0.7
+	typo1Factor  = 0.5
+	typo2Factor  = 0.3
+
+	snippetLength = 180
+)
+
+// Document is one searchable item of any content type
+type Document struct {
+	Type  string
+	ID    string
+	Title string
+	Body  string
+	Extra string   // categories, artists, hosts, cast...
+	Keys  []string // categories, tags, artists, hosts... compared whole for related content
+	URL   string
+	Image string
+	Date  time.Time
+}
+
+// Result is a ranked hit with <mark> highlighted title and snippet (HTML escaped)
+type Result struct {
+	Type           string    `json:"type"`
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	TitleHighlight string    `json:"title_highlight"`
+	Snippet        string    `json:"snippet"`
+	URL            string    `json:"url,omitempty"`
+	Image          string    `json:"image,omitempty"`
+	Date           time.Time `json:"date,omitempty"`
+	Score          float64   `json:"score"`
+}
+
+// Response is the outcome of a query, with per-type facet counts
+type Response struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Facets  map[string]int `json:"facets"`
+	Results []Result       `json:"results"`
+}
+
+type posting struct {
+	doc    int
+	weight float64
+}
+
+// Index is an immutable inverted index; rebuilds swap in a new one
+type Index struct {
+	docs     []Document
+	postings map[string][]posting
+	vocab    []string // sorted, for prefix and typo expansion
+	built    time.Time
+
+	byID    map[string]int        // "type:id" -> doc
+	vectors []map[string]float64  // unit length tf-idf vector per doc
+	keys    []map[string]struct{} // normalized Keys per doc
+	keyDF   map[string]int        // how many docs share each key
+	related sync.Map              // "type:id" -> []Related, dropped with the index
+}
+
+// Build creates an index over docs
+func Build(docs []Document) *Index {
+	idx := &Index{
+		docs:     docs,
+		postings: make(map[string][]posting),
+		built:    time.Now(),
+		byID:     make(map[string]int, len(docs)),
+		vectors:  make([]map[string]float64, len(docs)),
+		keys:     make([]map[string]struct{}, len(docs)),
+		keyDF:    make(map[string]int),
+	}
+
+	for i, doc := range docs {
+		idx.byID[doc.Type+":"+doc.ID] = i
+		idx.keys[i] = make(map[string]struct{}, len(doc.Keys))
+		for _, k := range doc.Keys {
+			if k = normalizeKey(k); k != "" {
+				idx.keys[i][k] = struct{}{}
+			}
+		}
+		for k := range idx.keys[i] {
+			idx.keyDF[k]++
+		}
+
+		weights := make(map[string]float64)
+		for _, t := range tokenize(doc.Title) {
+			weights[t.Term] += titleWeight
+		}
+		for _, t := range tokenize(doc.Extra) {
+			weights[t.Term] += extraWeight
+		}
+		for _, t := range tokenize(doc.Body) {
+			weights[t.Term] += bodyWeight
+		}
+		idx.vectors[i] = make(map[string]float64, len(weights))
+		for term, w := range weights {
+			// dampen long bodies repeating the same word
+			w = 1 + math.Log(w)
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, weight: w})
+			idx.vectors[i][term] = w
+		}
+	}
+
+	total := float64(len(docs))
+	for _, vector := range idx.vectors {
+		var norm float64
+		for term, w := range vector {
+			w *= math.Log(1 + total/float64(len(idx.postings[term])))
+			vector[term] = w
+			norm += w * w
+		}
+		if norm == 0 {
+			continue
+		}
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+	}
+
+	idx.vocab = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.vocab = append(idx.vocab, term)
+	}
+	sort.Strings(idx.vocab)
+
+	return idx
+}
+
+// expand maps a query term to the indexed terms it should match and their score factor
+func (idx *Index) expand(term string, allowPrefix bool) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, ok := idx.postings[term]; ok {
+		matches[term] = 1
+	}
+
+	if allowPrefix && len([]rune(term)) >= 3 {
+		for i := sort.SearchStrings(idx.vocab, term); i < len(idx.vocab) && strings.HasPrefix(idx.vocab[i], term); i++ {
+			if _, ok := matches[idx.vocab[i]]; !ok {
+				matches[idx.vocab[i]] = prefixFactor
+			}
+		}
+	}
+
+	if typos := maxTypos(term); typos > 0 {
+		for _, candidate := range idx.vocab {
+			if _, ok := matches[candidate]; ok {
+				continue
+			}
+			distance := levenshtein(term, candidate, typos)
+			switch {
+			case distance > typos:
+				continue
+			case distance == 1:
+				matches[candidate] = typo1Factor
+			case distance == 2:
+				matches[candidate] = typo2Factor
+			}
+		}
+	}
+
+	return matches
+}
+
+// Query ranks documents for q. types restricts results (facets are always computed on all types).
+func (idx *Index) Query(q string, types map[string]bool, limit, offset int) Response {
+	resp := Response{Query: q, Facets: make(map[string]int), Results: []Result{}}
+	for _, t := range Types {
+		resp.Facets[t] = 0
+	}
+
+	queryTokens := tokenize(q)
+	if len(queryTokens) == 0 || len(idx.docs) == 0 {
+		return resp
+	}
+
+	scores := make(map[int]float64)
+	coverage := make(map[int]int)
+	highlights := make(map[int]map[string]bool)
+	total := float64(len(idx.docs))
+
+	for i, qt := range queryTokens {
+		// the last word may still be being typed, so it also matches as a prefix
+		expansions := idx.expand(qt.Term, i == len(queryTokens)-1)
+
+		best := make(map[int]float64)
+		for term, factor := range expansions {
+			list := idx.postings[term]
+			idf := math.Log(1 + total/float64(len(list)))
+			for _, p := range list {
+				score := idf * p.weight * factor
+				if score > best[p.doc] {
+					best[p.doc] = score
+				}
+				if highlights[p.doc] == nil {
+					highlights[p.doc] = make(map[string]bool)
+				}
+				highlights[p.doc][term] = true
+			}
+		}
+
+		for doc, score := range best {
+			scores[doc] += score
+			coverage[doc]++
+		}
+	}
+
+	hits := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		d := idx.docs[doc]
+		resp.Facets[d.Type]++
+		if len(types) > 0 && !types[d.Type] {
+			continue
+		}
+
+		// documents matching every query word rank above partial matches
+		ratio := float64(coverage[doc]) / float64(len(queryTokens))
+		hits = append(hits, Result{
+			Type:  d.Type,
+			ID:    d.ID,
+			Title: d.Title,
+			URL:   d.URL,
+			Image: d.Image,
+			Date:  d.Date,
+			Score: math.Round(score*ratio*ratio*1000) / 1000,
+		})
+		hits[len(hits)-1].TitleHighlight = highlight(d.Title, highlights[doc], 0)
+		hits[len(hits)-1].Snippet = highlight(d.Body, highlights[doc], snippetLength)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Date.After(hits[j].Date)
+	})
+
+	resp.Total = len(hits)
+	if offset < len(hits) {
+		end := min(offset+limit, len(hits))
+		resp.Results = hits[offset:end]
+	}
+	return resp
+}
+
+// highlight HTML-escapes text and wraps matched terms in <mark>.
+// With window > 0 it returns an excerpt around the first match.
+func highlight(text string, terms map[string]bool, window int) string {
+	tokens := tokenize(text)
+	var marked []token
+	for _, t := range tokens {
+		if terms[t.Term] {
+			marked = append(marked, t)
+		}
+	}
+
+	start, end := 0, len(text)
+	if window > 0 && len(text) > window {
+		if len(marked) > 0 {
+			start = max(0, marked[0].Start-window/3)
+		}
+		end = min(len(text), start+window)
+		// keep whole words at the edges
+		if start > 0 {
+			if i := strings.IndexByte(text[start:end], ' '); i >= 0 {
+				start += i + 1
+			}
+		}
+		if end < len(text) {
+			if i := strings.LastIndexByte(text[start:end], ' '); i > 0 {
+				end = start + i
+			}
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, t := range marked {
+		if t.Start < start || t.End > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.Start]))
+		b.WriteString("<mark>" + html.EscapeString(text[t.Start:t.End]) + "</mark>")
+		pos = t.End
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
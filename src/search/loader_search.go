@@ -0,0 +1,206 @@
+package search
+
+import (
+	"context"
+	"log"
+	"magic-server-2026/src/db"
+	"magic-server-2026/src/helpers"
+	"magic-server-2026/src/models"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// writes arriving within this window trigger a single rebuild
+	refreshDebounce = 2 * time.Second
+	// catch edits made directly in the database
+	refreshInterval = 15 * time.Minute
+)
+
+var (
+	current   atomic.Pointer[Index]
+	refreshCh = make(chan struct{}, 1)
+)
+
+// Init builds the index once and keeps it fresh in the background
+func Init() {
+	current.Store(Build(nil))
+	rebuild()
+
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-refreshCh:
+				time.Sleep(refreshDebounce)
+				// drop refreshes requested while we were waiting
+				select {
+				case <-refreshCh:
+				default:
+				}
+				rebuild()
+			case <-ticker.C:
+				rebuild()
+			}
+		}
+	}()
+}
+
+// Refresh schedules a rebuild; controllers call it after content writes
+func Refresh() {
+	select {
+	case refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// Search queries the current index
+func Search(q string, types map[string]bool, limit, offset int) Response {
+	idx := current.Load()
+	if idx == nil {
+		return Response{Query: q, Facets: map[string]int{}, Results: []Result{}}
+	}
+	return idx.Query(q, types, limit, offset)
+}
+
+// FindRelated returns recommendations for an indexed item from the current index
+func FindRelated(docType, id string) ([]Related, bool) {
+	idx := current.Load()
+	if idx == nil {
+		return nil, false
+	}
+	return idx.Related(docType, id)
+}
+
+func rebuild() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	docs, err := loadDocuments(ctx)
+	if err != nil {
+		// keep serving the previous index
+		log.Println("[SEARCH] rebuild failed:", err)
+		return
+	}
+
+	current.Store(Build(docs))
+	log.Printf("[SEARCH] indexed %d documents in %s", len(docs), time.Since(start).Round(time.Millisecond))
+}
+
+func loadDocuments(ctx context.Context) ([]Document, error) {
+	var docs []Document
+	database := "magic899_db"
+
+	var news []models.News
+	if err := findAll(ctx, database, "news", bson.M{"status": "approved"}, &news); err != nil {
+		return nil, err
+	}
+	for _, n := range news {
+		docs = append(docs, Document{
+			Type:  TypeNews,
+			ID:    n.ID.Hex(),
+			Title: n.Title,
+			Body:  helpers.ContentPlainText(n.Content),
+			Extra: n.Category + " " + strings.Join(n.Tags, " ") + " " + n.Writer,
+			Keys:  append([]string{n.Category}, n.Tags...),
+			URL:   helpers.NewsURL(n),
+			Image: helpers.PublicImageURL(n.News_Image),
+			Date:  n.Created_at.Time(),
+		})
+	}
+
+	var shows []models.Shows
+	if err := findAll(ctx, database, "shows", bson.M{}, &shows); err != nil {
+		return nil, err
+	}
+	for _, s := range shows {
+		docs = append(docs, Document{
+			Type:  TypeShow,
+			ID:    s.ID.Hex(),
+			Title: deref(s.Show_name),
+			Body:  helpers.ContentPlainText(s.Show_desc),
+			Extra: deref(s.Show_host) + " " + deref(s.Show_day),
+			Keys:  []string{deref(s.Show_name), deref(s.Show_host)},
+			URL:   helpers.ShowURL(s),
+			Image: helpers.PublicImageURL(s.Show_Image),
+			Date:  s.Created_at.Time(),
+		})
+	}
+
+	var movies []models.Movies
+	if err := findAll(ctx, database, "movies", bson.M{}, &movies); err != nil {
+		return nil, err
+	}
+	for _, m := range movies {
+		docs = append(docs, Document{
+			Type:  TypeMovie,
+			ID:    m.ID.Hex(),
+			Title: m.Title,
+			Body:  helpers.ContentPlainText(m.Content),
+			Extra: strings.Join(m.Category, " ") + " " + strings.Join(m.Cast, " ") + " " + m.Directed_by,
+			Keys:  append(append(append([]string{}, m.Category...), m.Cast...), m.Directed_by),
+			URL:   helpers.MovieURL(m),
+			Image: helpers.PublicImageURL(m.Movie_Image),
+			Date:  m.Created_at.Time(),
+		})
+	}
+
+	var music []models.Music
+	if err := findAll(ctx, database, "music", bson.M{}, &music); err != nil {
+		return nil, err
+	}
+	for _, m := range music {
+		docs = append(docs, Document{
+			Type:  TypeMusic,
+			ID:    m.ID.Hex(),
+			Title: m.Title,
+			Body:  m.Album,
+			Extra: strings.Join(m.Artist, " "),
+			Keys:  m.Artist,
+			URL:   helpers.MusicURL(m),
+			Image: m.Music_image,
+			Date:  m.Created_at.Time(),
+		})
+	}
+
+	var videos []models.MagicVideos
+	if err := findAll(ctx, database, "magic_videos", bson.M{}, &videos); err != nil {
+		return nil, err
+	}
+	for _, v := range videos {
+		docs = append(docs, Document{
+			Type:  TypeVideo,
+			ID:    v.ID.Hex(),
+			Title: v.Title,
+			Body:  strings.Join(v.Desc, " "),
+			Extra: v.Show_name,
+			Keys:  []string{v.Show_name},
+			URL:   helpers.VideoURL(v),
+			Image: v.Thumbnail,
+			Date:  v.Created_at.Time(),
+		})
+	}
+
+	return docs, nil
+}
+
+func findAll(ctx context.Context, database, collection string, filter bson.M, out interface{}) error {
+	cursor, err := db.GetCollection(database, collection).Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, out)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Related content mixes structured overlap (shared categories, tags,
+// artists, hosts...) with text similarity of title and body.
+const (
+	keyWeight  = 1.0
+	textWeight = 2.0
+
+	// below this an item is not worth suggesting
+	minRelatedScore = 0.05
+
+	// enough for any page; callers slice down to their limit
+	MaxRelated = 20
+)
+
+// RelatedTypes are the content types that get and appear in recommendations
+var RelatedTypes = []string{TypeNews, TypeShow, TypeMovie, TypeVideo}
+
+// Related is a recommended item with the keys it shares with the source
+type Related struct {
+	Type   string    `json:"type"`
+	ID     string    `json:"id"`
+	Title  string    `json:"title"`
+	URL    string    `json:"url,omitempty"`
+	Image  string    `json:"image,omitempty"`
+	Date   time.Time `json:"date,omitempty"`
+	Shared []string  `json:"shared"`
+	Score  float64   `json:"score"`
+}
+
+// normalizeKey folds case, diacritics and spacing so "Ben&Ben" and "ben & ben" are one key
+func normalizeKey(key string) string {
+	return strings.Join(tokenizeAll(key), " ")
+}
+
+// tokenizeAll is tokenize without dropping stop words, for exact phrase keys
+func tokenizeAll(text string) []string {
+	return strings.FieldsFunc(normalizeTerm(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+func isRelatedType(t string) bool {
+	for _, known := range RelatedTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Related returns up to MaxRelated items similar to the given one.
+// ok is false when the item is not indexed (unknown, deleted or unpublished).
+func (idx *Index) Related(docType, id string) (items []Related, ok bool) {
+	source, ok := idx.byID[docType+":"+id]
+	if !ok || !isRelatedType(docType) {
+		return nil, false
+	}
+
+	cacheKey := docType + ":" + id
+	if cached, found := idx.related.Load(cacheKey); found {
+		return cached.([]Related), true
+	}
+
+	total := float64(len(idx.docs))
+	sourceVector := idx.vectors[source]
+
+	items = []Related{}
+	for i, d := range idx.docs {
+		if i == source || !isRelatedType(d.Type) {
+			continue
+		}
+
+		// rare keys (a specific host) say more than common ones (a big category)
+		var keyScore float64
+		var shared []string
+		for k := range idx.keys[source] {
+			if _, ok := idx.keys[i][k]; ok {
+				keyScore += math.Log(1 + total/float64(idx.keyDF[k]))
+				shared = append(shared, k)
+			}
+		}
+
+		var cosine float64
+		vector := idx.vectors[i]
+		if len(vector) < len(sourceVector) {
+			for term, w := range vector {
+				cosine += w * sourceVector[term]
+			}
+		} else {
+			for term, w := range sourceVector {
+				cosine += w * vector[term]
+			}
+		}
+
+		score := keyWeight*math.Log(1+keyScore) + textWeight*cosine
+		if score < minRelatedScore {
+			continue
+		}
+
+		sort.Strings(shared)
+		if shared == nil {
+			shared = []string{}
+		}
+		items = append(items, Related{
+			Type:   d.Type,
+			ID:     d.ID,
+			Title:  d.Title,
+			URL:    d.URL,
+			Image:  d.Image,
+			Date:   d.Date,
+			Shared: shared,
+			Score:  math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Date.After(items[j].Date)
+	})
+	if len(items) > MaxRelated {
+		items = items[:MaxRelated]
+	}
+
+	idx.related.Store(cacheKey, items)
+	return items, true
+}
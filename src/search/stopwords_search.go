@@ -0,0 +1,37 @@
+package search
+
+// stopWords are skipped when indexing and querying. The site is bilingual,
+// so common Filipino (Tagalog) function words are listed next to English ones.
+var stopWords = func() map[string]struct{} {
+	words := []string{
+		// English
+		"a", "about", "after", "all", "also", "an", "and", "any", "are", "as", "at",
+		"be", "been", "before", "but", "by", "can", "could", "did", "do", "does",
+		"for", "from", "had", "has", "have", "he", "her", "his", "how", "i", "if",
+		"in", "into", "is", "it", "its", "just", "me", "more", "my", "no", "not",
+		"of", "on", "one", "or", "our", "out", "over", "she", "so", "some", "than",
+		"that", "the", "their", "them", "then", "there", "these", "they", "this",
+		"to", "up", "us", "was", "we", "were", "what", "when", "which", "who",
+		"will", "with", "would", "you", "your",
+
+		// Filipino
+		"ako", "akin", "amin", "ang", "ano", "at", "ay", "ba", "bakit", "daw",
+		"din", "dito", "doon", "ganito", "ganoon", "hindi", "iba", "ikaw", "ito",
+		"iyan", "iyo", "iyon", "ka", "kami", "kanila", "kanya", "kay", "kayo",
+		"ko", "kung", "lahat", "lamang", "lang", "man", "mga", "mo", "na", "naman",
+		"nang", "ng", "nga", "ni", "nila", "nito", "niya", "niyo", "pa", "para",
+		"pero", "po", "raw", "rin", "sa", "sila", "si", "siya", "tayo", "tungkol",
+		"upang", "yan", "yun", "yung",
+	}
+
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}()
+
+func isStopWord(term string) bool {
+	_, ok := stopWords[term]
+	return ok
+}
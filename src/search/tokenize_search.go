@@ -0,0 +1,107 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// token is a normalized term and its byte span in the original text
+type token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// normalizeTerm lowercases and strips diacritics so "Niño" matches "nino"
+func normalizeTerm(word string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(word)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// tokenize splits text on anything that is not a letter or digit.
+// Stop words and single characters are dropped.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		term := normalizeTerm(text[start:end])
+		if len([]rune(term)) > 1 && !isStopWord(term) {
+			tokens = append(tokens, token{Term: term, Start: start, End: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// levenshtein returns the edit distance between a and b, or max+1 once it is exceeded
+func levenshtein(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// maxTypos is how many edits a query term tolerates, based on its length
+func maxTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
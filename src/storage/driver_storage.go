@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"magic-server-2026/src/utils"
+	"mime"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+   Object storage
+   -----------------------------------
+   Uploaded files are stored under "<area>/<name>" keys, never as paths,
+   so the same code runs against the local disk or an S3-compatible bucket
+   (AWS S3, MinIO, Cloudflare R2, DigitalOcean Spaces).
+
+   STORAGE_DRIVER       local | s3 (default local)
+   STORAGE_LOCAL_DIR    root of the local driver (./src/uploads)
+   S3_ENDPOINT          e.g. http://localhost:9000 for a local MinIO,
+                        https://s3.ap-southeast-1.amazonaws.com for AWS
+   S3_REGION            (us-east-1), S3_BUCKET
+   S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY
+   S3_PATH_STYLE        true (default, what MinIO expects) puts the bucket
+                        in the path; false uses bucket.host
+   STORAGE_PRESIGN      true: downloads redirect to short-lived presigned
+                        URLs so the bytes never pass through this server
+                        (s3 only; the local driver always serves itself)
+   STORAGE_PRESIGN_TTL  lifetime of presigned URLs (15m)
+   -----------------------------------
+   Derived files (image variants, share cards) are caches and stay on
+   the local disk of each instance.
+   -----------------------------------
+*/
+
+// Areas are the top-level key prefixes
+const (
+	Images = "images" // public media store
+	Videos = "videos" // player videos
+	Talent = "talent" // talent search videos and covers, judges only
+	Forms  = "forms"  // files submitted through forms, staff only
+)
+
+var (
+	ErrNotFound     = errors.New("object not found")
+	ErrInvalidKey   = errors.New("invalid object key")
+	ErrNotSupported = errors.New("not supported by this storage driver")
+)
+
+// Object describes a stored file
+type Object struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+	ETag        string
+}
+
+// Name is the last segment of the key
+func (o Object) Name() string { return path.Base(o.Key) }
+
+// Driver stores objects by key
+type Driver interface {
+	Name() string
+	// Put writes the object, replacing any object with the same key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object; the caller closes the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	// GetRange opens length bytes of the object from offset (length < 0: to the end)
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, Object, error)
+	Stat(ctx context.Context, key string) (Object, error)
+	// Delete removes the object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns every object whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// PresignGet returns a URL anyone can GET the object from until ttl passes
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Key joins an area and a file name, rejecting names that could escape the area
+func Key(area, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, "/\\\x00") || strings.HasPrefix(name, ".") {
+		return "", ErrInvalidKey
+	}
+	return area + "/" + name, nil
+}
+
+// validKey checks a full key: relative, no empty, hidden or dot-dot segments
+func validKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// ContentType guesses the MIME type from the key's extension
+func ContentType(key string) string {
+	if kind := mime.TypeByExtension(strings.ToLower(path.Ext(key))); kind != "" {
+		return kind
+	}
+	return "application/octet-stream"
+}
+
+type config struct {
+	driver     Driver
+	presign    bool
+	presignTTL time.Duration
+}
+
+var (
+	loadOnce sync.Once
+	current  config
+)
+
+func load() config {
+	loadOnce.Do(func() {
+		kind := strings.ToLower(utils.GetEnv("STORAGE_DRIVER"))
+		switch kind {
+		case "s3":
+			driver, err := newS3Driver(s3Config{
+				endpoint:  utils.GetEnv("S3_ENDPOINT"),
+				region:    utils.GetEnv("S3_REGION"),
+				bucket:    utils.GetEnv("S3_BUCKET"),
+				accessKey: utils.GetEnv("S3_ACCESS_KEY_ID"),
+				secretKey: utils.GetEnv("S3_SECRET_ACCESS_KEY"),
+				pathStyle: utils.GetEnv("S3_PATH_STYLE") != "false",
+			})
+			if err != nil {
+				log.Fatal("[STORAGE] ", err)
+			}
+			current.driver = driver
+		default:
+			if kind != "" && kind != "local" {
+				log.Printf("[STORAGE] unknown STORAGE_DRIVER %q, using local", kind)
+			}
+			dir := utils.GetEnv("STORAGE_LOCAL_DIR")
+			if dir == "" {
+				dir = "./src/uploads"
+			}
+			current.driver = NewLocalDriver(dir)
+		}
+
+		current.presign = utils.GetEnv("STORAGE_PRESIGN") == "true" && current.driver.Name() != "local"
+		current.presignTTL = 15 * time.Minute
+		if ttl, err := time.ParseDuration(utils.GetEnv("STORAGE_PRESIGN_TTL")); err == nil && ttl > 0 {
+			current.presignTTL = min(ttl, 7*24*time.Hour) // SigV4 maximum
+		}
+		log.Printf("[STORAGE] using the %s driver (presigned downloads: %t)", current.driver.Name(), current.presign)
+	})
+	return current
+}
+
+// Default is the configured driver
+func Default() Driver { return load().driver }
+
+// MaxPutSize is the largest object the configured driver can store in one Put (0: no limit)
+func MaxPutSize() int64 {
+	if limited, ok := Default().(interface{ MaxPutSize() int64 }); ok {
+		return limited.MaxPutSize()
+	}
+	return 0
+}
+
+// PresignedURL returns a presigned download URL when presigned downloads are on ("" otherwise)
+func PresignedURL(ctx context.Context, key string) (string, error) {
+	cfg := load()
+	if !cfg.presign {
+		return "", nil
+	}
+	return cfg.driver.PresignGet(ctx, key, cfg.presignTTL)
+}
+
+// ReadAll reads a whole object, refusing objects over maxBytes
+func ReadAll(ctx context.Context, key string, maxBytes int64) ([]byte, Object, error) {
+	r, obj, err := Default().Get(ctx, key)
+	if err != nil {
+		return nil, obj, err
+	}
+	defer r.Close()
+	if maxBytes > 0 && obj.Size > maxBytes {
+		return nil, obj, errors.New("object is too large")
+	}
+	if maxBytes > 0 {
+		r = io.NopCloser(io.LimitReader(r, maxBytes+1))
+	}
+	data, err := io.ReadAll(r)
+	if err == nil && maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, obj, errors.New("object is too large")
+	}
+	return data, obj, err
+}
+
+// PutBytes stores data under key
+func PutBytes(ctx context.Context, key string, data []byte, contentType string) error {
+	return Default().Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
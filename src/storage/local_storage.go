@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalDriver keeps objects as files under a root directory
+type LocalDriver struct {
+	root string
+}
+
+func NewLocalDriver(root string) *LocalDriver {
+	return &LocalDriver{root: root}
+}
+
+func (d *LocalDriver) Name() string { return "local" }
+
+// Path is the file behind a key, for handlers that can send files directly
+func (d *LocalDriver) Path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(d.root, filepath.FromSlash(key)), nil
+}
+
+func (d *LocalDriver) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	path, err := d.Path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// write then rename, so readers never see half a file
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *LocalDriver) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	return d.GetRange(ctx, key, 0, -1)
+}
+
+func (d *LocalDriver) GetRange(_ context.Context, key string, offset, length int64) (io.ReadCloser, Object, error) {
+	path, err := d.Path(key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Object{}, localError(err)
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, Object{}, ErrNotFound
+	}
+	obj := localObject(key, info)
+	if offset == 0 && length < 0 {
+		return file, obj, nil
+	}
+	if length < 0 || offset+length > info.Size() {
+		length = info.Size() - offset
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(file, offset, max(length, 0)), file}, obj, nil
+}
+
+func (d *LocalDriver) Stat(_ context.Context, key string) (Object, error) {
+	path, err := d.Path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Object{}, localError(err)
+	}
+	if info.IsDir() {
+		return Object{}, ErrNotFound
+	}
+	return localObject(key, info), nil
+}
+
+func (d *LocalDriver) Delete(_ context.Context, key string) error {
+	path, err := d.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (d *LocalDriver) List(_ context.Context, prefix string) ([]Object, error) {
+	// walk the directory holding the prefix and filter by the full key
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = prefix[:i]
+	}
+	start := filepath.Join(d.root, filepath.FromSlash(dir))
+
+	var objects []Object
+	err := filepath.WalkDir(start, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && path != start {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil // removed while walking
+		}
+		objects = append(objects, localObject(key, info))
+		return nil
+	})
+	return objects, err
+}
+
+func (d *LocalDriver) PresignGet(context.Context, string, time.Duration) (string, error) {
+	return "", ErrNotSupported
+}
+
+func localObject(key string, info fs.FileInfo) Object {
+	return Object{Key: key, Size: info.Size(), ModTime: info.ModTime(), ContentType: ContentType(key)}
+}
+
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
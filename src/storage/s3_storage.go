@@ -0,0 +1,380 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3MaxPutSize is the largest object a single PUT can store (S3 needs multipart uploads above it)
+const s3MaxPutSize = 5 << 30
+
+type s3Config struct {
+	endpoint             string
+	region               string
+	bucket               string
+	accessKey, secretKey string
+	pathStyle            bool
+}
+
+// S3Driver talks to an S3-compatible API with Signature Version 4
+type S3Driver struct {
+	cfg    s3Config
+	base   *url.URL
+	client *http.Client
+}
+
+func newS3Driver(cfg s3Config) (*S3Driver, error) {
+	if cfg.endpoint == "" || cfg.bucket == "" || cfg.accessKey == "" || cfg.secretKey == "" {
+		return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required")
+	}
+	if cfg.region == "" {
+		cfg.region = "us-east-1"
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.endpoint, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT %q", cfg.endpoint)
+	}
+	return &S3Driver{
+		cfg:  cfg,
+		base: base,
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+			MaxIdleConnsPerHost:   16,
+		}},
+	}, nil
+}
+
+func (d *S3Driver) Name() string { return "s3" }
+
+// MaxPutSize is the largest object Put accepts
+func (d *S3Driver) MaxPutSize() int64 { return s3MaxPutSize }
+
+// objectURL addresses a key (or the bucket itself when key is "")
+func (d *S3Driver) objectURL(key string) *url.URL {
+	u := *d.base
+	p := "/" + key
+	if d.cfg.pathStyle {
+		p = "/" + d.cfg.bucket + p
+		if key == "" {
+			p = "/" + d.cfg.bucket
+		}
+	} else {
+		u.Host = d.cfg.bucket + "." + u.Host
+	}
+	u.Path, u.RawPath = p, s3Escape(p, false)
+	return &u
+}
+
+func (d *S3Driver) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if size < 0 {
+		return errors.New("s3: object size is required")
+	}
+	if size > s3MaxPutSize {
+		return fmt.Errorf("s3: objects over %d GB cannot be stored in one PUT", s3MaxPutSize>>30)
+	}
+	body := io.ReadCloser(http.NoBody)
+	if size > 0 {
+		body = io.NopCloser(r)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType == "" {
+		contentType = ContentType(key)
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := d.do(req, "UNSIGNED-PAYLOAD")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error(res, key)
+	}
+	return nil
+}
+
+func (d *S3Driver) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	return d.GetRange(ctx, key, 0, -1)
+}
+
+func (d *S3Driver) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, Object, error) {
+	if err := validKey(key); err != nil {
+		return nil, Object{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	switch {
+	case length > 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := d.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		defer res.Body.Close()
+		return nil, Object{}, s3Error(res, key)
+	}
+	return res.Body, s3Object(key, res.Header), nil
+}
+
+func (d *S3Driver) Stat(ctx context.Context, key string) (Object, error) {
+	if err := validKey(key); err != nil {
+		return Object{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, d.objectURL(key).String(), nil)
+	if err != nil {
+		return Object{}, err
+	}
+	res, err := d.do(req, emptyPayloadHash)
+	if err != nil {
+		return Object{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Object{}, s3Error(res, key)
+	}
+	return s3Object(key, res.Header), nil
+}
+
+func (d *S3Driver) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, d.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := d.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return s3Error(res, key)
+	}
+	return nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (d *S3Driver) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		u := d.objectURL("")
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = s3Query(query)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := d.do(req, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			err := s3Error(res, prefix)
+			res.Body.Close()
+			return nil, err
+		}
+		var page s3ListResult
+		err = xml.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list: %w", err)
+		}
+		for _, item := range page.Contents {
+			modified, _ := time.Parse(time.RFC3339, item.LastModified)
+			objects = append(objects, Object{
+				Key: item.Key, Size: item.Size, ModTime: modified,
+				ContentType: ContentType(item.Key), ETag: strings.Trim(item.ETag, `"`),
+			})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// PresignGet signs a GET in the query string (only the host header is signed)
+func (d *S3Driver) PresignGet(_ context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return d.presign(key, ttl, time.Now().UTC()), nil
+}
+
+func (d *S3Driver) presign(key string, ttl time.Duration, now time.Time) string {
+	amzDate, scope := now.Format("20060102T150405Z"), d.scope(now)
+	u := d.objectURL(key)
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {d.cfg.accessKey + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	canonicalQuery := s3Query(query)
+	canonical := strings.Join([]string{
+		http.MethodGet, u.EscapedPath(), canonicalQuery,
+		"host:" + u.Host + "\n", "host", "UNSIGNED-PAYLOAD",
+	}, "\n")
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + d.signature(now, amzDate, scope, canonical)
+	return u.String()
+}
+
+// do signs the request and sends it
+func (d *S3Driver) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	d.sign(req, payloadHash, time.Now().UTC())
+	return d.client.Do(req)
+}
+
+// sign sets the Authorization header (host, date and payload hash are signed)
+func (d *S3Driver) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate, scope := now.Format("20060102T150405Z"), d.scope(now)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery,
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders, payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+d.cfg.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+d.signature(now, amzDate, scope, canonical))
+}
+
+func (d *S3Driver) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + d.cfg.region + "/s3/aws4_request"
+}
+
+// signature derives the day's signing key and signs the canonical request
+func (d *S3Driver) signature(now time.Time, amzDate, scope, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+	key := hmacSHA256([]byte("AWS4"+d.cfg.secretKey), now.Format("20060102"))
+	for _, part := range []string{d.cfg.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Escape percent-encodes everything but RFC 3986 unreserved characters (and "/" unless slash is set)
+func s3Escape(s string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !slash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// s3Query is the canonical query string: sorted, every key and value strictly encoded
+func s3Query(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3Object(key string, h http.Header) Object {
+	obj := Object{Key: key, ContentType: h.Get("Content-Type"), ETag: strings.Trim(h.Get("ETag"), `"`)}
+	obj.Size, _ = strconv.ParseInt(h.Get("Content-Length"), 10, 64)
+	// a ranged response carries the full size after the slash: "bytes 0-99/1234"
+	if cr := h.Get("Content-Range"); cr != "" {
+		if i := strings.LastIndex(cr, "/"); i >= 0 {
+			if total, err := strconv.ParseInt(cr[i+1:], 10, 64); err == nil {
+				obj.Size = total
+			}
+		}
+	}
+	obj.ModTime, _ = http.ParseTime(h.Get("Last-Modified"))
+	if obj.ContentType == "" {
+		obj.ContentType = ContentType(key)
+	}
+	return obj
+}
+
+// s3Error turns a failed response into ErrNotFound or a descriptive error
+func s3Error(res *http.Response, key string) error {
+	if res.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		if body.Code == "NoSuchKey" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("s3 %s %s: %s: %s", res.Request.Method, key, body.Code, body.Message)
+	}
+	return fmt.Errorf("s3 %s %s: %s", res.Request.Method, key, res.Status)
+}
@@ -19,3 +19,8 @@ func LoadEnv() error {
 func GetEnv(key string) string {
 	return os.Getenv(key)
 }
+
+// IsProduction reports whether ENV is "production"
+func IsProduction() bool {
+	return os.Getenv("ENV") == "production"
+}
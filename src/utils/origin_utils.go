@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// SiteOrigin returns the public frontend origin (no trailing slash) used in absolute links
+func SiteOrigin() string {
+	origin := GetEnv("FRONTEND_ORIGIN")
+	if origin == "" {
+		if os.Getenv("ENV") == "production" {
+			origin = "https://demo-test.magic899.com"
+		} else {
+			origin = "http://localhost:5179"
+		}
+	}
+	return strings.TrimRight(origin, "/")
+}
+
+// ServerOrigin returns the public API origin (no trailing slash) used in absolute asset links
+func ServerOrigin() string {
+	origin := GetEnv("SERVER_ORIGIN")
+	if origin == "" {
+		port := GetEnv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		origin = "http://localhost:" + port
+	}
+	return strings.TrimRight(origin, "/")
+}
@@ -2,38 +2,105 @@ package utils
 
 import (
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"net/url"
+	"sync"
 	"time"
 )
 
-var signedURLSecret = []byte("YOUR_SECRET_KEY") // Change to a strong secret in env
+/*
+   Signed player URLs
+   -----------------------------------
+   /api/player/:filename?expires=<unix>&sig=<hmac>[&s=1]
+   sig is an HMAC-SHA256 of the filename, the expiry and, when s=1, the
+   listener's session_id cookie, so a link only plays that file, until
+   it expires, and (if bound) only in the browser it was issued to.
+   Dropping s=1 changes what is signed, so a binding cannot be removed.
 
-// GenerateSignedURL generates a temporary signed URL for a filename
-func GenerateSignedURL(filename string, duration time.Duration) (string, error) {
-	expires := time.Now().Add(duration).Unix()
-	data := fmt.Sprintf("%s:%d", filename, expires)
+   PLAYER_URL_SECRET  signing secret; required when ENV=production. In
+                      development an unset secret is replaced by a random
+                      one, so issued URLs stop working on restart
+   PLAYER_URL_TTL     lifetime of issued URLs (30m); players ask for a new
+                      one before expires_at so seeking keeps working
+   -----------------------------------
+*/
 
-	h := hmac.New(sha256.New, signedURLSecret)
-	h.Write([]byte(data))
-	sig := hex.EncodeToString(h.Sum(nil))
+const defaultSignedURLTTL = 30 * time.Minute
 
-	// URL-encode filename
-	escapedFilename := url.PathEscape(filename)
+var (
+	signedURLOnce   sync.Once
+	signedURLSecret []byte
+	signedURLTTL    time.Duration
+)
+
+func signedURLConfig() ([]byte, time.Duration) {
+	signedURLOnce.Do(func() {
+		signedURLSecret = []byte(GetEnv("PLAYER_URL_SECRET"))
+		if len(signedURLSecret) == 0 {
+			if IsProduction() {
+				log.Fatal("[PLAYER] PLAYER_URL_SECRET must be set in production")
+			}
+			log.Println("[PLAYER] PLAYER_URL_SECRET is not set, signing with a random per-process secret")
+			signedURLSecret = make([]byte, 32)
+			if _, err := rand.Read(signedURLSecret); err != nil {
+				log.Fatal("[PLAYER] could not generate a signing secret:", err)
+			}
+		}
+		signedURLTTL = defaultSignedURLTTL
+		if ttl, err := time.ParseDuration(GetEnv("PLAYER_URL_TTL")); err == nil && ttl > 0 {
+			signedURLTTL = ttl
+		}
+	})
+	return signedURLSecret, signedURLTTL
+}
 
-	// Return query string URL: /player/:filename?expires=...&sig=...
-	return fmt.Sprintf("/api/v1/player/%s?expires=%d&sig=%s", escapedFilename, expires, sig), nil
+// CheckSignedURLSecret loads the player URL settings at startup, stopping the
+// server when PLAYER_URL_SECRET is missing in production
+func CheckSignedURLSecret() { signedURLConfig() }
+
+// SignedURLTTL is how long issued player URLs stay valid
+func SignedURLTTL() time.Duration {
+	_, ttl := signedURLConfig()
+	return ttl
 }
 
-// ValidateSignedURL validates the HMAC signature and expiration
-func ValidateSignedURL(filename string, expires int64, sig string) bool {
-	data := fmt.Sprintf("%s:%d", filename, expires)
+func signedURLMAC(filename string, expires int64, session string) string {
+	secret, _ := signedURLConfig()
+	h := hmac.New(sha256.New, secret)
+	fmt.Fprintf(h, "player:%s:%d:", filename, expires)
+	if session != "" {
+		// bound: the session is part of what is signed
+		h.Write([]byte("session:" + session))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GenerateSignedURL returns a player URL for filename that expires after duration;
+// a non-empty session binds it to that listener session
+func GenerateSignedURL(filename string, duration time.Duration, session string) (string, time.Time, error) {
+	if filename == "" {
+		return "", time.Time{}, fmt.Errorf("filename is required")
+	}
+	expiresAt := time.Now().Add(duration).Truncate(time.Second)
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("expires", fmt.Sprint(expires))
+	query.Set("sig", signedURLMAC(filename, expires, session))
+	if session != "" {
+		query.Set("s", "1")
+	}
+	return "/api/player/" + url.PathEscape(filename) + "?" + query.Encode(), expiresAt, nil
+}
 
-	h := hmac.New(sha256.New, signedURLSecret)
-	h.Write([]byte(data))
-	expected := hex.EncodeToString(h.Sum(nil))
+// ValidateSignedURL validates the HMAC signature and expiration; session is the
+// requester's session for bound URLs (s=1) and "" otherwise
+func ValidateSignedURL(filename string, expires int64, sig string, session string) bool {
+	expected := signedURLMAC(filename, expires, session)
 
 	// Check signature match and expiration
 	if !hmac.Equal([]byte(sig), []byte(expected)) {